@@ -0,0 +1,169 @@
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"gopkg.in/yaml.v2"
+)
+
+var defaultAction = flag.String("default-action", "errno", "action of the syscalls the profile doesn't allow: errno, kill, kill_process, trap or log")
+var actionsPath = flag.String("actions", "", "YAML or JSON file mapping syscalls to the action the profile takes for them, e.g. ptrace: trap, or the errno it fails with, e.g. keyctl: ENOSYS")
+
+// the actions by the names they're given on the command line and in the -actions file, which also take the
+// SCMP_ACT_ names
+var actionsByName = map[string]specs.LinuxSeccompAction{
+	"allow":        specs.ActAllow,
+	"errno":        specs.ActErrno,
+	"kill":         specs.ActKill,
+	"kill_thread":  specs.ActKillThread,
+	"kill_process": specs.ActKillProcess,
+	"trap":         specs.ActTrap,
+	"log":          specs.ActLog,
+	"trace":        specs.ActTrace,
+	"notify":       specs.ActNotify,
+}
+
+// the actions -default-action takes, the others would let the syscalls the profile doesn't allow through or need
+// a supervisor
+var defaultActions = map[specs.LinuxSeccompAction]bool{
+	specs.ActErrno:       true,
+	specs.ActKill:        true,
+	specs.ActKillProcess: true,
+	specs.ActTrap:        true,
+	specs.ActLog:         true,
+}
+
+// syscallAction is the action of a syscall in the -actions file, with the errno SCMP_ACT_ERRNO returns
+type syscallAction struct {
+	action   specs.LinuxSeccompAction
+	errnoRet *uint
+}
+
+// the actions of the syscalls in the -actions file
+var syscallActions map[string]syscallAction
+
+// errno of the names the -actions file takes, the asm-generic numbers most arches use. MIPS numbers the ones
+// above ERANGE differently, give them as numbers there.
+var errnosByName = map[string]uint{
+	"EPERM": 1, "ENOENT": 2, "ESRCH": 3, "EINTR": 4, "EIO": 5, "ENXIO": 6, "E2BIG": 7, "ENOEXEC": 8, "EBADF": 9,
+	"ECHILD": 10, "EAGAIN": 11, "ENOMEM": 12, "EACCES": 13, "EFAULT": 14, "EBUSY": 16, "EEXIST": 17, "EXDEV": 18,
+	"ENODEV": 19, "ENOTDIR": 20, "EISDIR": 21, "EINVAL": 22, "ENFILE": 23, "EMFILE": 24, "ENOTTY": 25,
+	"EFBIG": 27, "ENOSPC": 28, "ESPIPE": 29, "EROFS": 30, "EMLINK": 31, "EPIPE": 32, "ERANGE": 34,
+	"ENOSYS": 38, "ENODATA": 61, "EOPNOTSUPP": 95, "EAFNOSUPPORT": 97, "ECONNREFUSED": 111,
+}
+
+func parseAction(name string) (specs.LinuxSeccompAction, bool) {
+	if action, ok := actionsByName[strings.ToLower(name)]; ok {
+		return action, true
+	}
+	action := specs.LinuxSeccompAction(name)
+	return action, validActions[action]
+}
+
+// parseDefaultAction parses the -default-action, one of defaultActions
+func parseDefaultAction(name string) (specs.LinuxSeccompAction, bool) {
+	action, ok := parseAction(name)
+	return action, ok && defaultActions[action]
+}
+
+// profileDefaultAction returns the default action of the profile, the -default-action one or SCMP_ACT_LOG
+// for -mode audit profiles
+func profileDefaultAction() specs.LinuxSeccompAction {
+	if auditMode() {
+		return specs.ActLog
+	}
+	action, _ := parseDefaultAction(*defaultAction)
+	return action
+}
+
+// loadSyscallActions reads the -actions file, a map of syscall names to actions, or to the errno they fail with:
+// an errno name like ENOSYS, or its number
+func loadSyscallActions(path string) map[string]syscallAction {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		fatalf("Failed to read actions file: %v", err)
+	}
+	var names map[string]string
+	if err := yaml.UnmarshalStrict(data, &names); err != nil {
+		fatalf("Failed to parse actions file %v: %v", path, err)
+	}
+
+	actions := make(map[string]syscallAction, len(names))
+	for name, actionName := range names {
+		if errno, ok := parseErrno(actionName); ok {
+			actions[name] = syscallAction{specs.ActErrno, &errno}
+			continue
+		}
+		action, ok := parseAction(actionName)
+		if !ok {
+			fatalf("Invalid action %v for %v in %v", actionName, name, path)
+		}
+		actions[name] = syscallAction{action: action}
+	}
+	return actions
+}
+
+// parseErrno parses an errno name, or number
+func parseErrno(s string) (uint, bool) {
+	if errno, ok := errnosByName[strings.ToUpper(s)]; ok {
+		return errno, true
+	}
+	errno, err := strconv.ParseUint(s, 10, 32)
+	if err != nil || errno > maxErrno {
+		return 0, false
+	}
+	return uint(errno), true
+}
+
+// applySyscallActions gives the syscalls of the -actions file their action, in a rule per action and errno. Like
+// -add, they're in the profile even if not detected.
+func applySyscallActions(profile specs.LinuxSeccomp) specs.LinuxSeccomp {
+	if len(syscallActions) == 0 {
+		return profile
+	}
+
+	var rules []specs.LinuxSyscall
+	for _, rule := range profile.Syscalls {
+		if rule.Action == specs.ActAllow {
+			var names []string
+			for _, name := range rule.Names {
+				if _, ok := syscallActions[name]; !ok {
+					names = append(names, name)
+				}
+			}
+			// drop the rules left without syscalls, e.g. with -rule-style grouped
+			if len(names) == 0 {
+				continue
+			}
+			rule.Names = names
+		}
+		rules = append(rules, rule)
+	}
+
+	// a rule per action and errno
+	byAction := make(map[string][]string)
+	var keys []string
+	rule := make(map[string]specs.LinuxSyscall)
+	for name, a := range syscallActions {
+		key := ruleKey(specs.LinuxSyscall{Action: a.action, ErrnoRet: a.errnoRet})
+		if _, ok := byAction[key]; !ok {
+			keys = append(keys, key)
+			rule[key] = specs.LinuxSyscall{Action: a.action, ErrnoRet: a.errnoRet}
+		}
+		byAction[key] = append(byAction[key], name)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		r := rule[key]
+		r.Names = byAction[key]
+		sort.Strings(r.Names)
+		rules = append(rules, r)
+	}
+	profile.Syscalls = rules
+	return profile
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+func init() {
+	registerFormatter(formatFunc{"apparmor", "", writeAppArmorProfile})
+}
+
+var apparmorPath = flag.String("apparmor-path", "", "path the binary is installed at, which the apparmor profile attaches to, the absolute path of the analyzed binary by default")
+
+// functions starting other programs
+var execFunctions = []string{"os.StartProcess", "syscall.forkExec", "os/exec.(*Cmd).Start"}
+
+// whether the analyzed binary starts other programs, set by recordExecFunctions
+var runsPrograms bool
+
+// AppArmor names of the address families
+var apparmorFamilies = map[int64]string{
+	1: "unix", 2: "inet", 10: "inet6", 16: "netlink", 17: "packet", 29: "can", 31: "bluetooth", 38: "alg",
+	40: "vsock", 44: "xdp",
+}
+
+// capabilities the syscalls need, at least for some of their uses
+var syscallCapabilities = map[string][]string{
+	"chown": {"chown"}, "fchown": {"chown"}, "lchown": {"chown"}, "fchownat": {"chown"},
+	"setuid": {"setuid"}, "setreuid": {"setuid"}, "setresuid": {"setuid"},
+	"setgid": {"setgid"}, "setregid": {"setgid"}, "setresgid": {"setgid"}, "setgroups": {"setgid"},
+	"mount": {"sys_admin"}, "umount2": {"sys_admin"}, "pivot_root": {"sys_admin"}, "unshare": {"sys_admin"},
+	"setns": {"sys_admin"}, "chroot": {"sys_chroot"}, "mknod": {"mknod"}, "mknodat": {"mknod"},
+	"ptrace": {"sys_ptrace"}, "reboot": {"sys_boot"}, "settimeofday": {"sys_time"}, "clock_settime": {"sys_time"},
+	"adjtimex": {"sys_time"}, "init_module": {"sys_module"}, "finit_module": {"sys_module"},
+	"delete_module": {"sys_module"}, "bpf": {"bpf"},
+}
+
+// file syscalls, by what the program does with files when it makes them
+var fileSyscallUses = []struct {
+	use      string
+	syscalls []string
+}{
+	{"opens files", []string{"open", "openat", "openat2", "creat"}},
+	{"creates, renames or removes files and directories", []string{
+		"mkdir", "mkdirat", "rmdir", "unlink", "unlinkat", "rename", "renameat", "renameat2", "link", "linkat",
+		"symlink", "symlinkat", "mknod", "mknodat",
+	}},
+	{"changes file permissions or owners", []string{"chmod", "fchmod", "fchmodat", "fchmodat2", "chown", "fchown", "lchown", "fchownat"}},
+}
+
+// recordExecFunctions records whether the binary starts other programs, in the given functions (all if nil)
+func recordExecFunctions(graph *callGraph, functions map[string]bool) {
+	runsPrograms = false
+	for _, function := range execFunctions {
+		if _, ok := graph.syscalls[function]; ok && (functions == nil || functions[function]) {
+			runsPrograms = true
+		}
+	}
+}
+
+// writeAppArmorProfile writes an AppArmor profile skeleton to pair with the seccomp profile, in complain mode,
+// with the network families, capabilities and program execution the analysis found, and what to fill in
+func writeAppArmorProfile(w io.Writer, profile specs.LinuxSeccomp, profilePath string) error {
+	allowed := make(map[string]bool)
+	for _, rule := range profile.Syscalls {
+		if runningActions[rule.Action] {
+			for _, name := range rule.Names {
+				allowed[name] = true
+			}
+		}
+	}
+
+	binary := *apparmorPath
+	if binary == "" {
+		binary, _ = filepath.Abs(analyzedBinary)
+	}
+	name := strings.TrimSuffix(filepath.Base(profilePath), filepath.Ext(profilePath))
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# AppArmor profile skeleton generated by go2seccomp for %v, to pair with its seccomp profile.\n", analyzedBinary)
+	fmt.Fprintln(&buf, "# It starts in complain mode: fill in the paths, check the logs, then remove flags=(complain).")
+	fmt.Fprintln(&buf, "abi <abi/3.0>,")
+	fmt.Fprintln(&buf, "include <tunables/global>")
+	fmt.Fprintln(&buf)
+	fmt.Fprintf(&buf, "profile %v %v flags=(complain) {\n", name, binary)
+	fmt.Fprintln(&buf, "  include <abstractions/base>")
+	fmt.Fprintln(&buf)
+	fmt.Fprintf(&buf, "  %v mr,\n", binary)
+
+	if allowed["socket"] {
+		fmt.Fprintln(&buf)
+		if observedFamilies == nil {
+			fmt.Fprintln(&buf, "  # the address families couldn't all be told")
+			fmt.Fprintln(&buf, "  network,")
+		}
+		for _, family := range observedFamilies {
+			if apparmorName, ok := apparmorFamilies[family]; ok {
+				fmt.Fprintf(&buf, "  network %v,\n", apparmorName)
+			} else {
+				fmt.Fprintf(&buf, "  # %v has no AppArmor name\n", familyName(family))
+			}
+		}
+	}
+
+	capabilities := make(map[string]bool)
+	for syscall := range allowed {
+		for _, capability := range syscallCapabilities[syscall] {
+			capabilities[capability] = true
+		}
+	}
+	if len(capabilities) > 0 {
+		var names []string
+		for capability := range capabilities {
+			names = append(names, capability)
+		}
+		sort.Strings(names)
+		fmt.Fprintln(&buf)
+		fmt.Fprintln(&buf, "  # needed by some uses of the allowed syscalls, drop the ones the program doesn't need")
+		for _, capability := range names {
+			fmt.Fprintf(&buf, "  capability %v,\n", capability)
+		}
+	}
+	if allowed["ptrace"] {
+		fmt.Fprintln(&buf, "  ptrace,")
+	}
+
+	var fileUses []string
+	for _, files := range fileSyscallUses {
+		var used []string
+		for _, syscall := range files.syscalls {
+			if allowed[syscall] {
+				used = append(used, syscall)
+			}
+		}
+		if len(used) > 0 {
+			fileUses = append(fileUses, fmt.Sprintf("%v (%v)", files.use, strings.Join(used, ", ")))
+		}
+	}
+	if len(fileUses) > 0 {
+		fmt.Fprintln(&buf)
+		for _, use := range fileUses {
+			fmt.Fprintf(&buf, "  # the program %v\n", use)
+		}
+		fmt.Fprintln(&buf, "  # add the paths it needs, e.g.")
+		fmt.Fprintln(&buf, "  # /etc/example/** r,")
+		fmt.Fprintln(&buf, "  # /var/lib/example/** rw,")
+	}
+
+	if runsPrograms {
+		fmt.Fprintln(&buf)
+		fmt.Fprintln(&buf, "  # the program runs other programs, add them, e.g.")
+		fmt.Fprintln(&buf, "  # /usr/bin/example ix,")
+	}
+	fmt.Fprintln(&buf, "}")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+// argSite is a call making a syscall with an argument that tells how it's used, e.g. the protection of
+// mmap, and its value when it's a constant
+type argSite struct {
+	Syscall  string `json:"syscall"`
+	Function string `json:"function"`
+	Address  string `json:"address"`
+	Location string `json:"location"`
+	// index of the argument in the syscall
+	Arg   int   `json:"arg"`
+	Value int64 `json:"value"`
+	Known bool  `json:"known"`
+}
+
+// the syscall arguments worth recording, by syscall name
+var recordedArgs = map[string]int{
+	"mmap":          2, // prot
+	"mprotect":      2, // prot
+	"pkey_mprotect": 2, // prot
+	"socket":        0, // domain
+	"prctl":         0, // option
+	"clone":         0, // flags
+	"unshare":       0, // flags
+}
+
+// functions of the syscall and x/sys/unix packages wrapping the syscalls with recorded arguments, with
+// the index of the argument in the call on amd64. Argument registers skip the receiver of methods.
+var argFunctions = map[string]struct {
+	syscall string
+	arg     int
+}{
+	"syscall.(*mmapper).Mmap":               {"mmap", 4},
+	"golang.org/x/sys/unix.(*mmapper).Mmap": {"mmap", 4},
+	"syscall.mmap":                          {"mmap", 2},
+	"golang.org/x/sys/unix.mmap":            {"mmap", 2},
+	"syscall.Mprotect":                      {"mprotect", 3},
+	"golang.org/x/sys/unix.Mprotect":        {"mprotect", 3},
+	"golang.org/x/sys/unix.PkeyMprotect":    {"pkey_mprotect", 3},
+	"syscall.Socket":                        {"socket", 0},
+	"syscall.socket":                        {"socket", 0},
+	"golang.org/x/sys/unix.Socket":          {"socket", 0},
+	"golang.org/x/sys/unix.socket":          {"socket", 0},
+	"golang.org/x/sys/unix.Prctl":           {"prctl", 0},
+	"syscall.Unshare":                       {"unshare", 0},
+	"golang.org/x/sys/unix.Unshare":         {"unshare", 0},
+}
+
+// registers of the first arguments in the Go internal ABI on amd64
+var amd64ArgRegisters = []string{"AX", "BX", "CX", "DI", "SI", "R8", "R9", "R10", "R11"}
+
+// scanArgSite records the argument of the call, if it's one of the syscalls with recorded arguments.
+// Only amd64 passes arguments in registers, where they can be found before the call.
+func scanArgSite(graph *callGraph, arch specs.Arch, function, instruction string, previousInstructions []string, curPos int) {
+	if arch != specs.ArchX86_64 {
+		return
+	}
+	target := callTarget(arch, instruction)
+	if target == "" {
+		return
+	}
+
+	var syscall string
+	var register int
+	if f, ok := argFunctions[canonicalSyscallFunction(target)]; ok {
+		syscall, register = f.syscall, f.arg
+	} else if isGenericSyscallFunction(target) {
+		trap, ok := findRegisterValue(previousInstructions, curPos, "AX")
+		if !ok {
+			return
+		}
+		syscall = syscallIDtoName[arch][trap]
+		arg, ok := recordedArgs[syscall]
+		if !ok {
+			return
+		}
+		// the arguments follow the trap
+		register = arg + 1
+	} else {
+		return
+	}
+	if register >= len(amd64ArgRegisters) {
+		return
+	}
+	// wrappers pass their caller's argument on, their callers are recorded instead
+	if f, ok := argFunctions[canonicalSyscallFunction(function)]; ok && f.syscall == syscall {
+		return
+	}
+
+	site := argSite{Syscall: syscall, Function: function, Arg: recordedArgs[syscall]}
+	site.Value, site.Known = findRegisterValue(previousInstructions, curPos, amd64ArgRegisters[register])
+	// objdump pads short locations with an extra tab
+	fields := strings.Fields(instruction)
+	if len(fields) > 1 {
+		site.Location = fields[0]
+		site.Address = fields[1]
+	}
+	graph.argSites = append(graph.argSites, site)
+}
+
+// findRegisterValue goes back from the call until the instruction setting the register, and returns the
+// constant it sets it to, if it's one
+func findRegisterValue(previousInstructions []string, curPos int, register string) (int64, bool) {
+	for i := 1; i < previousInstructionsBufferSize; i++ {
+		instruction := strings.TrimSpace(previousInstructions[(curPos-i+previousInstructionsBufferSize)%previousInstructionsBufferSize])
+		fields := strings.Split(instruction, "\t")
+		op := strings.TrimSpace(fields[len(fields)-1])
+
+		// the registers don't survive calls, and function boundaries end the search
+		if strings.HasPrefix(op, "CALL ") || strings.HasPrefix(op, "TEXT ") || strings.HasPrefix(instruction, "TEXT ") {
+			return 0, false
+		}
+		if !strings.HasSuffix(op, ", "+register) {
+			continue
+		}
+		if op == "XORL "+register+", "+register || op == "XORQ "+register+", "+register {
+			return 0, true
+		}
+		if (strings.HasPrefix(op, "MOVL $") || strings.HasPrefix(op, "MOVQ $")) && !strings.Contains(op, "(") {
+			value, err := strconv.ParseInt(strings.TrimSuffix(op[len("MOVL $"):], ", "+register), 0, 64)
+			return value, err == nil
+		}
+		return 0, false
+	}
+	return 0, false
+}
+
+// argSitesOf returns the recorded sites of the syscall in the given functions, or in all of them if nil
+func (g *callGraph) argSitesOf(syscall string, functions map[string]bool) []argSite {
+	var sites []argSite
+	for _, site := range g.argSites {
+		if site.Syscall == syscall && (functions == nil || functions[site.Function]) {
+			sites = append(sites, site)
+		}
+	}
+	return sites
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"time"
+)
+
+// version of go2seccomp, set at build time with -ldflags "-X main.version=..."
+var version = ""
+
+var attest = flag.Bool("attest", false, "write an in-toto statement next to each profile, recording the binary, parameters and tool version")
+
+// binary the profiles are currently being generated for, the subject of their statements
+var analyzedBinary string
+
+const (
+	inTotoStatementType  = "https://in-toto.io/Statement/v1"
+	profilePredicateType = "https://github.com/xfernando/go2seccomp/seccomp-profile/v1"
+)
+
+type inTotoSubject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+type inTotoStatement struct {
+	Type          string           `json:"_type"`
+	Subject       []inTotoSubject  `json:"subject"`
+	PredicateType string           `json:"predicateType"`
+	Predicate     profilePredicate `json:"predicate"`
+}
+
+// profilePredicate records how a profile was generated for the binary in the statement subject
+type profilePredicate struct {
+	Profile     inTotoSubject     `json:"profile"`
+	Format      string            `json:"format"`
+	Parameters  map[string]string `json:"parameters"`
+	Tool        map[string]string `json:"tool"`
+	Environment map[string]string `json:"environment"`
+	GeneratedOn string            `json:"generatedOn"`
+}
+
+// toolVersion returns the version of go2seccomp, from -ldflags or the module it was installed from
+func toolVersion() string {
+	if version != "" {
+		return version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+	return "devel"
+}
+
+// attestationPath returns where the statement of a profile is written
+func attestationPath(profilePath string) string {
+	return profilePath + ".intoto.json"
+}
+
+// writeAttestation writes the in-toto statement for the profile generated for the analyzed binary
+func writeAttestation(profilePath, format string) {
+	// the flags given on the command line are the generation parameters
+	parameters := make(map[string]string)
+	flag.Visit(func(f *flag.Flag) {
+		parameters[f.Name] = f.Value.String()
+	})
+
+	environment := map[string]string{"os": runtime.GOOS, "arch": runtime.GOARCH}
+	if hostname, err := os.Hostname(); err == nil {
+		environment["hostname"] = hostname
+	}
+
+	statement := inTotoStatement{
+		Type: inTotoStatementType,
+		Subject: []inTotoSubject{{
+			Name:   filepath.Base(analyzedBinary),
+			Digest: map[string]string{"sha256": analyzedBinarySHA256()},
+		}},
+		PredicateType: profilePredicateType,
+		Predicate: profilePredicate{
+			Profile: inTotoSubject{
+				Name:   filepath.Base(profilePath),
+				Digest: map[string]string{"sha256": fileSHA256(profilePath)},
+			},
+			Format:     format,
+			Parameters: parameters,
+			Tool: map[string]string{
+				"name":    "go2seccomp",
+				"version": toolVersion(),
+				"go":      runtime.Version(),
+			},
+			Environment: environment,
+			GeneratedOn: time.Now().UTC().Format(time.RFC3339),
+		},
+	}
+
+	data, err := json.MarshalIndent(statement, "", "    ")
+	if err != nil {
+		fatalf("Failed to encode attestation: %v", err)
+	}
+	if err := writeFileAtomic(attestationPath(profilePath), append(data, '\n'), 0644); err != nil {
+		fatalf("Failed to write attestation: %v", err)
+	}
+	fmt.Printf("Saved attestation at %v\n", attestationPath(profilePath))
+}
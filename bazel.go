@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// bazel run starts the tool in its runfiles tree, and tells where it was started from and the workspace
+// in these variables
+const (
+	bazelWorkingDirectoryEnv   = "BUILD_WORKING_DIRECTORY"
+	bazelWorkspaceDirectoryEnv = "BUILD_WORKSPACE_DIRECTORY"
+)
+
+// rules_go compiles with the sandbox trimmed from the paths, the generated sources are left under
+// bazel-out/<configuration>/bin
+var bazelOutRegexp = regexp.MustCompile(`^bazel-out/[^/]+/(bin|genfiles)/`)
+
+// bazelWorkingPath resolves a relative path given on the command line against the directory bazel run was
+// started from, since the tool itself runs in its runfiles tree
+func bazelWorkingPath(path string) string {
+	dir := os.Getenv(bazelWorkingDirectoryEnv)
+	if dir == "" || path == "" || path == stdoutPath || filepath.IsAbs(path) || isBazelLabel(path) || isRemoteBinary(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+// isBazelLabel tells if the binary is given as a Bazel target, //cmd/app:app or @repo//cmd/app
+func isBazelLabel(path string) bool {
+	return strings.HasPrefix(path, "//") || strings.HasPrefix(path, "@")
+}
+
+// bazelLabelName returns the name of the target, app for //cmd/app:app and //cmd/app
+func bazelLabelName(label string) string {
+	if i := strings.LastIndex(label, ":"); i != -1 {
+		return label[i+1:]
+	}
+	return filepath.Base(label)
+}
+
+// bazelSourcePath returns the path of a source file of a rules_go binary relative to the workspace
+func bazelSourcePath(file string) string {
+	return bazelOutRegexp.ReplaceAllString(file, "")
+}
+
+// resolveBazelBinary returns the Go binary to analyze for a Bazel target or output: labels are built and their
+// Go binary found among the output files cquery lists, rules_go's name_/name output is used for name, and the
+// runfiles trees of wrappers are searched for the Go binary they run. Anything else is returned as is.
+func resolveBazelBinary(path string) string {
+	if isBazelLabel(path) {
+		return bazelTargetBinary(path)
+	}
+
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		// rules_go writes go_binary(name = "app") to app_/app
+		name := filepath.Base(path)
+		if candidate := filepath.Join(path+"_", name); isGoELF(candidate) {
+			fmt.Printf("Using the rules_go output %v\n", candidate)
+			return candidate
+		}
+		return path
+	}
+	if isGoELF(path) {
+		return path
+	}
+
+	runfiles := path
+	if !strings.HasSuffix(path, ".runfiles") {
+		runfiles = path + ".runfiles"
+	}
+	if info, err := os.Stat(runfiles); err != nil || !info.IsDir() {
+		return path
+	}
+	binary := findRunfilesBinary(runfiles, strings.TrimSuffix(filepath.Base(path), ".runfiles"))
+	fmt.Printf("Using the Go binary %v from the runfiles of %v\n", binary, path)
+	return binary
+}
+
+// findRunfilesBinary returns the Go binary of a runfiles tree, preferring the one named like the wrapper
+// when there are several
+func findRunfilesBinary(runfiles, name string) string {
+	var binaries []string
+	filepath.Walk(runfiles, func(path string, info os.FileInfo, err error) error {
+		// runfiles are symlinks to the outputs, isGoELF opens the files they point to
+		if err == nil && !info.IsDir() && isGoELF(path) {
+			binaries = append(binaries, path)
+		}
+		return nil
+	})
+	sort.Strings(binaries)
+
+	switch len(binaries) {
+	case 0:
+		fatalf("No Go binary in the runfiles tree %v", runfiles)
+	case 1:
+		return binaries[0]
+	}
+	var named []string
+	for _, binary := range binaries {
+		if filepath.Base(binary) == name {
+			named = append(named, binary)
+		}
+	}
+	if len(named) == 1 {
+		return named[0]
+	}
+	fatalf("Several Go binaries in the runfiles tree %v, give the one to analyze: %v", runfiles, strings.Join(binaries, ", "))
+	return ""
+}
+
+// bazelTargetBinary builds the target with bazel and returns its Go binary, from the output files cquery lists
+func bazelTargetBinary(label string) string {
+	workspace := os.Getenv(bazelWorkspaceDirectoryEnv)
+
+	fmt.Printf("Building %v with bazel\n", label)
+	build := exec.Command("bazel", "build", label)
+	build.Dir = workspace
+	build.Stdout, build.Stderr = os.Stderr, os.Stderr
+	if err := build.Run(); err != nil {
+		fatalf("bazel build %v failed: %v", label, err)
+	}
+
+	var stderr bytes.Buffer
+	cquery := exec.Command("bazel", "cquery", "--output=files", label)
+	cquery.Dir = workspace
+	cquery.Stderr = &stderr
+	out, err := cquery.Output()
+	if err != nil {
+		fatalf("bazel cquery %v failed: %v\n%s", label, err, stderr.Bytes())
+	}
+
+	var binaries []string
+	for _, file := range strings.Fields(string(out)) {
+		if !filepath.IsAbs(file) && workspace != "" {
+			file = filepath.Join(workspace, file)
+		}
+		if isGoELF(file) {
+			binaries = append(binaries, file)
+		}
+	}
+	if len(binaries) != 1 {
+		fatalf("%v has %v Go binaries in its outputs, expected one: %v", label, len(binaries), binaries)
+	}
+	return binaries[0]
+}
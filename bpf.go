@@ -0,0 +1,438 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+var bpfReport = flag.Bool("bpf-report", false, "print an estimate of the size of the BPF filter of the profile, and the instructions it runs per syscall")
+
+// bpfInstruction is a classic BPF instruction, as in struct sock_filter
+type bpfInstruction struct {
+	Code uint16
+	Jt   uint8
+	Jf   uint8
+	K    uint32
+}
+
+// the BPF opcodes seccomp filters use
+const (
+	bpfLdAbsW = 0x20 // BPF_LD | BPF_W | BPF_ABS
+	bpfAndK   = 0x54 // BPF_ALU | BPF_AND | BPF_K
+	bpfJa     = 0x05 // BPF_JMP | BPF_JA
+	bpfJeqK   = 0x15 // BPF_JMP | BPF_JEQ | BPF_K
+	bpfJgtK   = 0x25 // BPF_JMP | BPF_JGT | BPF_K
+	bpfJgeK   = 0x35 // BPF_JMP | BPF_JGE | BPF_K
+	bpfRetK   = 0x06 // BPF_RET | BPF_K
+)
+
+// offsets in struct seccomp_data
+const (
+	seccompDataNr   = 0
+	seccompDataArch = 4
+	seccompDataArgs = 16
+)
+
+// the x32 ABI sets this bit in the syscall numbers of x86_64 processes
+const x32SyscallBit = 0x40000000
+
+// AUDIT_ARCH_* value of each arch, and whether it's big endian
+var auditArches = map[specs.Arch]struct {
+	value     uint32
+	bigEndian bool
+}{
+	specs.ArchX86_64: {0xc000003e, false},
+	specs.ArchX86:    {0x40000003, false},
+	// x32 processes report x86_64, their syscall numbers have x32SyscallBit set
+	specs.ArchX32:      {0xc000003e, false},
+	specs.ArchARM:      {0x40000028, false},
+	specs.ArchAARCH64:  {0xc00000b7, false},
+	specs.ArchPPC64:    {0x80000015, true},
+	specs.ArchPPC64LE:  {0xc0000015, false},
+	specs.ArchS390X:    {0x80000016, true},
+	specs.ArchMIPS:     {0x00000008, true},
+	specs.ArchMIPSEL:   {0x40000008, false},
+	specs.ArchMIPS64:   {0x80000008, true},
+	specs.ArchMIPSEL64: {0xc0000008, false},
+}
+
+// SECCOMP_RET_* values
+var actionReturns = map[specs.LinuxSeccompAction]uint32{
+	specs.ActKill:        0x00000000,
+	specs.ActKillThread:  0x00000000,
+	specs.ActKillProcess: 0x80000000,
+	specs.ActTrap:        0x00030000,
+	specs.ActErrno:       0x00050000,
+	specs.ActNotify:      0x7fc00000,
+	specs.ActTrace:       0x7ff00000,
+	specs.ActLog:         0x7ffc0000,
+	specs.ActAllow:       0x7fff0000,
+}
+
+// precedence of the actions when several rules match, the same the kernel uses between filters
+var actionPrecedence = map[specs.LinuxSeccompAction]int{
+	specs.ActKillProcess: 0,
+	specs.ActKillThread:  1,
+	specs.ActKill:        1,
+	specs.ActTrap:        2,
+	specs.ActErrno:       3,
+	specs.ActNotify:      4,
+	specs.ActTrace:       5,
+	specs.ActLog:         6,
+	specs.ActAllow:       7,
+}
+
+// the errno SCMP_ACT_ERRNO returns when the profile doesn't set one
+const defaultErrno = 1 // EPERM
+
+func actionReturn(action specs.LinuxSeccompAction, errnoRet *uint) uint32 {
+	ret := actionReturns[action]
+	if action == specs.ActErrno {
+		errno := uint(defaultErrno)
+		if errnoRet != nil {
+			errno = *errnoRet
+		}
+		ret |= uint32(errno) & 0xffff
+	}
+	return ret
+}
+
+// returnAction is the reverse of actionReturn, the action of a filter result and its errno if any
+func returnAction(ret uint32) (specs.LinuxSeccompAction, *uint) {
+	switch ret & 0xffff0000 {
+	case actionReturns[specs.ActErrno]:
+		errno := uint(ret & 0xffff)
+		return specs.ActErrno, &errno
+	case actionReturns[specs.ActKillThread]:
+		return specs.ActKillThread, nil
+	}
+	for action, value := range actionReturns {
+		if value == ret&0xffff0000 && action != specs.ActKill {
+			return action, nil
+		}
+	}
+	return specs.ActKillProcess, nil
+}
+
+// bpfProgram is a filter being compiled, its jumps target labels that are resolved once it's complete
+type bpfProgram struct {
+	insns  []bpfInstruction
+	jt, jf []int // labels of the jumps, 0 for the next instruction
+	labels []int // position of each label, the first one is unused
+}
+
+func (p *bpfProgram) label() int {
+	p.labels = append(p.labels, -1)
+	return len(p.labels) - 1
+}
+
+func (p *bpfProgram) bind(label int) {
+	p.labels[label] = len(p.insns)
+}
+
+func (p *bpfProgram) emit(code uint16, k uint32, jt, jf int) {
+	p.insns = append(p.insns, bpfInstruction{Code: code, K: k})
+	p.jt = append(p.jt, jt)
+	p.jf = append(p.jf, jf)
+}
+
+// resolve returns the instructions with the jumps to labels turned into offsets
+func (p *bpfProgram) resolve() ([]bpfInstruction, error) {
+	offset := func(i, label int) int {
+		if label == 0 {
+			return 0
+		}
+		return p.labels[label] - i - 1
+	}
+	for i := range p.insns {
+		switch p.insns[i].Code {
+		case bpfJa:
+			p.insns[i].K = uint32(offset(i, p.jt[i]))
+		case bpfJeqK, bpfJgtK, bpfJgeK:
+			jt, jf := offset(i, p.jt[i]), offset(i, p.jf[i])
+			if jt > 255 || jf > 255 {
+				return nil, fmt.Errorf("conditional jump at %v too long", i)
+			}
+			p.insns[i].Jt, p.insns[i].Jf = uint8(jt), uint8(jf)
+		}
+	}
+	return p.insns, nil
+}
+
+// compileBPF compiles the profile to a seccomp filter. It checks the arch, then each syscall in turn, the
+// rules with the most restrictive actions first, like the kernel picks the most restrictive of several
+// filters. The conditions of a rule must all hold.
+func compileBPF(profile specs.LinuxSeccomp) ([]bpfInstruction, error) {
+	p := &bpfProgram{labels: []int{0}}
+
+	// libseccomp kills the thread on architectures the filter wasn't built for
+	badArch := actionReturn(specs.ActKillThread, nil)
+	p.emit(bpfLdAbsW, seccompDataArch, 0, 0)
+	// the x32 block comes last, the x86_64 one jumps forward to it
+	archs := append([]specs.Arch(nil), profile.Architectures...)
+	sort.SliceStable(archs, func(i, j int) bool { return archs[i] != specs.ArchX32 && archs[j] == specs.ArchX32 })
+	blocks := make([]int, len(archs))
+	for i, arch := range archs {
+		audit, ok := auditArches[arch]
+		if !ok {
+			return nil, fmt.Errorf("no BPF support for %v", arch)
+		}
+		blocks[i] = p.label()
+		// the x86_64 block jumps to the x32 one for the x32 syscall numbers
+		if arch == specs.ArchX32 && archIndex(archs, specs.ArchX86_64) != -1 {
+			continue
+		}
+		next := p.label()
+		p.emit(bpfJeqK, audit.value, 0, next)
+		p.emit(bpfJa, 0, blocks[i], 0)
+		p.bind(next)
+	}
+	p.emit(bpfRetK, badArch, 0, 0)
+
+	rules := make([]int, len(profile.Syscalls))
+	for i := range rules {
+		rules[i] = i
+	}
+	sort.SliceStable(rules, func(i, j int) bool {
+		return actionPrecedence[profile.Syscalls[rules[i]].Action] < actionPrecedence[profile.Syscalls[rules[j]].Action]
+	})
+
+	for i, arch := range archs {
+		table, ok := syscallIDtoName[arch]
+		if !ok {
+			return nil, fmt.Errorf("no syscall table for %v", arch)
+		}
+		ids := make(map[string]int64, len(table))
+		for id, name := range table {
+			ids[name] = id
+		}
+
+		p.bind(blocks[i])
+		p.emit(bpfLdAbsW, seccompDataNr, 0, 0)
+		if arch == specs.ArchX86_64 {
+			next := p.label()
+			p.emit(bpfJgeK, x32SyscallBit, 0, next)
+			if j := archIndex(archs, specs.ArchX32); j != -1 {
+				p.emit(bpfJa, 0, blocks[j], 0)
+			} else {
+				p.emit(bpfRetK, badArch, 0, 0)
+			}
+			p.bind(next)
+		}
+		if arch == specs.ArchX32 {
+			next := p.label()
+			p.emit(bpfJgeK, x32SyscallBit, next, 0)
+			p.emit(bpfRetK, badArch, 0, 0)
+			p.bind(next)
+		}
+
+		for _, r := range rules {
+			rule := profile.Syscalls[r]
+			ret := actionReturn(rule.Action, rule.ErrnoRet)
+			for _, name := range rule.Names {
+				id, ok := ids[name]
+				if !ok {
+					continue
+				}
+				next := p.label()
+				if len(rule.Args) == 0 {
+					p.emit(bpfJeqK, uint32(id), 0, next)
+					p.emit(bpfRetK, ret, 0, 0)
+					p.bind(next)
+					continue
+				}
+
+				nomatch := p.label()
+				p.emit(bpfJeqK, uint32(id), 0, next)
+				for _, arg := range rule.Args {
+					if err := emitArgCheck(p, arg, auditArches[arch].bigEndian, nomatch); err != nil {
+						return nil, fmt.Errorf("%v: %v", name, err)
+					}
+				}
+				p.emit(bpfRetK, ret, 0, 0)
+				// the argument checks overwrote the syscall number
+				p.bind(nomatch)
+				p.emit(bpfLdAbsW, seccompDataNr, 0, 0)
+				p.bind(next)
+			}
+		}
+		p.emit(bpfRetK, actionReturn(profile.DefaultAction, profile.DefaultErrnoRet), 0, 0)
+	}
+	return p.resolve()
+}
+
+func archIndex(archs []specs.Arch, arch specs.Arch) int {
+	for i, a := range archs {
+		if a == arch {
+			return i
+		}
+	}
+	return -1
+}
+
+// emitArgCheck emits the comparison of a 64 bit argument, as two 32 bit halves, jumping to fail
+// if it doesn't hold and falling through otherwise
+func emitArgCheck(p *bpfProgram, arg specs.LinuxSeccompArg, bigEndian bool, fail int) error {
+	if arg.Index > 5 {
+		return fmt.Errorf("invalid argument index %v", arg.Index)
+	}
+	hiOff, loOff := uint32(seccompDataArgs+8*arg.Index+4), uint32(seccompDataArgs+8*arg.Index)
+	if bigEndian {
+		hiOff, loOff = loOff, hiOff
+	}
+	hi, lo := uint32(arg.Value>>32), uint32(arg.Value)
+	pass := p.label()
+
+	switch arg.Op {
+	case specs.OpEqualTo:
+		p.emit(bpfLdAbsW, hiOff, 0, 0)
+		p.emit(bpfJeqK, hi, 0, fail)
+		p.emit(bpfLdAbsW, loOff, 0, 0)
+		p.emit(bpfJeqK, lo, pass, fail)
+	case specs.OpNotEqual:
+		p.emit(bpfLdAbsW, hiOff, 0, 0)
+		p.emit(bpfJeqK, hi, 0, pass)
+		p.emit(bpfLdAbsW, loOff, 0, 0)
+		p.emit(bpfJeqK, lo, fail, pass)
+	case specs.OpGreaterThan, specs.OpGreaterEqual:
+		p.emit(bpfLdAbsW, hiOff, 0, 0)
+		p.emit(bpfJgtK, hi, pass, 0)
+		p.emit(bpfJeqK, hi, 0, fail)
+		p.emit(bpfLdAbsW, loOff, 0, 0)
+		if arg.Op == specs.OpGreaterThan {
+			p.emit(bpfJgtK, lo, pass, fail)
+		} else {
+			p.emit(bpfJgeK, lo, pass, fail)
+		}
+	case specs.OpLessThan, specs.OpLessEqual:
+		p.emit(bpfLdAbsW, hiOff, 0, 0)
+		p.emit(bpfJgtK, hi, fail, 0)
+		p.emit(bpfJeqK, hi, 0, pass)
+		p.emit(bpfLdAbsW, loOff, 0, 0)
+		if arg.Op == specs.OpLessThan {
+			p.emit(bpfJgeK, lo, fail, pass)
+		} else {
+			p.emit(bpfJgtK, lo, fail, pass)
+		}
+	case specs.OpMaskedEqual:
+		p.emit(bpfLdAbsW, hiOff, 0, 0)
+		p.emit(bpfAndK, hi, 0, 0)
+		p.emit(bpfJeqK, uint32(arg.ValueTwo>>32), 0, fail)
+		p.emit(bpfLdAbsW, loOff, 0, 0)
+		p.emit(bpfAndK, lo, 0, 0)
+		p.emit(bpfJeqK, uint32(arg.ValueTwo), pass, fail)
+	default:
+		return fmt.Errorf("invalid operator %v", arg.Op)
+	}
+	p.bind(pass)
+	return nil
+}
+
+// runBPF runs a filter on the 32 bit words of a struct seccomp_data, returning its result and the number
+// of instructions it took
+func runBPF(prog []bpfInstruction, data [16]uint32) (uint32, int, error) {
+	var a uint32
+	for pc, steps := 0, 1; pc < len(prog); pc, steps = pc+1, steps+1 {
+		insn := prog[pc]
+		switch insn.Code {
+		case bpfLdAbsW:
+			if insn.K%4 != 0 || insn.K/4 >= uint32(len(data)) {
+				return 0, steps, fmt.Errorf("invalid load at %v", pc)
+			}
+			a = data[insn.K/4]
+		case bpfAndK:
+			a &= insn.K
+		case bpfJa:
+			pc += int(insn.K)
+		case bpfJeqK, bpfJgtK, bpfJgeK:
+			var ok bool
+			switch insn.Code {
+			case bpfJeqK:
+				ok = a == insn.K
+			case bpfJgtK:
+				ok = a > insn.K
+			case bpfJgeK:
+				ok = a >= insn.K
+			}
+			if ok {
+				pc += int(insn.Jt)
+			} else {
+				pc += int(insn.Jf)
+			}
+		case bpfRetK:
+			return insn.K, steps, nil
+		default:
+			return 0, steps, fmt.Errorf("invalid instruction %#x at %v", insn.Code, pc)
+		}
+	}
+	return 0, len(prog), fmt.Errorf("filter ends without returning")
+}
+
+// bpfSteps returns the number of instructions the filter runs for the syscall on the arch, with all its
+// arguments zero
+func bpfSteps(prog []bpfInstruction, arch specs.Arch, id int64) (int, error) {
+	var data [16]uint32
+	data[seccompDataNr/4] = uint32(id)
+	data[seccompDataArch/4] = auditArches[arch].value
+	_, steps, err := runBPF(prog, data)
+	return steps, err
+}
+
+// reportBPF compiles the profile and prints the length of the filter and the instructions it runs per
+// syscall, warning when it gets close to the kernel limit. They're estimates: the filter is go2seccomp's,
+// not the one libseccomp builds for runtimes.
+func reportBPF(profile specs.LinuxSeccomp) {
+	prog, err := compileBPF(profile)
+	if err != nil {
+		fmt.Printf("Failed to compile the profile to BPF: %v\n", err)
+		return
+	}
+	fmt.Printf("Estimated BPF filter: %v instructions, %v bytes (%v%% of the kernel limit)\n",
+		len(prog), len(prog)*8, len(prog)*100/bpfMaxInstructions)
+
+	for _, arch := range profile.Architectures {
+		ids := make(map[string]int64)
+		for id, name := range syscallIDtoName[arch] {
+			ids[name] = id
+		}
+		min, max, total, count := 0, 0, 0, 0
+		for _, rule := range profile.Syscalls {
+			for _, name := range rule.Names {
+				id, ok := ids[name]
+				if !ok {
+					continue
+				}
+				steps, err := bpfSteps(prog, arch, id)
+				if err != nil {
+					fmt.Printf("Failed to run the BPF filter: %v\n", err)
+					return
+				}
+				if count == 0 || steps < min {
+					min = steps
+				}
+				if steps > max {
+					max = steps
+				}
+				total += steps
+				count++
+			}
+		}
+		// a syscall number no arch uses falls through to the default action
+		unlisted, err := bpfSteps(prog, arch, 0xffff)
+		if err != nil {
+			fmt.Printf("Failed to run the BPF filter: %v\n", err)
+			return
+		}
+		if count > 0 {
+			fmt.Printf("    %v: %v-%v instructions per listed syscall (%v on average), %v for the others\n",
+				arch, min, max, total/count, unlisted)
+		}
+	}
+
+	if len(prog) > bpfMaxInstructions*3/4 {
+		warnf("the filter is estimated to be close to the kernel limit of %v instructions, split the profile or drop conditions", bpfMaxInstructions)
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+func init() {
+	commands["patch-config"] = patchConfigCommand
+}
+
+// patchConfigCommand sets the linux.seccomp of the config.json of an OCI bundle, for runc and crun, to a
+// profile or the one generated for a binary, leaving the rest of the file as it is
+func patchConfigCommand(args []string) {
+	fs := flag.NewFlagSet("patch-config", flag.ExitOnError)
+	profilePath := fs.String("profile", "", "profile to put in the config")
+	binaryPath := fs.String("binary", "", "binary to generate the profile to put in the config for")
+	parseFlags(fs, args)
+
+	if fs.NArg() != 1 || (*profilePath == "") == (*binaryPath == "") {
+		fmt.Println("Usage: go2seccomp patch-config -profile profile.json /path/to/bundle")
+		fmt.Println("       go2seccomp patch-config -binary /path/to/binary /path/to/bundle/config.json")
+		os.Exit(1)
+	}
+	configPath := fs.Arg(0)
+	if info, err := os.Stat(configPath); err == nil && info.IsDir() {
+		configPath = filepath.Join(configPath, "config.json")
+	}
+
+	var profile specs.LinuxSeccomp
+	if *profilePath != "" {
+		profile = loadProfile(*profilePath)
+		if errs := validateProfile(profile); len(errs) > 0 {
+			for _, err := range errs {
+				fmt.Printf("%v: %v\n", *profilePath, err)
+			}
+			fatalf("Invalid profile %v", *profilePath)
+		}
+	} else {
+		graph, arch := analyzeBinary(bazelWorkingPath(*binaryPath))
+		syscallsList := getSyscallList(graph.allSyscalls(), arch)
+		fmt.Printf("Syscalls detected (total: %v): %v\n", len(syscallsList), syscallsList)
+		profileSyscalls = syscallsList
+		profile = generatedProfile(syscallsList, arch)
+	}
+
+	info, err := os.Stat(configPath)
+	if err != nil {
+		fatalf("Failed to stat %v: %v", configPath, err)
+	}
+	data, err := ioutil.ReadFile(configPath)
+	if err != nil {
+		fatalf("Failed to read config: %v", err)
+	}
+	patched, err := patchConfig(data, profile)
+	if err != nil {
+		fatalf("Failed to patch %v: %v", configPath, err)
+	}
+	if err := writeFileAtomic(configPath, patched, info.Mode()); err != nil {
+		fatalf("Failed to write %v: %v", configPath, err)
+	}
+	fmt.Printf("Set the seccomp profile of %v\n", configPath)
+}
+
+// patchConfig returns the config.json with linux.seccomp replaced by the profile, or added, keeping the other
+// fields, their order and the indentation of the file
+func patchConfig(data []byte, profile specs.LinuxSeccomp) ([]byte, error) {
+	var config jsonObject
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+	var linux jsonObject
+	if raw, ok := config.get("linux"); ok {
+		if err := json.Unmarshal(raw, &linux); err != nil {
+			return nil, fmt.Errorf("linux: %v", err)
+		}
+	}
+
+	seccomp, err := json.Marshal(profile)
+	if err != nil {
+		return nil, err
+	}
+	linux.set("seccomp", seccomp)
+	linuxJSON, err := json.Marshal(linux)
+	if err != nil {
+		return nil, err
+	}
+	config.set("linux", linuxJSON)
+	compact, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, compact, "", jsonIndentOf(data)); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// jsonIndentOf returns the indentation of the first indented line of the JSON document, runc writes
+// config.json with tabs
+func jsonIndentOf(data []byte) string {
+	for _, line := range bytes.Split(data, []byte("\n"))[1:] {
+		if indent := line[:len(line)-len(bytes.TrimLeft(line, " \t"))]; len(indent) > 0 {
+			return string(indent)
+		}
+	}
+	return "\t"
+}
+
+// jsonObject is a JSON object keeping the order of its fields, and the fields as they were written
+type jsonObject []jsonField
+
+type jsonField struct {
+	key   string
+	value json.RawMessage
+}
+
+func (o jsonObject) get(key string) (json.RawMessage, bool) {
+	for _, f := range o {
+		if f.key == key {
+			return f.value, true
+		}
+	}
+	return nil, false
+}
+
+// set replaces the value of the field, or adds it at the end
+func (o *jsonObject) set(key string, value json.RawMessage) {
+	for i := range *o {
+		if (*o)[i].key == key {
+			(*o)[i].value = value
+			return
+		}
+	}
+	*o = append(*o, jsonField{key, value})
+}
+
+func (o *jsonObject) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return fmt.Errorf("not a JSON object")
+	}
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return err
+		}
+		*o = append(*o, jsonField{t.(string), value})
+	}
+	return nil
+}
+
+func (o jsonObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(f.key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(f.value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
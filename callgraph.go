@@ -0,0 +1,187 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// callGraph records, for each function in the disassembled binary, the syscall IDs found in it
+// and the functions it references (direct calls, closures and method values loaded by address)
+type callGraph struct {
+	syscalls   map[string]map[int64]bool
+	references map[string]map[string]bool
+	sites      []syscallSite
+	argSites   []argSite
+	unresolved []unresolvedSite
+	stringRefs []stringRef
+	paths      []pathConstant
+}
+
+// syscallSite is a place in the binary where a syscall is made
+type syscallSite struct {
+	ID       int64  `json:"-"`
+	Function string `json:"function"`
+	Address  string `json:"address"`
+	// file:line as printed by go tool objdump, or the full path from DWARF when available
+	Location   string `json:"location"`
+	Confidence string `json:"confidence"`
+}
+
+// symbol references look like "CALL os.(*File).Write(SB)" or "LEAQ main.main.func1(SB), AX"
+var symbolReferenceRegexp = regexp.MustCompile(`([^\s,]+)\(SB\)`)
+
+func newCallGraph() *callGraph {
+	return &callGraph{
+		syscalls:   make(map[string]map[int64]bool),
+		references: make(map[string]map[string]bool),
+	}
+}
+
+func (g *callGraph) addFunction(function string) {
+	if _, ok := g.syscalls[function]; !ok {
+		g.syscalls[function] = make(map[int64]bool)
+		g.references[function] = make(map[string]bool)
+	}
+}
+
+// addSyscall records the syscall made by the instruction, in the format printed by go tool objdump:
+// "  file.go:152	0x47b24f	e81c340000	CALL syscall.Syscall(SB)". Detections less confident than
+// -min-confidence are only kept as sites, for reports.
+func (g *callGraph) addSyscall(function string, id int64, instruction, confidence string) {
+	g.addFunction(function)
+	if confident(confidence) {
+		g.syscalls[function][id] = true
+	}
+
+	site := syscallSite{ID: id, Function: function, Confidence: confidence}
+	// objdump pads short locations with an extra tab
+	fields := strings.Fields(instruction)
+	if len(fields) > 1 {
+		site.Location = fields[0]
+		site.Address = fields[1]
+	}
+	g.sites = append(g.sites, site)
+}
+
+// resolveSourceLines replaces the site locations with the full source paths from DWARF
+func (g *callGraph) resolveSourceLines(lines *sourceLines) {
+	for i := range g.sites {
+		addr, err := strconv.ParseUint(g.sites[i].Address, 0, 64)
+		if err != nil {
+			continue
+		}
+		if location, ok := lines.lookup(addr); ok {
+			g.sites[i].Location = location
+		}
+	}
+}
+
+// sitesOf returns the sites where the syscall is made, in the given functions or in all of them if nil
+func (g *callGraph) sitesOf(id int64, functions map[string]bool) []syscallSite {
+	var sites []syscallSite
+	for _, site := range g.sites {
+		if site.ID == id && (functions == nil || functions[site.Function]) {
+			sites = append(sites, site)
+		}
+	}
+	return sites
+}
+
+// addReferences records the symbols referenced by the instruction, the ones that aren't functions
+// (data, offsets into data) are ignored when walking the graph
+func (g *callGraph) addReferences(function, instruction string) {
+	for _, m := range symbolReferenceRegexp.FindAllStringSubmatch(instruction, -1) {
+		symbol := m[1]
+		if plus := strings.LastIndex(symbol, "+"); plus != -1 {
+			symbol = symbol[:plus]
+		}
+		if symbol != function {
+			g.addFunction(function)
+			g.references[function][symbol] = true
+		}
+	}
+}
+
+// allSyscalls is the set of syscall IDs found anywhere in the binary
+func (g *callGraph) allSyscalls() map[int64]bool {
+	ids := make(map[int64]bool)
+	for _, functionIDs := range g.syscalls {
+		for id := range functionIDs {
+			ids[id] = true
+		}
+	}
+	return ids
+}
+
+// functionsMatching returns the functions whose name matches the glob pattern
+func (g *callGraph) functionsMatching(pattern string) []string {
+	var functions []string
+	for function := range g.syscalls {
+		if globMatch(pattern, function) {
+			functions = append(functions, function)
+		}
+	}
+	return functions
+}
+
+// reachableSyscalls returns the syscall IDs found in the functions reachable from the entry functions
+func (g *callGraph) reachableSyscalls(entries []string) map[int64]bool {
+	return g.syscallsIn(g.reachableFunctions(entries))
+}
+
+// syscallsIn returns the syscall IDs found in the given functions
+func (g *callGraph) syscallsIn(functions map[string]bool) map[int64]bool {
+	ids := make(map[int64]bool)
+	for function := range functions {
+		for id := range g.syscalls[function] {
+			ids[id] = true
+		}
+	}
+	return ids
+}
+
+// reachableFunctions walks the graph from the entry functions and returns every function reached.
+// Code that runs no matter the entry point (the runtime and package initialization) is always
+// considered reachable, and so is anything it references.
+// Calls through interfaces and function values can't be followed, only symbols referenced by name.
+func (g *callGraph) reachableFunctions(entries []string) map[string]bool {
+	// runtime.main calls main.main, which is only reachable if it's one of the entries
+	visited := map[string]bool{"main.main": true}
+	for _, entry := range entries {
+		if entry == "main.main" {
+			visited = make(map[string]bool)
+		}
+	}
+	queue := append([]string{}, entries...)
+	for function := range g.syscalls {
+		if isAlwaysReachable(function) {
+			queue = append(queue, function)
+		}
+	}
+
+	reached := make(map[string]bool)
+	for len(queue) > 0 {
+		function := queue[0]
+		queue = queue[1:]
+		if visited[function] {
+			continue
+		}
+		visited[function] = true
+		reached[function] = true
+
+		for ref := range g.references[function] {
+			if _, isFunction := g.syscalls[ref]; isFunction && !visited[ref] {
+				queue = append(queue, ref)
+			}
+		}
+	}
+	return reached
+}
+
+func isAlwaysReachable(function string) bool {
+	return strings.HasPrefix(function, "runtime.") ||
+		strings.HasPrefix(function, "internal/runtime/") ||
+		strings.HasSuffix(function, ".init") ||
+		strings.Contains(function, ".init.")
+}
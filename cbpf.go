@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+func init() {
+	registerFormatter(formatFunc{"bpf", ".bpf", writeBPF})
+	registerFormatter(formatFunc{"bpf-asm", ".asm", writeBPFAsm})
+}
+
+// compileProfileBPF compiles the profile for the bpf formats, which are loaded by the host of its arches, so
+// they must all have the same byte order
+func compileProfileBPF(profile specs.LinuxSeccomp) ([]bpfInstruction, binary.ByteOrder, error) {
+	var order binary.ByteOrder
+	for _, arch := range profile.Architectures {
+		archOrder := binary.ByteOrder(binary.LittleEndian)
+		if auditArches[arch].bigEndian {
+			archOrder = binary.BigEndian
+		}
+		if order != nil && order != archOrder {
+			return nil, nil, fmt.Errorf("a BPF filter can't be loaded by both little and big endian arches: %v", profile.Architectures)
+		}
+		order = archOrder
+	}
+	if order == nil {
+		order = binary.LittleEndian
+	}
+
+	prog, err := compileBPF(profile)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(prog) > bpfMaxInstructions {
+		return nil, nil, fmt.Errorf("the filter has %v instructions, over the kernel limit of %v", len(prog), bpfMaxInstructions)
+	}
+	return prog, order, nil
+}
+
+// writeBPF writes the profile compiled to a classic BPF filter, the array of struct sock_filter to load
+// with prctl(PR_SET_SECCOMP, SECCOMP_MODE_FILTER) or seccomp(SECCOMP_SET_MODE_FILTER), len being its size / 8
+func writeBPF(w io.Writer, profile specs.LinuxSeccomp, profilePath string) error {
+	prog, order, err := compileProfileBPF(profile)
+	if err != nil {
+		return err
+	}
+	return binary.Write(w, order, prog)
+}
+
+// mnemonics of the opcodes in the syntax of bpf_asm, from the linux tools
+var bpfAsmMnemonics = map[uint16]string{
+	bpfLdAbsW: "ld",
+	bpfAndK:   "and",
+	bpfJa:     "ja",
+	bpfJeqK:   "jeq",
+	bpfJgtK:   "jgt",
+	bpfJgeK:   "jge",
+	bpfRetK:   "ret",
+}
+
+// bpf_asm mnemonics jumping when the comparison of the opcode doesn't hold
+var bpfAsmNegated = map[uint16]string{
+	bpfJeqK: "jneq",
+	bpfJgtK: "jle",
+	bpfJgeK: "jlt",
+}
+
+// writeBPFAsm writes the profile compiled to a classic BPF filter, in the syntax of bpf_asm with a label on
+// each jump target, to read the filter or assemble it with bpf_asm
+func writeBPFAsm(w io.Writer, profile specs.LinuxSeccomp, profilePath string) error {
+	prog, _, err := compileProfileBPF(profile)
+	if err != nil {
+		return err
+	}
+
+	// only the instructions jumped to get a label, not the ones jumps fall through to
+	targets := make(map[int]bool)
+	for i, insn := range prog {
+		switch insn.Code {
+		case bpfJa:
+			targets[i+1+int(insn.K)] = true
+		case bpfJeqK, bpfJgtK, bpfJgeK:
+			if insn.Jt != 0 {
+				targets[i+1+int(insn.Jt)] = true
+			}
+			if insn.Jf != 0 {
+				targets[i+1+int(insn.Jf)] = true
+			}
+		}
+	}
+	label := func(i int) string {
+		return fmt.Sprintf("l%v", i)
+	}
+
+	b := bufio.NewWriter(w)
+	fmt.Fprintf(b, "; seccomp filter generated by go2seccomp for %v, %v instructions\n", analyzedBinary, len(prog))
+	for i, insn := range prog {
+		if targets[i] {
+			fmt.Fprintf(b, "%v:\n", label(i))
+		}
+		mnemonic := bpfAsmMnemonics[insn.Code]
+		switch insn.Code {
+		case bpfLdAbsW:
+			fmt.Fprintf(b, "\t%v [%v]\n", mnemonic, insn.K)
+		case bpfAndK, bpfRetK:
+			fmt.Fprintf(b, "\t%v #%#x\n", mnemonic, insn.K)
+		case bpfJa:
+			fmt.Fprintf(b, "\t%v %v\n", mnemonic, label(i+1+int(insn.K)))
+		case bpfJeqK, bpfJgtK, bpfJgeK:
+			switch {
+			case insn.Jf == 0:
+				fmt.Fprintf(b, "\t%v #%#x, %v\n", mnemonic, insn.K, label(i+1+int(insn.Jt)))
+			case insn.Jt == 0:
+				// the negated jump, to the false branch
+				fmt.Fprintf(b, "\t%v #%#x, %v\n", bpfAsmNegated[insn.Code], insn.K, label(i+1+int(insn.Jf)))
+			default:
+				fmt.Fprintf(b, "\t%v #%#x, %v, %v\n", mnemonic, insn.K, label(i+1+int(insn.Jt)), label(i+1+int(insn.Jf)))
+			}
+		default:
+			return fmt.Errorf("invalid instruction %#x at %v", insn.Code, i)
+		}
+	}
+	return b.Flush()
+}
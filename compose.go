@@ -0,0 +1,38 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"gopkg.in/yaml.v2"
+)
+
+func init() {
+	registerFormatter(formatFunc{"compose", ".yaml", writeComposeFragment})
+}
+
+var composeService = flag.String("compose-service", "", "service of the compose output, named after the binary by default")
+
+// writeComposeFragment writes the services section of a compose file giving the service the profile, to merge
+// into docker-compose.yaml. The profile is the oci one written along with it, in the same directory.
+func writeComposeFragment(w io.Writer, profile specs.LinuxSeccomp, profilePath string) error {
+	service := *composeService
+	if service == "" {
+		service = filepath.Base(analyzedBinary)
+	}
+	base := filepath.Base(profilePath)
+	file := strings.TrimSuffix(base, filepath.Ext(base)) + formatters["oci"].Extension()
+
+	fragment := yaml.MapSlice{{Key: "services", Value: yaml.MapSlice{
+		{Key: service, Value: yaml.MapSlice{{Key: "security_opt", Value: []string{"seccomp=./" + file}}}},
+	}}}
+
+	enc := yaml.NewEncoder(w)
+	if err := enc.Encode(fragment); err != nil {
+		return err
+	}
+	return enc.Close()
+}
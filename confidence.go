@@ -0,0 +1,51 @@
+package main
+
+import "flag"
+
+// how much a detected syscall can be trusted, from the most to the least
+const (
+	// the ID is an immediate loaded right before the call
+	confidenceExact = "exact"
+	// the ID is computed, e.g. with arithmetic on ARM or loaded from a constant pool
+	confidenceDerived = "derived"
+	// the ID is an immediate found far back from the call, it may belong to something else
+	confidenceHeuristic = "heuristic"
+	// the syscall is in the default set, not detected
+	confidenceDefault = "default"
+)
+
+var confidenceRanks = map[string]int{
+	confidenceExact:     3,
+	confidenceDerived:   2,
+	confidenceHeuristic: 1,
+	confidenceDefault:   0,
+}
+
+var minConfidence = flag.String("min-confidence", confidenceDefault, "least trusted detections going in the profile: exact, derived, heuristic or default")
+
+// IDs found within this many instructions of the call are exact, further back they're heuristic
+const exactWindow = 6
+
+// windowConfidence returns the confidence of an immediate ID found distance instructions before the call
+func windowConfidence(distance int) string {
+	if distance <= exactWindow {
+		return confidenceExact
+	}
+	return confidenceHeuristic
+}
+
+// confident tells if detections with the confidence go in the profile
+func confident(confidence string) bool {
+	return confidenceRanks[confidence] >= confidenceRanks[*minConfidence]
+}
+
+// mostConfident returns the highest of the confidences, "" if there are none
+func mostConfident(confidences ...string) string {
+	best := ""
+	for _, c := range confidences {
+		if best == "" || confidenceRanks[c] > confidenceRanks[best] {
+			best = c
+		}
+	}
+	return best
+}
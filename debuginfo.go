@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var httpTimeout = flag.Duration("http-timeout", 5*time.Minute, "how long downloads, e.g. of debug info from debuginfod, may take before they're given up")
+
+// newHTTPClient returns the client of the downloads, which gives up after -http-timeout
+func newHTTPClient() *http.Client {
+	return &http.Client{Timeout: *httpTimeout}
+}
+
+// findDebugFile looks for the separate debug info of a stripped binary, first by its GNU build ID
+// and then by the file named on the .gnu_debuglink section, in the places gdb looks for them.
+// If none is found and debuginfod is enabled, it's downloaded from the DEBUGINFOD_URLS servers.
+// Returns "" if no debug file is found.
+func findDebugFile(binaryPath string, f *elf.File) (debugFile string, downloaded bool) {
+	buildID := gnuBuildID(f)
+	// the first byte of the ID names the directory
+	if len(buildID) > 2 {
+		path := filepath.Join(*debugFileDir, ".build-id", buildID[:2], buildID[2:]+".debug")
+		if hasSymbols(path) {
+			return path, false
+		}
+	}
+
+	if name, crc, ok := debugLink(f); ok {
+		dir := filepath.Dir(binaryPath)
+		absDir, _ := filepath.Abs(dir)
+		candidates := []string{
+			filepath.Join(dir, name),
+			filepath.Join(dir, ".debug", name),
+			filepath.Join(*debugFileDir, absDir, name),
+		}
+		for _, path := range candidates {
+			if hasSymbols(path) && fileCRC(path) == crc {
+				return path, false
+			}
+		}
+	}
+
+	if *debuginfod && buildID != "" {
+		if path := fetchDebuginfod(buildID); path != "" {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// gnuBuildID returns the hex encoded GNU build ID of the binary, or "" if it doesn't have one
+func gnuBuildID(f *elf.File) string {
+	sect := f.Section(".note.gnu.build-id")
+	if sect == nil {
+		return ""
+	}
+	data, err := sect.Data()
+	// note header: namesz, descsz, type, followed by "GNU\0" and the ID
+	if err != nil || len(data) < 16 {
+		return ""
+	}
+	namesz := f.ByteOrder.Uint32(data[0:4])
+	descsz := f.ByteOrder.Uint32(data[4:8])
+	start := 12 + (namesz+3)&^3
+	if uint32(len(data)) < start+descsz {
+		return ""
+	}
+	return hex.EncodeToString(data[start : start+descsz])
+}
+
+// debugLink returns the debug file name and its CRC from the .gnu_debuglink section
+func debugLink(f *elf.File) (string, uint32, bool) {
+	sect := f.Section(".gnu_debuglink")
+	if sect == nil {
+		return "", 0, false
+	}
+	data, err := sect.Data()
+	if err != nil {
+		return "", 0, false
+	}
+	end := bytes.IndexByte(data, 0)
+	if end <= 0 {
+		return "", 0, false
+	}
+	// the CRC comes after the name, aligned to 4 bytes
+	crcStart := (end + 4) &^ 3
+	if len(data) < crcStart+4 {
+		return "", 0, false
+	}
+	return string(data[:end]), f.ByteOrder.Uint32(data[crcStart:]), true
+}
+
+func hasSymbols(path string) bool {
+	f, err := elf.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	return f.Section(".symtab") != nil
+}
+
+func fileCRC(path string) uint32 {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+	h := crc32.NewIEEE()
+	io.Copy(h, f)
+	return h.Sum32()
+}
+
+// fetchDebuginfod tries each of the servers on DEBUGINFOD_URLS for the debug info of the build ID
+func fetchDebuginfod(buildID string) string {
+	for _, server := range strings.Fields(os.Getenv("DEBUGINFOD_URLS")) {
+		url := strings.TrimSuffix(server, "/") + "/buildid/" + buildID + "/debuginfo"
+		fmt.Printf("Fetching debug info from %v\n", url)
+
+		resp, err := newHTTPClient().Get(url)
+		if err != nil {
+			log.Printf("Failed to fetch debug info from %v: %v\n", server, err)
+			continue
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			continue
+		}
+
+		tmp, err := ioutil.TempFile("", "go2seccomp-debuginfo")
+		if err != nil {
+			fatalf("Failed to create temporary file: %v", err)
+		}
+		_, err = io.Copy(tmp, resp.Body)
+		resp.Body.Close()
+		tmp.Close()
+		if err != nil || !hasSymbols(tmp.Name()) {
+			os.Remove(tmp.Name())
+			continue
+		}
+		return tmp.Name()
+	}
+	return ""
+}
+
+// mergeDebugInfo uses eu-unstrip (from elfutils) to put the symbols from the debug file back into
+// a copy of the stripped binary, which go tool objdump can then disassemble
+func mergeDebugInfo(binaryPath, debugFile string) string {
+	tmp, err := ioutil.TempFile("", "go2seccomp-unstripped")
+	if err != nil {
+		fatalf("Failed to create temporary file: %v", err)
+	}
+	tmp.Close()
+
+	fmt.Printf("Using debug info from %v\n", debugFile)
+	cmd := exec.Command("eu-unstrip", "-o", tmp.Name(), binaryPath, debugFile)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		os.Remove(tmp.Name())
+		fatalf("Failed to merge debug info from %v: %v\n%s", debugFile, err, out)
+	}
+	return tmp.Name()
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+func init() {
+	commands["list-defaults"] = listDefaultsCommand
+}
+
+// listDefaultsCommand prints the syscalls every generated profile starts with, before any detection:
+// the ones the container runtime needs, plus the -base and -add ones. They're the same for every Go
+// version, the syscalls of the Go runtime are detected in each binary.
+func listDefaultsCommand(args []string) {
+	fs := flag.NewFlagSet("list-defaults", flag.ExitOnError)
+	archName := fs.String("arch", "amd64", "architecture to list the defaults for (amd64, 386, arm, arm64, ppc64le, mips, mipsle, mips64, mips64le)")
+	goVersion := fs.String("go-version", "", "not supported, the defaults are the same for every Go version")
+	parseFlags(fs, args)
+
+	if *goVersion != "" {
+		fmt.Printf("-go-version %v is not supported: the defaults are the same for every Go version, the syscalls of the Go runtime are detected in each binary\n", *goVersion)
+		os.Exit(1)
+	}
+
+	arch, ok := archByName(*archName)
+	if !ok {
+		fmt.Printf("Architecture %v is not supported\n", *archName)
+		os.Exit(1)
+	}
+
+	sources := profileSources(nil, arch)
+	names := sortedNames(sources)
+	fmt.Printf("Default syscalls for %v (total: %v):\n", arch, len(names))
+	for _, name := range names {
+		fmt.Printf("    %-20v %v\n", name, strings.Join(sources[name], ", "))
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+func init() {
+	commands["docker-build"] = dockerBuildCommand
+}
+
+// label recording the sha256 of the profile generated for an image
+const profileHashLabel = "io.go2seccomp.profile-sha256"
+
+// dockerBuildCommand runs docker build with the given arguments and generates the profile for the Go
+// binaries in the image it builds, optionally labeling the image with the digest of the profile
+func dockerBuildCommand(args []string) {
+	fs := flag.NewFlagSet("docker-build", flag.ExitOnError)
+	output := fs.String("o", "profile.json", "path to write the profile to")
+	label := fs.Bool("label", false, "label the image with the sha256 of the profile")
+	own, buildArgs := splitDockerBuildArgs(fs, args)
+	parseFlags(fs, own)
+
+	if len(buildArgs) < 1 {
+		fmt.Println("Usage: go2seccomp docker-build [-o profile.json] [-label] [--] [docker build flags] -t image:tag .")
+		os.Exit(1)
+	}
+
+	tmpDir, err := ioutil.TempDir("", "go2seccomp")
+	if err != nil {
+		fatalf("Failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	image := dockerBuild(buildArgs, filepath.Join(tmpDir, "iid"))
+
+	// the union of the syscalls of every Go binary in the final stage
+	ids, arch := analyzeImageBinaries(image, tmpDir)
+	syscallsList := getSyscallList(ids, arch)
+	fmt.Printf("Syscalls detected (total: %v): %v\n", len(syscallsList), syscallsList)
+	writeProfile(syscallsList, arch, *output)
+
+	if *label {
+		selected := selectedFormats()
+		hash := fileSHA256(formatOutputPath(*output, selected[0], len(selected)))
+		labelImage(image, dockerBuildTags(buildArgs), hash)
+	}
+}
+
+// splitDockerBuildArgs splits the arguments into the flags of the flag set and the docker build arguments, which
+// start at the first argument that isn't one of the flags, or after --
+func splitDockerBuildArgs(fs *flag.FlagSet, args []string) ([]string, []string) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			return args[:i], args[i+1:]
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			return args[:i], args[i:]
+		}
+		name := strings.TrimLeft(arg, "-")
+		hasValue := strings.Contains(name, "=")
+		if hasValue {
+			name = name[:strings.Index(name, "=")]
+		}
+		f := fs.Lookup(name)
+		if f == nil {
+			return args[:i], args[i:]
+		}
+		if b, ok := f.Value.(interface{ IsBoolFlag() bool }); !hasValue && !(ok && b.IsBoolFlag()) {
+			// the value is the next argument
+			i++
+		}
+	}
+	return args, nil
+}
+
+// analyzeImageBinaries extracts the Go binaries of the image to dir and returns the union of their syscalls
+func analyzeImageBinaries(image, dir string) (map[int64]bool, specs.Arch) {
+	binaries, err := extractGoBinaries(image, dir, "")
+	if err != nil {
+		fatalf("%v", err)
+	}
+	sort.Strings(binaries)
+	ids := make(map[int64]bool)
+	var arch specs.Arch
+	for _, binary := range binaries {
+		graph, binaryArch := analyzeBinary(binary)
+		if arch != "" && arch != binaryArch {
+			fatalf("Image %v has Go binaries for different architectures (%v, %v)", image, arch, binaryArch)
+		}
+		arch = binaryArch
+		for id := range graph.allSyscalls() {
+			ids[id] = true
+		}
+	}
+	return ids, arch
+}
+
+// dockerBuild runs docker build with the arguments and returns the ID of the image it built
+func dockerBuild(args []string, iidFile string) string {
+	fmt.Printf("Running docker build %v\n", strings.Join(args, " "))
+	cmd := exec.Command("docker", append([]string{"build", "--iidfile", iidFile}, args...)...)
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		fatalf("docker build failed: %v", err)
+	}
+	id, err := ioutil.ReadFile(iidFile)
+	if err != nil {
+		fatalf("Failed to read the ID of the built image: %v", err)
+	}
+	return strings.TrimSpace(string(id))
+}
+
+// dockerBuildTags returns the tags given to docker build with -t or --tag
+func dockerBuildTags(args []string) []string {
+	var tags []string
+	for i, arg := range args {
+		switch {
+		case (arg == "-t" || arg == "--tag") && i+1 < len(args):
+			tags = append(tags, args[i+1])
+		case strings.HasPrefix(arg, "-t="):
+			tags = append(tags, strings.TrimPrefix(arg, "-t="))
+		case strings.HasPrefix(arg, "--tag="):
+			tags = append(tags, strings.TrimPrefix(arg, "--tag="))
+		}
+	}
+	return tags
+}
+
+// labelImage adds the profile digest label to the image, building a layer on top of it and moving its tags
+func labelImage(image string, tags []string, hash string) {
+	args := []string{"build", "--label", profileHashLabel + "=" + hash}
+	for _, tag := range tags {
+		args = append(args, "-t", tag)
+	}
+	cmd := exec.Command("docker", append(args, "-")...)
+	cmd.Stdin = strings.NewReader("FROM " + image + "\n")
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		fatalf("Failed to label image %v: %v", image, err)
+	}
+	fmt.Printf("Labeled image with %v=%v\n", profileHashLabel, hash)
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+func init() {
+	commands["seccomp-gen"] = seccompGenCommand
+	commands["docker-cli-plugin-metadata"] = dockerPluginMetadataCommand
+}
+
+// dockerPluginMetadata is what the docker CLI asks its plugins for, to list them and check they're plugins
+type dockerPluginMetadata struct {
+	SchemaVersion    string
+	Vendor           string
+	Version          string `json:",omitempty"`
+	ShortDescription string
+	URL              string
+}
+
+// dockerPluginMetadataCommand prints the metadata of the docker seccomp-gen plugin. The docker CLI runs
+// docker-seccomp-gen docker-cli-plugin-metadata to find it, then docker-seccomp-gen seccomp-gen [args]
+// for docker seccomp-gen [args].
+func dockerPluginMetadataCommand(args []string) {
+	enc := json.NewEncoder(os.Stdout)
+	enc.Encode(dockerPluginMetadata{
+		SchemaVersion:    "0.1.0",
+		Vendor:           "go2seccomp",
+		Version:          version,
+		ShortDescription: "Generate a seccomp profile for the Go binaries of an image",
+		URL:              "https://github.com/xfernando/go2seccomp",
+	})
+}
+
+// seccompGenCommand generates the profile for the Go binaries of a local image, and prints how to run
+// the image with it
+func seccompGenCommand(args []string) {
+	fs := flag.NewFlagSet("seccomp-gen", flag.ExitOnError)
+	output := fs.String("o", "profile.json", "path to write the profile to")
+	parseFlags(fs, args)
+
+	// docker users put the options after the image, docker seccomp-gen IMAGE -o profile.json
+	var images []string
+	for fs.NArg() > 0 {
+		images = append(images, fs.Arg(0))
+		parseFlags(fs, fs.Args()[1:])
+	}
+	if len(images) != 1 {
+		fmt.Println("Usage: docker seccomp-gen IMAGE [-o profile.json]")
+		os.Exit(1)
+	}
+	image := images[0]
+
+	tmpDir, err := ioutil.TempDir("", "go2seccomp")
+	if err != nil {
+		fatalf("Failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	ids, arch := analyzeImageBinaries(image, tmpDir)
+	syscallsList := getSyscallList(ids, arch)
+	fmt.Printf("Syscalls detected (total: %v): %v\n", len(syscallsList), syscallsList)
+	writeProfile(syscallsList, arch, *output)
+
+	profilePath, err := filepath.Abs(formatOutputPath(*output, "oci", len(selectedFormats())))
+	if err != nil {
+		profilePath = *output
+	}
+	fmt.Printf("\nRun the image with the profile with:\n\ndocker run --security-opt seccomp=%v %v\n", profilePath, image)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"io"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+func init() {
+	registerFormatter(formatFunc{"docker", ".json", writeDockerProfile})
+}
+
+// dockerProfile is a profile in the format of docker's default one, with an archMap instead of the list of
+// architectures, so a single profile covers the hosts of each arch
+type dockerProfile struct {
+	DefaultAction   specs.LinuxSeccompAction `json:"defaultAction"`
+	DefaultErrnoRet *uint                    `json:"defaultErrnoRet,omitempty"`
+	ArchMap         []dockerArchMap          `json:"archMap"`
+	ListenerPath    string                   `json:"listenerPath,omitempty"`
+	Flags           []specs.LinuxSeccompFlag `json:"flags,omitempty"`
+	Syscalls        []specs.LinuxSyscall     `json:"syscalls"`
+}
+
+// dockerArchMap is an arch and the ones its hosts also run, docker loads the entry of the host arch
+type dockerArchMap struct {
+	Architecture     specs.Arch   `json:"architecture"`
+	SubArchitectures []specs.Arch `json:"subArchitectures"`
+}
+
+// the arch whose hosts also run each arch, which is a sub-architecture of it when the profile has both
+var dockerSubArchitectureOf = map[specs.Arch]specs.Arch{
+	specs.ArchX86:    specs.ArchX86_64,
+	specs.ArchX32:    specs.ArchX86_64,
+	specs.ArchARM:    specs.ArchAARCH64,
+	specs.ArchMIPS:   specs.ArchMIPS64,
+	specs.ArchMIPSEL: specs.ArchMIPSEL64,
+}
+
+// dockerArchitectures groups the architectures of the profile into the archMap of docker
+func dockerArchitectures(archs []specs.Arch) []dockerArchMap {
+	var archMap []dockerArchMap
+	for _, arch := range archs {
+		if parent, ok := dockerSubArchitectureOf[arch]; ok && archIndex(archs, parent) != -1 {
+			continue
+		}
+		entry := dockerArchMap{Architecture: arch, SubArchitectures: []specs.Arch{}}
+		for _, sub := range archs {
+			if dockerSubArchitectureOf[sub] == arch {
+				entry.SubArchitectures = append(entry.SubArchitectures, sub)
+			}
+		}
+		archMap = append(archMap, entry)
+	}
+	return archMap
+}
+
+// writeDockerProfile writes the profile in the format of docker's default profile, for
+// docker run --security-opt seccomp=profile.json
+func writeDockerProfile(w io.Writer, profile specs.LinuxSeccomp, profilePath string) error {
+	enc := newProfileEncoder(w)
+	return enc.Encode(dockerProfile{
+		DefaultAction:   profile.DefaultAction,
+		DefaultErrnoRet: profile.DefaultErrnoRet,
+		ArchMap:         dockerArchitectures(profile.Architectures),
+		ListenerPath:    profile.ListenerPath,
+		Flags:           profile.Flags,
+		Syscalls:        profile.Syscalls,
+	})
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"debug/dwarf"
+	"debug/elf"
+	"fmt"
+	"sort"
+)
+
+// sourceLines maps instruction addresses to the source file and line they were compiled from,
+// using the DWARF line tables
+type sourceLines struct {
+	rows []lineRow
+}
+
+type lineRow struct {
+	address uint64
+	file    string
+	line    int
+	// marks the first address after a sequence of instructions, which has no source line
+	end bool
+}
+
+// loadSourceLines reads the DWARF line tables of the binary, returning nil if it has no DWARF
+// (built with -ldflags=-w)
+func loadSourceLines(f *elf.File) *sourceLines {
+	d, err := f.DWARF()
+	if err != nil {
+		return nil
+	}
+
+	lines := &sourceLines{}
+	r := d.Reader()
+	for {
+		entry, err := r.Next()
+		if err != nil || entry == nil {
+			break
+		}
+		if entry.Tag != dwarf.TagCompileUnit {
+			r.SkipChildren()
+			continue
+		}
+		lr, err := d.LineReader(entry)
+		if err != nil || lr == nil {
+			continue
+		}
+		var le dwarf.LineEntry
+		for lr.Next(&le) == nil {
+			row := lineRow{address: le.Address, line: le.Line, end: le.EndSequence}
+			if le.File != nil {
+				row.file = le.File.Name
+			}
+			lines.rows = append(lines.rows, row)
+		}
+	}
+
+	if len(lines.rows) == 0 {
+		return nil
+	}
+	// a sequence can start at the address the previous one ends, the end row must come first
+	sort.SliceStable(lines.rows, func(i, j int) bool {
+		a, b := lines.rows[i], lines.rows[j]
+		return a.address < b.address || a.address == b.address && a.end && !b.end
+	})
+	return lines
+}
+
+// lookup returns file:line for the instruction at addr
+func (s *sourceLines) lookup(addr uint64) (string, bool) {
+	i := sort.Search(len(s.rows), func(i int) bool { return s.rows[i].address > addr }) - 1
+	if i < 0 || s.rows[i].end || s.rows[i].file == "" {
+		return "", false
+	}
+	return fmt.Sprintf("%v:%v", bazelSourcePath(s.rows[i].file), s.rows[i].line), true
+}
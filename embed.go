@@ -0,0 +1,108 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+func init() {
+	commands["embed"] = embedCommand
+	commands["extract"] = extractCommand
+}
+
+// ELF section the profile is embedded in. It isn't loaded in memory, so it doesn't change how the binary runs.
+const profileSection = ".go2seccomp.profile"
+
+// embedCommand writes the profile into a section of the binary, replacing the one embedded before, if any
+func embedCommand(args []string) {
+	fs := flag.NewFlagSet("embed", flag.ExitOnError)
+	output := fs.String("o", "", "path to write the binary with the profile to (default the binary itself)")
+	parseFlags(fs, args)
+
+	if fs.NArg() < 2 {
+		fmt.Println("Usage: go2seccomp embed [-o output] /path/to/binary /path/to/profile.json")
+		os.Exit(1)
+	}
+	binaryPath, profilePath := fs.Arg(0), fs.Arg(1)
+	if *output == "" {
+		*output = binaryPath
+	}
+
+	if errs := validateProfile(loadProfile(profilePath)); len(errs) > 0 {
+		for _, err := range errs {
+			fmt.Printf("%v: %v\n", profilePath, err)
+		}
+		fatalf("Not embedding invalid profile %v", profilePath)
+	}
+
+	f := openElf(binaryPath)
+	embedded := f.Section(profileSection) != nil
+	f.Close()
+
+	// objcopy writes to a temporary file next to the output, renamed into place once complete
+	tmp, err := ioutil.TempFile(filepath.Dir(*output), "."+filepath.Base(*output)+".tmp")
+	if err != nil {
+		fatalf("Failed to create temporary file: %v", err)
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+
+	var cmd *exec.Cmd
+	if embedded {
+		cmd = exec.Command("objcopy", "--update-section", profileSection+"="+profilePath, binaryPath, tmp.Name())
+	} else {
+		cmd = exec.Command("objcopy", "--add-section", profileSection+"="+profilePath,
+			"--set-section-flags", profileSection+"=noload,readonly", binaryPath, tmp.Name())
+	}
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		fatalf("Failed to embed the profile with objcopy: %v", err)
+	}
+
+	info, err := os.Stat(binaryPath)
+	if err != nil {
+		fatalf("Failed to stat %v: %v", binaryPath, err)
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode()); err != nil {
+		fatalf("Failed to set the mode of %v: %v", *output, err)
+	}
+	if err := os.Rename(tmp.Name(), *output); err != nil {
+		fatalf("Failed to write %v: %v", *output, err)
+	}
+	fmt.Printf("Embedded %v in %v\n", profilePath, *output)
+}
+
+// extractCommand reads the profile embedded in a binary
+func extractCommand(args []string) {
+	fs := flag.NewFlagSet("extract", flag.ExitOnError)
+	output := fs.String("o", "", "path to write the profile to (default stdout)")
+	parseFlags(fs, args)
+
+	if fs.NArg() < 1 {
+		fmt.Println("Usage: go2seccomp extract [-o profile.json] /path/to/binary")
+		os.Exit(1)
+	}
+
+	f := openElf(fs.Arg(0))
+	defer f.Close()
+	section := f.Section(profileSection)
+	if section == nil {
+		fmt.Printf("%v has no embedded profile\n", fs.Arg(0))
+		os.Exit(1)
+	}
+	data, err := section.Data()
+	if err != nil {
+		fatalf("Failed to read the embedded profile: %v", err)
+	}
+
+	if *output == "" {
+		os.Stdout.Write(data)
+		return
+	}
+	writeProfileFile(*output, data)
+	fmt.Printf("Saved seccomp profile at %v\n", *output)
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+// buildFixture builds testdata/hello for the arch, with a GNU build ID
+func buildFixture(t *testing.T, goarch string) string {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("builds a binary")
+	}
+	out := filepath.Join(t.TempDir(), "hello-"+goarch)
+	cmd := exec.Command("go", "build", "-ldflags=-B=gobuildid", "-o", out, "testdata/hello/main.go")
+	cmd.Env = append(os.Environ(), "GOOS=linux", "GOARCH="+goarch, "CGO_ENABLED=0")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("Failed to build the %v fixture: %v\n%s", goarch, err, output)
+	}
+	return out
+}
+
+func TestBigEndianFixtures(t *testing.T) {
+	tests := []struct {
+		goarch string
+		arch   specs.Arch
+	}{
+		{"mips", specs.ArchMIPS},
+		{"mips64", specs.ArchMIPS64},
+		{"mipsle", specs.ArchMIPSEL},
+		{"amd64", specs.ArchX86_64},
+	}
+	for _, test := range tests {
+		t.Run(test.goarch, func(t *testing.T) {
+			path := buildFixture(t, test.goarch)
+			f := openElf(path)
+			defer f.Close()
+
+			if arch := getArch(f); arch != test.arch {
+				t.Errorf("getArch() = %v, want %v", arch, test.arch)
+			}
+			// read in the wrong byte order, the note sizes are off and no ID is found
+			if id := gnuBuildID(f); len(id) != 40 {
+				t.Errorf("gnuBuildID() = %q, want a 20 byte ID", id)
+			}
+			if godebug := readBinaryGodebug(path); !strings.Contains(godebug, "panicnil=1") {
+				t.Errorf("readBinaryGodebug() = %q, want panicnil=1 in it", godebug)
+			}
+		})
+	}
+}
+
+func TestBigEndianAnalysis(t *testing.T) {
+	path := buildFixture(t, "mips")
+	graph, arch := analyzeBinary(path)
+	if arch != specs.ArchMIPS {
+		t.Fatalf("analyzeBinary() arch = %v, want %v", arch, specs.ArchMIPS)
+	}
+	names := getSyscallList(graph.allSyscalls(), arch)
+	for _, want := range []string{"write", "exit_group"} {
+		found := false
+		for _, name := range names {
+			found = found || name == want
+		}
+		if !found {
+			t.Errorf("%v not detected, got %v", want, names)
+		}
+	}
+}
+
+// textSection returns a .text section at 0x1000 holding the words in the byte order
+func textSection(order binary.ByteOrder, words ...uint32) *elf.Section {
+	data := make([]byte, 4*len(words))
+	for i, word := range words {
+		order.PutUint32(data[4*i:], word)
+	}
+	return &elf.Section{
+		SectionHeader: elf.SectionHeader{Name: ".text", Addr: 0x1000, Size: uint64(len(data))},
+		ReaderAt:      bytes.NewReader(data),
+	}
+}
+
+func TestReadARMWord(t *testing.T) {
+	defer func(text *elf.Section, order binary.ByteOrder) { armText, binaryByteOrder = text, order }(armText, binaryByteOrder)
+
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		armText, binaryByteOrder = textSection(order, 0xdeadbeef, 0x1b1), order
+		tests := []struct {
+			address uint64
+			word    uint32
+			ok      bool
+		}{
+			{0x1000, 0xdeadbeef, true},
+			{0x1004, 0x1b1, true},
+			{0x0ffc, 0, false},
+			{0x1006, 0, false},
+			{0x1008, 0, false},
+		}
+		for _, test := range tests {
+			word, ok := readARMWord(test.address)
+			if word != test.word || ok != test.ok {
+				t.Errorf("%v: readARMWord(%#x) = %#x, %v, want %#x, %v", order, test.address, word, ok, test.word, test.ok)
+			}
+		}
+	}
+}
+
+func TestARMConstantPool(t *testing.T) {
+	defer func(text *elf.Section, order binary.ByteOrder) { armText, binaryByteOrder = text, order }(armText, binaryByteOrder)
+
+	// MOVW 0x4(R15), R7 at 0x1000 loads the word at 0x1000+8+4
+	instructions := make([]string, previousInstructionsBufferSize)
+	instructions[1] = "  asm_linux_arm.s:10\t0x1000\t\te59f7004\t\tMOVW 0x4(R15), R7"
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		armText, binaryByteOrder = textSection(order, 0, 0, 0, 0x1b1), order
+		id, confidence, err := findRuntimeSyscallIDARM(instructions, 1)
+		if err != nil || id != 0x1b1 || confidence != confidenceDerived {
+			t.Errorf("%v: findRuntimeSyscallIDARM() = %v, %v, %v, want %v, %v", order, id, confidence, err, 0x1b1, confidenceDerived)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+// entrypoint is an applet of a multi-call binary, with the glob patterns of the functions implementing it
+type entrypoint struct {
+	name     string
+	patterns []string
+}
+
+// parseEntrypoints parses a comma separated list of name=pattern entries. A bare name stands for
+// all the functions of a package of that name, e.g. "ls" is "ls.*" and "*/ls.*"
+func parseEntrypoints(list string) []entrypoint {
+	var entries []entrypoint
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		e := entrypoint{name: item, patterns: []string{item + ".*", "*/" + item + ".*"}}
+		if eq := strings.Index(item, "="); eq != -1 {
+			e.name, e.patterns = item[:eq], []string{item[eq+1:]}
+		}
+		if e.name == "" || e.patterns[0] == "" {
+			fatalf("Invalid entrypoint %q, expected name or name=function", item)
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// resolveEntryFuncs returns the functions matching the given names or glob patterns, exiting if
+// any of them doesn't match a function in the binary
+func resolveEntryFuncs(graph *callGraph, patterns []string) []string {
+	var functions []string
+	for _, pattern := range patterns {
+		matching := graph.functionsMatching(pattern)
+		if len(matching) == 0 {
+			fatalf("Entry function %v not found in the binary", pattern)
+		}
+		functions = append(functions, matching...)
+	}
+	return functions
+}
+
+// entrypointProfilePath puts the entrypoint name before the profile extension, profile.json => profile.ls.json
+func entrypointProfilePath(profilePath, name string) string {
+	ext := filepath.Ext(profilePath)
+	return strings.TrimSuffix(profilePath, ext) + "." + name + ext
+}
+
+// writeEntrypointProfiles writes one profile per entrypoint, with only the syscalls reachable from it,
+// and their union at profilePath
+func writeEntrypointProfiles(graph *callGraph, arch specs.Arch, entries []entrypoint, profilePath string) {
+	union := make(map[int64]bool)
+
+	for _, e := range entries {
+		var functions []string
+		for _, pattern := range e.patterns {
+			functions = append(functions, graph.functionsMatching(pattern)...)
+		}
+		if len(functions) == 0 {
+			fatalf("No functions found for entrypoint %v (%v)", e.name, strings.Join(e.patterns, ", "))
+		}
+
+		ids := graph.reachableSyscalls(functions)
+		for id := range ids {
+			union[id] = true
+		}
+
+		syscallsList := getSyscallList(ids, arch)
+		fmt.Printf("Syscalls detected for %v (total: %v): %v\n", e.name, len(syscallsList), syscallsList)
+		writeProfile(syscallsList, arch, entrypointProfilePath(profilePath, e.name))
+	}
+
+	syscallsList := getSyscallList(union, arch)
+	fmt.Printf("Syscalls detected for all entrypoints (total: %v): %v\n", len(syscallsList), syscallsList)
+	writeProfile(syscallsList, arch, profilePath)
+}
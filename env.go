@@ -0,0 +1,47 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"strings"
+)
+
+// flags can also be set with environment variables, e.g. GO2SECCOMP_FORMAT for -format and
+// GO2SECCOMP_POD_OUT_DIR for the -out-dir of the pod subcommand. The command line takes precedence.
+const envPrefix = "GO2SECCOMP_"
+
+// envName returns the environment variable setting the flag of the flag set
+func envName(fs *flag.FlagSet, name string) string {
+	prefix := envPrefix
+	if fs != flag.CommandLine {
+		prefix += fs.Name() + "_"
+	}
+	return strings.ToUpper(strings.Replace(prefix+name, "-", "_", -1))
+}
+
+// applyEnvironment sets the flags not given on the command line from the environment
+func applyEnvironment(fs *flag.FlagSet) {
+	given := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		given[f.Name] = true
+	})
+	fs.VisitAll(func(f *flag.Flag) {
+		if given[f.Name] {
+			return
+		}
+		name := envName(fs, f.Name)
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return
+		}
+		if err := fs.Set(f.Name, value); err != nil {
+			fatalf("Invalid %v: %v", name, err)
+		}
+	})
+}
+
+// parseFlags parses the flags of a subcommand, then sets the rest from the environment
+func parseFlags(fs *flag.FlagSet, args []string) {
+	fs.Parse(args)
+	applyEnvironment(fs)
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+func init() {
+	commands["explain"] = explainCommand
+}
+
+// explainCommand prints where in the binary each syscall is made
+func explainCommand(args []string) {
+	if len(args) < 1 {
+		fmt.Println("Usage: go2seccomp explain /path/to/binary [syscall...]")
+		os.Exit(1)
+	}
+
+	graph, arch := analyzeBinary(args[0])
+	ids := graph.allSyscalls()
+	r := buildReport(args[0], graph, arch, syscallSources(ids, arch), ids, nil)
+
+	wanted := make(map[string]bool)
+	for _, name := range args[1:] {
+		wanted[name] = true
+	}
+
+	for _, s := range r.Syscalls {
+		if len(wanted) > 0 && !wanted[s.Name] {
+			continue
+		}
+		delete(wanted, s.Name)
+
+		fmt.Printf("%v (%v), call sites: %v\n", s.Name, s.ID, s.CallSites)
+		if len(s.Sites) == 0 {
+			fmt.Printf("    not found in the binary, included from: %v\n", strings.Join(s.Sources, ", "))
+		}
+		for _, site := range s.Sites {
+			if site.Address == "" {
+				// found by symbol, without an instruction
+				fmt.Printf("    %v (%v)\n", site.Function, site.Confidence)
+				continue
+			}
+			fmt.Printf("    %v at %v (%v, %v)\n", site.Function, site.Location, site.Address, site.Confidence)
+		}
+	}
+
+	if len(wanted) > 0 {
+		var missing []string
+		for name := range wanted {
+			missing = append(missing, name)
+		}
+		fatalf("Syscalls not found in the binary: %v", strings.Join(missing, ", "))
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import "flag"
+
+var expandFamilies = flag.Bool("expand-families", false, "when a syscall is detected, also allow the related ones the standard library may switch to across Go versions")
+
+// equivalent variants of the same operation, code paths move between them across Go versions, arches and
+// kernels. Detecting one must never allow another operation, e.g. getrlimit doesn't bring prlimit64, which can
+// also set limits, and sendfile doesn't bring splice.
+var syscallFamilies = [][]string{
+	{"stat", "fstat", "lstat", "newfstatat", "statx", "stat64", "fstat64", "lstat64", "fstatat64"},
+	{"open", "openat", "openat2"},
+	{"access", "faccessat", "faccessat2"},
+	{"recvfrom", "recvmsg", "recvmmsg"},
+	{"sendto", "sendmsg", "sendmmsg"},
+	{"accept", "accept4"},
+	{"dup", "dup2", "dup3"},
+	{"pipe", "pipe2"},
+	{"poll", "ppoll"},
+	{"select", "pselect6"},
+	{"epoll_create", "epoll_create1"},
+	{"epoll_wait", "epoll_pwait", "epoll_pwait2"},
+	{"eventfd", "eventfd2"},
+	{"inotify_init", "inotify_init1"},
+	{"signalfd", "signalfd4"},
+	{"clone", "clone3"},
+	{"wait4", "waitid"},
+	{"rename", "renameat", "renameat2"},
+	{"unlink", "unlinkat"},
+	{"mkdir", "mkdirat"},
+	{"readlink", "readlinkat"},
+	{"chmod", "fchmod", "fchmodat", "fchmodat2"},
+	{"chown", "fchown", "lchown", "fchownat"},
+	{"utimes", "utimensat", "futimesat"},
+	{"getdents", "getdents64"},
+	{"pread64", "preadv", "preadv2"},
+	{"pwrite64", "pwritev", "pwritev2"},
+	{"execve", "execveat"},
+	{"sendfile", "sendfile64"},
+}
+
+// familyOf returns the other members of the families the syscall is in
+func familyOf(name string) []string {
+	var siblings []string
+	for _, family := range syscallFamilies {
+		in := false
+		for _, member := range family {
+			in = in || member == name
+		}
+		if !in {
+			continue
+		}
+		for _, member := range family {
+			if member != name {
+				siblings = append(siblings, member)
+			}
+		}
+	}
+	return siblings
+}
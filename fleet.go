@@ -0,0 +1,328 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"gopkg.in/yaml.v2"
+)
+
+func init() {
+	commands["fleet"] = fleetCommand
+}
+
+// fleetWorkload is a binary or image of the fleet manifest
+type fleetWorkload struct {
+	Name   string `yaml:"name"`
+	Binary string `yaml:"binary"`
+	Image  string `yaml:"image"`
+}
+
+type fleetManifest struct {
+	Workloads []fleetWorkload `yaml:"workloads"`
+}
+
+// options of the fleet run that aren't passed on to the analysis of each binary
+var fleetIgnoredOptions = map[string]bool{
+	"summary-file": true, "interactive": true, "fail-on-new-namespaces": true, "entrypoints": true, "annotate": true,
+}
+
+// fleetReport is the aggregated report of the fleet
+type fleetReport struct {
+	Workloads []workloadReport `json:"workloads"`
+	// syscalls every workload uses
+	Core []string `json:"core"`
+	// high risk syscalls, with the workloads using them
+	Dangerous map[string][]string `json:"dangerous"`
+}
+
+type workloadReport struct {
+	Name   string     `json:"name"`
+	Binary string     `json:"binary,omitempty"`
+	Image  string     `json:"image,omitempty"`
+	Arch   specs.Arch `json:"arch,omitempty"`
+	// the union of the syscalls of the Go binaries of the workload
+	Syscalls []string `json:"syscalls"`
+	// syscalls the workload uses besides the core ones
+	Extras    []string `json:"extras"`
+	Dangerous []string `json:"dangerous,omitempty"`
+	// the pledge promises of the Go binaries of the workload, see promisesOf
+	Promises []string `json:"promises,omitempty"`
+	Error    string   `json:"error,omitempty"`
+}
+
+func loadFleetManifest(path string) []fleetWorkload {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		fatalf("Failed to read fleet manifest: %v", err)
+	}
+	var m fleetManifest
+	if err := yaml.UnmarshalStrict(data, &m); err != nil {
+		fatalf("Failed to parse fleet manifest %v: %v", path, err)
+	}
+	names := make(map[string]bool)
+	for i := range m.Workloads {
+		w := &m.Workloads[i]
+		if (w.Binary == "") == (w.Image == "") {
+			fatalf("Workload #%v of %v: exactly one of binary or image must be set", i+1, path)
+		}
+		if w.Name == "" {
+			w.Name = w.Image
+			if w.Binary != "" {
+				w.Name = filepath.Base(w.Binary)
+			}
+		}
+		if names[w.Name] {
+			fatalf("Workload %v is in %v twice, give them different names", w.Name, path)
+		}
+		names[w.Name] = true
+	}
+	if len(m.Workloads) == 0 {
+		fatalf("No workloads in %v", path)
+	}
+	return m.Workloads
+}
+
+// fleetAnalyzer analyzes binaries in go2seccomp subprocesses, each in its own directory since the analysis
+// isn't safe to run concurrently, and caches the syscalls by the sha256 of the binary
+type fleetAnalyzer struct {
+	tmpDir  string
+	options []string
+
+	mu    sync.Mutex
+	cache map[string]*report
+}
+
+// analyze returns the report of the binary, reusing the one of an identical binary already analyzed
+func (fa *fleetAnalyzer) analyze(binaryPath string) (*report, error) {
+	data, err := ioutil.ReadFile(binaryPath)
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256Hex(data)
+	fa.mu.Lock()
+	r, ok := fa.cache[hash]
+	fa.mu.Unlock()
+	if ok {
+		return r, nil
+	}
+
+	dir, err := ioutil.TempDir(fa.tmpDir, "binary")
+	if err != nil {
+		return nil, err
+	}
+	abs, err := filepath.Abs(binaryPath)
+	if err != nil {
+		return nil, err
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	args := append(append([]string{}, fa.options...), "-format=oci", "-report=report.json", abs, "profile.json")
+	cmd := exec.Command(executable, args...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("analysis of %v failed: %v\n%s", binaryPath, err, out)
+	}
+
+	data, err = ioutil.ReadFile(filepath.Join(dir, "report.json"))
+	if err != nil {
+		return nil, err
+	}
+	r = &report{}
+	if err := json.Unmarshal(data, r); err != nil {
+		return nil, fmt.Errorf("failed to parse the report of %v: %v", binaryPath, err)
+	}
+	fa.mu.Lock()
+	fa.cache[hash] = r
+	fa.mu.Unlock()
+	return r, nil
+}
+
+// analyzeWorkload returns the syscalls of the Go binaries of the workload
+func (fa *fleetAnalyzer) analyzeWorkload(w fleetWorkload) workloadReport {
+	result := workloadReport{Name: w.Name, Binary: w.Binary, Image: w.Image}
+	binaries := []string{w.Binary}
+	if w.Image != "" {
+		dir, err := ioutil.TempDir(fa.tmpDir, "image")
+		if err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		if binaries, err = extractGoBinaries(w.Image, dir, ""); err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		sort.Strings(binaries)
+	}
+
+	syscalls := make(map[string]bool)
+	promises := make(map[string]bool)
+	for _, binary := range binaries {
+		r, err := fa.analyze(binary)
+		if err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		if result.Arch != "" && result.Arch != r.Arch {
+			result.Error = fmt.Sprintf("Go binaries for different architectures (%v, %v)", result.Arch, r.Arch)
+			return result
+		}
+		result.Arch = r.Arch
+		for _, s := range r.Syscalls {
+			syscalls[s.Name] = true
+		}
+		for _, promise := range r.Promises {
+			promises[promise] = true
+		}
+	}
+	for _, promise := range pledgeOrder {
+		if promises[promise] {
+			result.Promises = append(result.Promises, promise)
+		}
+	}
+	for name := range syscalls {
+		result.Syscalls = append(result.Syscalls, name)
+		if riskOf(name).Tier == riskHigh {
+			result.Dangerous = append(result.Dangerous, name)
+		}
+	}
+	sort.Strings(result.Syscalls)
+	sort.Strings(result.Dangerous)
+	return result
+}
+
+// fleetOptions returns the global flags given to go2seccomp, to analyze each binary with them
+func fleetOptions() []string {
+	var options []string
+	flag.Visit(func(f *flag.Flag) {
+		if !lockIgnoredOptions[f.Name] && !fleetIgnoredOptions[f.Name] {
+			options = append(options, "-"+f.Name+"="+f.Value.String())
+		}
+	})
+	return options
+}
+
+// fleetCommand analyzes the binaries and images of a fleet manifest concurrently and writes the report
+// of the syscalls they all share, the ones each adds to them, and which use dangerous syscalls
+func fleetCommand(args []string) {
+	fs := flag.NewFlagSet("fleet", flag.ExitOnError)
+	output := fs.String("o", "fleet-report.json", "path to write the fleet report to")
+	jobs := fs.Int("j", runtime.NumCPU(), "number of workloads to analyze at once")
+	parseFlags(fs, args)
+
+	if fs.NArg() != 1 || *jobs < 1 {
+		fmt.Println("Usage: go2seccomp [options] fleet [-j jobs] [-o fleet-report.json] manifest.yaml")
+		os.Exit(1)
+	}
+	workloads := loadFleetManifest(fs.Arg(0))
+
+	tmpDir, err := ioutil.TempDir("", "go2seccomp")
+	if err != nil {
+		fatalf("Failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	fa := &fleetAnalyzer{tmpDir: tmpDir, options: fleetOptions(), cache: make(map[string]*report)}
+	results := make([]workloadReport, len(workloads))
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < *jobs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range queue {
+				results[j] = fa.analyzeWorkload(workloads[j])
+				if results[j].Error != "" {
+					fmt.Printf("%v: %v\n", workloads[j].Name, results[j].Error)
+				} else {
+					fmt.Printf("%v: %v syscalls\n", workloads[j].Name, len(results[j].Syscalls))
+				}
+			}
+		}()
+	}
+	for i := range workloads {
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+
+	fleet := aggregateFleet(results)
+	fmt.Printf("Syscalls every workload uses (total: %v): %v\n", len(fleet.Core), fleet.Core)
+	for _, w := range fleet.Workloads {
+		if w.Error == "" && len(w.Extras) > 0 {
+			fmt.Printf("Syscalls %v adds (total: %v): %v\n", w.Name, len(w.Extras), w.Extras)
+		}
+	}
+	dangerous := make([]string, 0, len(fleet.Dangerous))
+	for name := range fleet.Dangerous {
+		dangerous = append(dangerous, name)
+	}
+	sort.Strings(dangerous)
+	for _, name := range dangerous {
+		fmt.Printf("%v is used by %v\n", name, strings.Join(fleet.Dangerous[name], ", "))
+	}
+
+	data, err := json.MarshalIndent(fleet, "", "    ")
+	if err != nil {
+		fatalf("Failed to encode the fleet report: %v", err)
+	}
+	if err := writeFileAtomic(*output, append(data, '\n'), 0644); err != nil {
+		fatalf("Failed to write the fleet report: %v", err)
+	}
+	fmt.Printf("Saved fleet report at %v\n", *output)
+
+	for _, w := range fleet.Workloads {
+		if w.Error != "" {
+			os.RemoveAll(tmpDir)
+			fatalf("Some workloads couldn't be analyzed, see %v", *output)
+		}
+	}
+}
+
+// aggregateFleet finds the core syscalls of the analyzed workloads, the extras of each one, and which
+// workloads use each dangerous syscall
+func aggregateFleet(workloads []workloadReport) fleetReport {
+	fleet := fleetReport{Workloads: workloads, Core: []string{}, Dangerous: make(map[string][]string)}
+	counts := make(map[string]int)
+	analyzed := 0
+	for _, w := range workloads {
+		if w.Error != "" {
+			continue
+		}
+		analyzed++
+		for _, name := range w.Syscalls {
+			counts[name]++
+		}
+		for _, name := range w.Dangerous {
+			fleet.Dangerous[name] = append(fleet.Dangerous[name], w.Name)
+		}
+	}
+	for name, count := range counts {
+		if count == analyzed {
+			fleet.Core = append(fleet.Core, name)
+		}
+	}
+	sort.Strings(fleet.Core)
+	for i := range fleet.Workloads {
+		w := &fleet.Workloads[i]
+		w.Extras = []string{}
+		for _, name := range w.Syscalls {
+			if counts[name] < analyzed {
+				w.Extras = append(w.Extras, name)
+			}
+		}
+	}
+	return fleet
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"gopkg.in/yaml.v2"
+)
+
+func init() {
+	commands["fmt"] = fmtCommand
+}
+
+// formattedProfile is an OCI profile as fmt writes it, keeping the fragments it includes
+type formattedProfile struct {
+	ociProfile
+	Include []string `json:"include,omitempty"`
+}
+
+// fmtCommand rewrites profiles in the format go2seccomp writes them, like gofmt: to stdout, in place with -w,
+// or listing the ones that aren't formatted with -l
+func fmtCommand(args []string) {
+	fs := flag.NewFlagSet("fmt", flag.ExitOnError)
+	write := fs.Bool("w", false, "write the formatted profile back to the file instead of stdout")
+	list := fs.Bool("l", false, "list the profiles that aren't formatted instead of printing them")
+	parseFlags(fs, args)
+
+	if fs.NArg() < 1 {
+		fmt.Println("Usage: go2seccomp fmt [-w] [-l] profile.json...")
+		os.Exit(1)
+	}
+
+	for _, path := range fs.Args() {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			fatalf("Failed to read profile: %v", err)
+		}
+		formatted, err := formatProfileData(data, isYAMLPath(path))
+		if err != nil {
+			fatalf("Failed to format %v: %v", path, err)
+		}
+
+		switch {
+		case *list:
+			if !bytes.Equal(data, formatted) {
+				fmt.Println(path)
+			}
+		case *write:
+			if bytes.Equal(data, formatted) {
+				continue
+			}
+			info, err := os.Stat(path)
+			if err != nil {
+				fatalf("Failed to stat %v: %v", path, err)
+			}
+			if err := writeFileAtomic(path, formatted, info.Mode()); err != nil {
+				fatalf("Failed to write %v: %v", path, err)
+			}
+		default:
+			os.Stdout.Write(formatted)
+		}
+	}
+}
+
+// formatProfileData parses a JSON or YAML profile, without merging the fragments it includes,
+// and encodes it back canonicalized
+func formatProfileData(data []byte, isYAML bool) ([]byte, error) {
+	jsonData := data
+	if isYAML {
+		var err error
+		if jsonData, err = yamlToJSON(data); err != nil {
+			return nil, err
+		}
+	}
+	var profile specs.LinuxSeccomp
+	if err := json.Unmarshal(jsonData, &profile); err != nil {
+		return nil, err
+	}
+	var includes profileIncludes
+	if err := json.Unmarshal(jsonData, &includes); err != nil {
+		return nil, err
+	}
+
+	profile = canonicalProfile(profile)
+	var buf bytes.Buffer
+	enc := newProfileEncoder(&buf)
+	meta := profileMetadata{MinKernel: profileMinKernel(profile).String()}
+	if err := enc.Encode(formattedProfile{ociProfile{profile, meta}, includes.Include}); err != nil {
+		return nil, err
+	}
+	if !isYAML {
+		return buf.Bytes(), nil
+	}
+	// JSON is YAML, decoding it into a MapSlice keeps the order of the fields
+	var doc yaml.MapSlice
+	if err := yaml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(doc)
+}
+
+// ruleKey tells rules whose names can be merged: same action, errno and conditions
+func ruleKey(rule specs.LinuxSyscall) string {
+	errno := ""
+	if rule.ErrnoRet != nil {
+		errno = fmt.Sprint(*rule.ErrnoRet)
+	}
+	return fmt.Sprintf("%v %v %v", rule.Action, errno, rule.Args)
+}
+
+// canonicalProfile returns the profile with the same meaning, written the same way however it was written:
+// sorted architectures, sorted conditions without duplicates, rules with the same action and conditions
+// merged, names already allowed by an unconditional rule with the same action left out of conditional ones,
+// and the rules sorted by action, unconditional first, then by name
+func canonicalProfile(profile specs.LinuxSeccomp) specs.LinuxSeccomp {
+	sort.Slice(profile.Architectures, func(i, j int) bool { return profile.Architectures[i] < profile.Architectures[j] })
+	var archs []specs.Arch
+	for i, arch := range profile.Architectures {
+		if i == 0 || arch != profile.Architectures[i-1] {
+			archs = append(archs, arch)
+		}
+	}
+	profile.Architectures = archs
+
+	merged := make(map[string]*specs.LinuxSyscall)
+	var keys []string
+	for _, rule := range profile.Syscalls {
+		sort.Slice(rule.Args, func(i, j int) bool {
+			a, b := rule.Args[i], rule.Args[j]
+			if a.Index != b.Index {
+				return a.Index < b.Index
+			}
+			if a.Op != b.Op {
+				return a.Op < b.Op
+			}
+			if a.Value != b.Value {
+				return a.Value < b.Value
+			}
+			return a.ValueTwo < b.ValueTwo
+		})
+		var args []specs.LinuxSeccompArg
+		for i, arg := range rule.Args {
+			if i == 0 || arg != rule.Args[i-1] {
+				args = append(args, arg)
+			}
+		}
+		rule.Args = args
+
+		key := ruleKey(rule)
+		if m, ok := merged[key]; ok {
+			m.Names = append(m.Names, rule.Names...)
+			continue
+		}
+		m := rule
+		m.Names = append([]string(nil), rule.Names...)
+		merged[key] = &m
+		keys = append(keys, key)
+	}
+
+	// names every call of which an unconditional rule already matches, by action and errno
+	unconditional := make(map[string]bool)
+	for _, key := range keys {
+		if rule := merged[key]; len(rule.Args) == 0 {
+			for _, name := range rule.Names {
+				unconditional[key+" "+name] = true
+			}
+		}
+	}
+
+	rules := make([]specs.LinuxSyscall, 0, len(keys))
+	for _, key := range keys {
+		rule := merged[key]
+		sort.Strings(rule.Names)
+		rule.Names = uniqueStrings(rule.Names)
+		if len(rule.Args) > 0 {
+			unconditionalKey := ruleKey(specs.LinuxSyscall{Action: rule.Action, ErrnoRet: rule.ErrnoRet})
+			var names []string
+			for _, name := range rule.Names {
+				if !unconditional[unconditionalKey+" "+name] {
+					names = append(names, name)
+				}
+			}
+			rule.Names = names
+		}
+		if len(rule.Names) > 0 {
+			rules = append(rules, *rule)
+		}
+	}
+	sort.SliceStable(rules, func(i, j int) bool {
+		a, b := rules[i], rules[j]
+		if a.Action != b.Action {
+			return a.Action < b.Action
+		}
+		if (len(a.Args) == 0) != (len(b.Args) == 0) {
+			return len(a.Args) == 0
+		}
+		if a.Names[0] != b.Names[0] {
+			return a.Names[0] < b.Names[0]
+		}
+		return ruleKey(a) < ruleKey(b)
+	})
+	profile.Syscalls = rules
+	return profile
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"gopkg.in/yaml.v2"
+)
+
+// formatter writes the profile in an output format, selected with -format by its name. The formats register
+// themselves from init() with registerFormatter, so adding one doesn't touch the analysis.
+type formatter interface {
+	Name() string
+	// extension of the files it writes, when they're named after the profile
+	Extension() string
+	// Write writes the profile to w, profilePath is where it's being written to
+	Write(w io.Writer, profile specs.LinuxSeccomp, profilePath string) error
+}
+
+// the registered formatters, by name
+var formatters = map[string]formatter{}
+
+func registerFormatter(f formatter) {
+	if _, ok := formatters[f.Name()]; ok {
+		panic("format " + f.Name() + " registered twice")
+	}
+	formatters[f.Name()] = f
+}
+
+// formatFunc is a formatter writing the profile with a function
+type formatFunc struct {
+	name, extension string
+	write           func(w io.Writer, profile specs.LinuxSeccomp, profilePath string) error
+}
+
+func (f formatFunc) Name() string      { return f.name }
+func (f formatFunc) Extension() string { return f.extension }
+func (f formatFunc) Write(w io.Writer, profile specs.LinuxSeccomp, profilePath string) error {
+	return f.write(w, profile, profilePath)
+}
+
+func init() {
+	registerFormatter(formatFunc{"oci", ".json", writeOCIProfile})
+	registerFormatter(formatFunc{"k8s-installer", ".yaml", writeK8sInstaller})
+	registerFormatter(formatFunc{"k8s-annotations", ".yaml", writeK8sAnnotations})
+}
+
+var outDir = flag.String("out-dir", "", "directory to write the profiles to, named after the profile path or the binary")
+
+// formats referring to the oci profile, which is written along with them
+var formatsWithProfile = map[string]bool{
+	"compose": true,
+	"quadlet": true,
+}
+
+// selectedFormats returns the formats selected with -format, a comma separated list, with oci first when
+// a format refers to it
+func selectedFormats() []string {
+	var selected []string
+	oci, withProfile := false, false
+	for _, name := range strings.Split(*format, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			selected = append(selected, name)
+			oci = oci || name == "oci"
+			withProfile = withProfile || formatsWithProfile[name]
+		}
+	}
+	if withProfile && !oci {
+		selected = append([]string{"oci"}, selected...)
+	}
+	return selected
+}
+
+// formatOutputPath returns where the profile in the format is written. A single format is written to
+// the profile path, several ones next to each other, named after it: profile.json for oci, and
+// profile.<format><extension> for the rest.
+func formatOutputPath(profilePath, format string, count int) string {
+	dir := filepath.Dir(profilePath)
+	if *outDir != "" {
+		dir = *outDir
+	}
+	base := filepath.Base(profilePath)
+	if count == 1 {
+		return filepath.Join(dir, base)
+	}
+
+	name := strings.TrimSuffix(base, filepath.Ext(base))
+	extension := formatters[format].Extension()
+	if format == "oci" {
+		return filepath.Join(dir, name+extension)
+	}
+	return filepath.Join(dir, name+"."+format+extension)
+}
+
+func formatNames() string {
+	names := make([]string, 0, len(formatters))
+	for name := range formatters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// go2seccomp metadata stored in the x-go2seccomp field of OCI profiles, which runtimes ignore
+type profileMetadata struct {
+	MinKernel string `json:"minKernel"`
+}
+
+type ociProfile struct {
+	specs.LinuxSeccomp
+	Metadata profileMetadata `json:"x-go2seccomp"`
+}
+
+// writeOCIProfile writes the profile as the runtime-spec JSON docker, podman and the kubelet take
+func writeOCIProfile(w io.Writer, profile specs.LinuxSeccomp, profilePath string) error {
+	enc := newProfileEncoder(w)
+	oci := ociProfile{profile, profileMetadata{MinKernel: profileMinKernel(profile).String()}}
+	if *annotate && provenances != nil {
+		return enc.Encode(annotatedOCIProfile{oci, annotateRules(profile)})
+	}
+	return enc.Encode(oci)
+}
+
+var installerNamespace = flag.String("installer-namespace", "kube-system", "namespace of the k8s-installer ConfigMap and DaemonSet")
+var installerImage = flag.String("installer-image", "busybox:stable", "image the k8s-installer DaemonSet uses to copy the profile to the nodes")
+var kubeletSeccompRoot = flag.String("kubelet-seccomp-root", "/var/lib/kubelet/seccomp", "seccomp profile directory of the kubelet on the nodes")
+
+// writeK8sInstaller writes a ConfigMap with the profile and a DaemonSet that installs it in the seccomp
+// directory of every node, under go2seccomp/<profile file name>, where Localhost profiles can reference it
+func writeK8sInstaller(w io.Writer, profile specs.LinuxSeccomp, profilePath string) error {
+	var profileJSON bytes.Buffer
+	if err := writeOCIProfile(&profileJSON, profile, profilePath); err != nil {
+		return err
+	}
+
+	base := filepath.Base(profilePath)
+	name := strings.TrimSuffix(base, filepath.Ext(base))
+	file := name + ".json"
+	resourceName := "go2seccomp-" + strings.ToLower(strings.Replace(name, "_", "-", -1))
+	labels := yaml.MapSlice{{Key: "app.kubernetes.io/name", Value: resourceName}}
+	metadata := yaml.MapSlice{
+		{Key: "name", Value: resourceName},
+		{Key: "namespace", Value: *installerNamespace},
+		{Key: "labels", Value: labels},
+	}
+
+	configMap := yaml.MapSlice{
+		{Key: "apiVersion", Value: "v1"},
+		{Key: "kind", Value: "ConfigMap"},
+		{Key: "metadata", Value: metadata},
+		{Key: "data", Value: yaml.MapSlice{{Key: file, Value: profileJSON.String()}}},
+	}
+
+	// copy to a temporary file and rename so the kubelet never sees a partially written profile,
+	// then stay around so the DaemonSet pods don't restart
+	install := "mkdir -p /host/go2seccomp && cp /profiles/" + file + " /host/go2seccomp/." + file +
+		" && mv /host/go2seccomp/." + file + " /host/go2seccomp/" + file
+	podSpec := yaml.MapSlice{
+		{Key: "initContainers", Value: []interface{}{yaml.MapSlice{
+			{Key: "name", Value: "install"},
+			{Key: "image", Value: *installerImage},
+			{Key: "command", Value: []string{"sh", "-c", install}},
+			{Key: "volumeMounts", Value: []interface{}{
+				yaml.MapSlice{{Key: "name", Value: "profiles"}, {Key: "mountPath", Value: "/profiles"}, {Key: "readOnly", Value: true}},
+				yaml.MapSlice{{Key: "name", Value: "seccomp"}, {Key: "mountPath", Value: "/host"}},
+			}},
+		}}},
+		{Key: "containers", Value: []interface{}{yaml.MapSlice{
+			{Key: "name", Value: "pause"},
+			{Key: "image", Value: *installerImage},
+			{Key: "command", Value: []string{"sh", "-c", "trap exit TERM; while true; do sleep 3600 & wait; done"}},
+			{Key: "resources", Value: yaml.MapSlice{{Key: "requests", Value: yaml.MapSlice{{Key: "cpu", Value: "1m"}, {Key: "memory", Value: "8Mi"}}}}},
+		}}},
+		{Key: "tolerations", Value: []interface{}{yaml.MapSlice{{Key: "operator", Value: "Exists"}}}},
+		{Key: "volumes", Value: []interface{}{
+			yaml.MapSlice{{Key: "name", Value: "profiles"}, {Key: "configMap", Value: yaml.MapSlice{{Key: "name", Value: resourceName}}}},
+			yaml.MapSlice{{Key: "name", Value: "seccomp"}, {Key: "hostPath", Value: yaml.MapSlice{
+				{Key: "path", Value: *kubeletSeccompRoot},
+				{Key: "type", Value: "DirectoryOrCreate"},
+			}}},
+		}},
+	}
+
+	daemonSet := yaml.MapSlice{
+		{Key: "apiVersion", Value: "apps/v1"},
+		{Key: "kind", Value: "DaemonSet"},
+		{Key: "metadata", Value: metadata},
+		{Key: "spec", Value: yaml.MapSlice{
+			{Key: "selector", Value: yaml.MapSlice{{Key: "matchLabels", Value: labels}}},
+			{Key: "template", Value: yaml.MapSlice{
+				{Key: "metadata", Value: yaml.MapSlice{
+					{Key: "labels", Value: labels},
+					// changing the profile changes the template, rolling out the new version
+					{Key: "annotations", Value: yaml.MapSlice{{Key: "go2seccomp.io/profile-sha256", Value: sha256Hex(profileJSON.Bytes())}}},
+				}},
+				{Key: "spec", Value: podSpec},
+			}},
+		}},
+	}
+
+	enc := yaml.NewEncoder(w)
+	if err := enc.Encode(configMap); err != nil {
+		return err
+	}
+	if err := enc.Encode(daemonSet); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+var annotationContainer = flag.String("annotation-container", "", "container the k8s-annotations apply to (default the whole pod)")
+var profileArtifact = flag.String("profile-artifact", "", "OCI artifact with the profile, for the CRI-O annotation of k8s-annotations")
+
+// writeK8sAnnotations writes the pod annotations referencing the profile, installed by k8s-installer,
+// for clusters configuring seccomp with annotations instead of the securityContext fields
+func writeK8sAnnotations(w io.Writer, profile specs.LinuxSeccomp, profilePath string) error {
+	base := filepath.Base(profilePath)
+	file := strings.TrimSuffix(base, filepath.Ext(base)) + ".json"
+
+	annotations := seccompAnnotations(nil, "go2seccomp/"+file, *annotationContainer, *profileArtifact)
+	metadata := yaml.MapSlice{{Key: "metadata", Value: yaml.MapSlice{{Key: "annotations", Value: annotations}}}}
+
+	enc := yaml.NewEncoder(w)
+	if err := enc.Encode(metadata); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// seccompAnnotations adds the annotations selecting the Localhost profile for a container, or the whole
+// pod if container is empty: the ones the kubelet used before the securityContext fields, and CRI-O's
+// one pulling the profile from an OCI artifact, if given
+func seccompAnnotations(annotations yaml.MapSlice, localhostProfile, container, artifact string) yaml.MapSlice {
+	if container == "" {
+		annotations = yamlSet(annotations, "localhost/"+localhostProfile, "seccomp.security.alpha.kubernetes.io/pod")
+		if artifact != "" {
+			annotations = yamlSet(annotations, artifact, crioSeccompAnnotation+"POD")
+		}
+		return annotations
+	}
+	annotations = yamlSet(annotations, "localhost/"+localhostProfile, "container.seccomp.security.alpha.kubernetes.io/"+container)
+	if artifact != "" {
+		annotations = yamlSet(annotations, artifact, crioSeccompAnnotation+container)
+	}
+	return annotations
+}
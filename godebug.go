@@ -0,0 +1,107 @@
+package main
+
+import (
+	"debug/buildinfo"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+var godebugHint = flag.String("godebug", "", "GODEBUG the workload runs with, e.g. asyncpreemptoff=1,netdns=cgo, so the profile covers the runtime paths it enables")
+
+// syscalls made by the runtime and standard library paths the GODEBUG settings enable, by setting=value
+var godebugSyscalls = map[string][]string{
+	// async preemption signals the threads to preempt with tgkill
+	"asyncpreemptoff=0": {"getpid", "tgkill"},
+	"madvdontneed=0":    {"madvise"},
+	"madvdontneed=1":    {"madvise"},
+	"harddecommit=1":    {"mmap", "mprotect"},
+	// the Go resolver reads /etc/resolv.conf and /etc/hosts and queries over UDP and TCP
+	"netdns=go": {"openat", "read", "fstat", "close", "socket", "connect", "getsockopt", "getsockname",
+		"getpeername", "setsockopt", "write", "epoll_ctl", "epoll_pwait"},
+	// the libc resolver, on top of the Go one's, as it runs through cgo
+	"netdns=cgo": {"openat", "read", "fstat", "close", "socket", "connect", "sendto", "sendmmsg", "recvfrom",
+		"poll", "ioctl", "bind", "getsockname", "setsockopt", "uname"},
+}
+
+// settings the runtime uses when neither the binary nor -godebug change them
+var godebugDefaults = map[string]string{
+	"asyncpreemptoff": "0",
+}
+
+// the GODEBUG defaults embedded in the analyzed binary by its go.mod and //go:debug directives
+var binaryGodebug string
+
+// readBinaryGodebug returns the DefaultGODEBUG setting recorded in the build info of the binary
+func readBinaryGodebug(path string) string {
+	info, err := buildinfo.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	for _, setting := range info.Settings {
+		if setting.Key == "DefaultGODEBUG" {
+			return setting.Value
+		}
+	}
+	return ""
+}
+
+// parseGodebug adds the settings of a GODEBUG value to settings
+func parseGodebug(value string, settings map[string]string) {
+	for _, pair := range strings.Split(value, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			fatalf("Invalid GODEBUG setting %v, use key=value", pair)
+		}
+		settings[kv[0]] = kv[1]
+	}
+}
+
+// godebugSettings returns the settings the workload runs with: the runtime defaults, overridden by the
+// ones of the binary, overridden by -godebug
+func godebugSettings() map[string]string {
+	settings := make(map[string]string)
+	for key, value := range godebugDefaults {
+		settings[key] = value
+	}
+	parseGodebug(binaryGodebug, settings)
+	parseGodebug(*godebugHint, settings)
+	return settings
+}
+
+// godebugNames returns the syscalls of the paths enabled by the GODEBUG settings that exist on the arch
+func godebugNames(arch specs.Arch) []string {
+	archNames := make(map[string]bool)
+	for _, name := range syscallIDtoName[arch] {
+		archNames[name] = true
+	}
+	var names []string
+	for key, value := range godebugSettings() {
+		for _, name := range godebugSyscalls[key+"="+value] {
+			if archNames[name] {
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func reportGodebug() {
+	if *godebugHint == "" && binaryGodebug == "" {
+		return
+	}
+	settings := godebugSettings()
+	pairs := make([]string, 0, len(settings))
+	for key, value := range settings {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	fmt.Printf("Covering GODEBUG %v\n", strings.Join(pairs, ","))
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	gofmt "go/format"
+	"io"
+	"path/filepath"
+	"text/template"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+func init() {
+	registerFormatter(formatFunc{"go-package", ".go", writeGoPackage})
+	registerFormatter(formatFunc{"go-libseccomp", ".go", writeGoPackage})
+}
+
+var goPackageName = flag.String("go-package-name", "seccompprofile", "package name of the go-package output")
+var goPackageInit = flag.Bool("go-package-init", false, "make the go-package output install the filter from init(), instead of calling Install()")
+
+// GOARCH of each seccomp arch, the generated package only builds for the arch of the profile
+var goarchBySeccompArch = map[specs.Arch]string{
+	specs.ArchX86_64:   "amd64",
+	specs.ArchX86:      "386",
+	specs.ArchARM:      "arm",
+	specs.ArchAARCH64:  "arm64",
+	specs.ArchPPC64LE:  "ppc64le",
+	specs.ArchMIPS:     "mips",
+	specs.ArchMIPSEL:   "mipsle",
+	specs.ArchMIPS64:   "mips64",
+	specs.ArchMIPSEL64: "mips64le",
+}
+
+// libseccomp-golang expressions of the runtime-spec actions and operators
+var libseccompActions = map[specs.LinuxSeccompAction]string{
+	specs.ActKill:        "seccomp.ActKillThread",
+	specs.ActKillThread:  "seccomp.ActKillThread",
+	specs.ActKillProcess: "seccomp.ActKillProcess",
+	specs.ActTrap:        "seccomp.ActTrap",
+	specs.ActErrno:       "seccomp.ActErrno",
+	specs.ActTrace:       "seccomp.ActTrace",
+	specs.ActAllow:       "seccomp.ActAllow",
+	specs.ActLog:         "seccomp.ActLog",
+	specs.ActNotify:      "seccomp.ActNotify",
+}
+
+var libseccompOperators = map[specs.LinuxSeccompOperator]string{
+	specs.OpNotEqual:     "seccomp.CompareNotEqual",
+	specs.OpLessThan:     "seccomp.CompareLess",
+	specs.OpLessEqual:    "seccomp.CompareLessOrEqual",
+	specs.OpEqualTo:      "seccomp.CompareEqual",
+	specs.OpGreaterEqual: "seccomp.CompareGreaterEqual",
+	specs.OpGreaterThan:  "seccomp.CompareGreater",
+	specs.OpMaskedEqual:  "seccomp.CompareMaskedEqual",
+}
+
+var goPackageTemplate = template.Must(template.New("go-package").Parse(`// Code generated by go2seccomp for {{.Binary}}. DO NOT EDIT.
+
+{{if .GOARCH}}//go:build linux && {{.GOARCH}}
+{{end}}
+// Package {{.Package}} applies the seccomp filter go2seccomp generated for this program to the
+// process itself, for programs that don't run in a container.
+package {{.Package}}
+
+import (
+	"fmt"
+
+	seccomp "github.com/seccomp/libseccomp-golang"
+)
+
+type rule struct {
+	names      []string
+	action     seccomp.ScmpAction
+	conditions []seccomp.ScmpCondition
+}
+
+// Install loads the seccomp filter, for every thread of the process. Syscalls it doesn't allow
+// fail (or are killed) according to the profile from then on.
+func Install() error {
+	filter, err := seccomp.NewFilter({{.DefaultAction}})
+	if err != nil {
+		return fmt.Errorf("seccomp: %v", err)
+	}
+	defer filter.Release()
+	if err := filter.SetTsync(true); err != nil {
+		return fmt.Errorf("seccomp: %v", err)
+	}
+
+	rules := []rule{
+{{- range .Rules}}
+		{
+			names:  []string{ {{- range $i, $n := .Names}}{{if $i}}, {{end}}{{printf "%q" $n}}{{end -}} },
+			action: {{.Action}},
+			{{- if .Conditions}}
+			conditions: []seccomp.ScmpCondition{
+			{{- range .Conditions}}
+				mustCondition({{.}}),
+			{{- end}}
+			},
+			{{- end}}
+		},
+{{- end}}
+	}
+
+	for _, r := range rules {
+		for _, name := range r.names {
+			call, err := seccomp.GetSyscallFromName(name)
+			if err != nil {
+				// syscalls unknown to this libseccomp version can't be made either
+				continue
+			}
+			if len(r.conditions) > 0 {
+				err = filter.AddRuleConditional(call, r.action, r.conditions)
+			} else {
+				err = filter.AddRule(call, r.action)
+			}
+			if err != nil {
+				return fmt.Errorf("seccomp: adding %v: %v", name, err)
+			}
+		}
+	}
+	return filter.Load()
+}
+
+func mustCondition(c seccomp.ScmpCondition, err error) seccomp.ScmpCondition {
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+{{if .Init}}
+func init() {
+	if err := Install(); err != nil {
+		panic(err)
+	}
+}
+{{end}}`))
+
+// writeGoPackage writes a Go source file with an Install function loading the profile as the seccomp
+// filter of the process, with libseccomp-golang
+func writeGoPackage(w io.Writer, profile specs.LinuxSeccomp, profilePath string) error {
+	type goRule struct {
+		Names      []string
+		Action     string
+		Conditions []string
+	}
+
+	action := func(a specs.LinuxSeccompAction, errnoRet *uint) (string, error) {
+		expr, ok := libseccompActions[a]
+		if !ok {
+			return "", fmt.Errorf("action %v isn't supported by the go-package format", a)
+		}
+		if a == specs.ActErrno {
+			// runtimes return EPERM unless the profile sets the errno
+			errno := uint(1)
+			if errnoRet != nil {
+				errno = *errnoRet
+			}
+			expr = fmt.Sprintf("%v.SetReturnCode(%v)", expr, errno)
+		}
+		return expr, nil
+	}
+
+	defaultAction, err := action(profile.DefaultAction, profile.DefaultErrnoRet)
+	if err != nil {
+		return err
+	}
+
+	var rules []goRule
+	for _, s := range profile.Syscalls {
+		a, err := action(s.Action, s.ErrnoRet)
+		if err != nil {
+			return err
+		}
+		r := goRule{Names: s.Names, Action: a}
+		for _, arg := range s.Args {
+			op, ok := libseccompOperators[arg.Op]
+			if !ok {
+				return fmt.Errorf("operator %v isn't supported by the go-package format", arg.Op)
+			}
+			condition := fmt.Sprintf("seccomp.MakeCondition(%v, %v, %#x)", arg.Index, op, arg.Value)
+			if arg.Op == specs.OpMaskedEqual {
+				condition = fmt.Sprintf("seccomp.MakeCondition(%v, %v, %#x, %#x)", arg.Index, op, arg.Value, arg.ValueTwo)
+			}
+			r.Conditions = append(r.Conditions, condition)
+		}
+		rules = append(rules, r)
+	}
+
+	goarch := ""
+	if len(profile.Architectures) == 1 {
+		goarch = goarchBySeccompArch[profile.Architectures[0]]
+	}
+
+	var buf bytes.Buffer
+	err = goPackageTemplate.Execute(&buf, map[string]interface{}{
+		"Binary":        filepath.Base(analyzedBinary),
+		"Package":       *goPackageName,
+		"GOARCH":        goarch,
+		"DefaultAction": defaultAction,
+		"Rules":         rules,
+		"Init":          *goPackageInit,
+	})
+	if err != nil {
+		return err
+	}
+
+	source, err := gofmt.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("generated invalid Go code: %v", err)
+	}
+	_, err = w.Write(source)
+	return err
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func init() {
+	commands["goreleaser"] = goreleaserCommand
+}
+
+// goreleaserArtifact is an entry of the artifacts.json goreleaser writes to its dist directory
+type goreleaserArtifact struct {
+	Name   string `json:"name"`
+	Path   string `json:"path"`
+	Goos   string `json:"goos"`
+	Goarch string `json:"goarch"`
+	Type   string `json:"type"`
+}
+
+// goreleaserCommand generates profiles for the linux binaries goreleaser built, either listed in
+// dist/artifacts.json or given as arguments (from a build post hook, e.g. go2seccomp goreleaser {{ .Path }})
+func goreleaserCommand(args []string) {
+	fs := flag.NewFlagSet("goreleaser", flag.ExitOnError)
+	dist := fs.String("dist", "dist", "goreleaser dist directory")
+	extraFiles := fs.Bool("extra-files", false, "print the release.extra_files configuration to upload the profiles with the release")
+	parseFlags(fs, args)
+
+	var artifacts []goreleaserArtifact
+	if fs.NArg() > 0 {
+		for _, path := range fs.Args() {
+			artifacts = append(artifacts, goreleaserArtifact{
+				Name: filepath.Base(path),
+				Path: path,
+				// goreleaser build paths end with the target, e.g. dist/app_linux_amd64_v1/app
+				Goos: "linux",
+				Type: "Binary",
+			})
+		}
+	} else {
+		data, err := ioutil.ReadFile(filepath.Join(*dist, "artifacts.json"))
+		if err != nil {
+			fatalf("Failed to read goreleaser artifacts: %v", err)
+		}
+		if err := json.Unmarshal(data, &artifacts); err != nil {
+			fatalf("Failed to parse goreleaser artifacts: %v", err)
+		}
+	}
+
+	generated := 0
+	for _, a := range artifacts {
+		if a.Type != "Binary" || a.Goos != "linux" {
+			continue
+		}
+		if a.Goarch != "" {
+			if _, ok := archByName(a.Goarch); !ok {
+				fmt.Printf("Skipping %v, %v is not supported\n", a.Path, a.Goarch)
+				continue
+			}
+		}
+
+		graph, arch := analyzeBinary(a.Path)
+		syscallsList := getSyscallList(graph.allSyscalls(), arch)
+
+		// profiles go next to the archives, named after the binary and its target
+		target := strings.TrimPrefix(filepath.Base(filepath.Dir(a.Path)), a.Name+"_")
+		if a.Goarch != "" {
+			target = a.Goos + "_" + a.Goarch
+		}
+		profilePath := filepath.Join(*dist, a.Name+"_"+target+".seccomp.json")
+		writeProfile(syscallsList, arch, profilePath)
+		generated++
+	}
+
+	if generated == 0 {
+		fmt.Println("No linux binaries found in the goreleaser artifacts")
+		os.Exit(1)
+	}
+
+	if *extraFiles {
+		fmt.Printf("\nAdd the profiles to the release with:\n\nrelease:\n  extra_files:\n    - glob: %v\n",
+			filepath.ToSlash(filepath.Join(*dist, "*.seccomp.json")))
+	}
+}
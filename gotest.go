@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	gofmt "go/format"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+func init() {
+	registerFormatter(formatFunc{"go-test", "_test.go", writeGoTest})
+}
+
+var goTestProfile = flag.String("go-test-profile", "", "path of the profile the go-test output checks, relative to its package, the profile path with a .json extension by default")
+var goTestTrace = flag.String("go-test-trace", "", "strace output or list of syscall names the go-test output checks the profile allows, instead of the detected syscalls")
+
+// the syscalls going in the profile after the review, which the go-test output checks by default
+var profileSyscalls []string
+
+// syscall names in strace output, "openat(AT_FDCWD, ...) = 3", with the pid with -f, or alone on their line
+var traceLineRegexp = regexp.MustCompile(`^(?:\[pid\s+\d+\]\s+|\d+\s+)?([a-z_][a-z0-9_]*)(?:\(|$)`)
+
+// readTraceSyscalls returns the sorted names of the syscalls in strace output or a list of names
+func readTraceSyscalls(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var names []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// strace prints the rest of interrupted syscalls on their own line, "<... read resumed>"
+		if m := traceLineRegexp.FindStringSubmatch(strings.TrimSpace(scanner.Text())); m != nil {
+			names = append(names, m[1])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+	return uniqueStrings(names), nil
+}
+
+var goTestTemplate = template.Must(template.New("go-test").Parse(`// Code generated by go2seccomp for {{.Binary}}. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+// the syscalls {{.Source}}
+var seccompProfileSyscalls = []string{
+{{- range .Syscalls}}
+	{{printf "%q" .}},
+{{- end}}
+}
+
+// TestSeccompProfile checks the seccomp profile still lets the program make its syscalls. It matches the rules of
+// the JSON profile by name, not with libseccomp: the most restrictive action of the rules without conditions wins,
+// and syscalls only allowed for some arguments are logged rather than checked.
+func TestSeccompProfile(t *testing.T) {
+	data, err := os.ReadFile({{printf "%q" .Profile}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var profile struct {
+		DefaultAction string ` + "`json:\"defaultAction\"`" + `
+		Syscalls      []struct {
+			Names  []string          ` + "`json:\"names\"`" + `
+			Action string            ` + "`json:\"action\"`" + `
+			Args   []json.RawMessage ` + "`json:\"args\"`" + `
+		} ` + "`json:\"syscalls\"`" + `
+	}
+	if err := json.Unmarshal(data, &profile); err != nil {
+		t.Fatal(err)
+	}
+
+	// the actions letting the syscall run, and the precedence of the actions when several rules match
+	running := map[string]bool{"SCMP_ACT_ALLOW": true, "SCMP_ACT_LOG": true, "SCMP_ACT_TRACE": true, "SCMP_ACT_NOTIFY": true}
+	precedence := map[string]int{
+		"SCMP_ACT_KILL_PROCESS": 0, "SCMP_ACT_KILL_THREAD": 1, "SCMP_ACT_KILL": 1, "SCMP_ACT_TRAP": 2,
+		"SCMP_ACT_ERRNO": 3, "SCMP_ACT_NOTIFY": 4, "SCMP_ACT_TRACE": 5, "SCMP_ACT_LOG": 6, "SCMP_ACT_ALLOW": 7,
+	}
+
+	for _, name := range seccompProfileSyscalls {
+		action, conditional := "", false
+		for _, rule := range profile.Syscalls {
+			for _, n := range rule.Names {
+				if n != name {
+					continue
+				}
+				if len(rule.Args) > 0 {
+					conditional = conditional || running[rule.Action]
+				} else if action == "" || precedence[rule.Action] < precedence[action] {
+					action = rule.Action
+				}
+			}
+		}
+		if action == "" {
+			action = profile.DefaultAction
+		}
+		switch {
+		case running[action]:
+		case conditional:
+			t.Logf("%v is only allowed for some arguments", name)
+		default:
+			t.Errorf("%v isn't allowed by the profile, it gets %v", name, action)
+		}
+	}
+}
+`))
+
+// writeGoTest writes a Go test checking the profile allows the syscalls of the program, the detected ones or
+// the ones of -go-test-trace, so that it fails once the profile and the program drift apart. The test matches the
+// rules itself, libseccomp can't evaluate a filter without loading it.
+func writeGoTest(w io.Writer, profile specs.LinuxSeccomp, profilePath string) error {
+	syscalls := profileSyscalls
+	source := fmt.Sprintf("go2seccomp detected in %v", filepath.Base(analyzedBinary))
+	if *goTestTrace != "" {
+		var err error
+		if syscalls, err = readTraceSyscalls(*goTestTrace); err != nil {
+			return err
+		}
+		source = fmt.Sprintf("%v made in %v", filepath.Base(analyzedBinary), filepath.Base(*goTestTrace))
+	}
+
+	path := *goTestProfile
+	if path == "" {
+		// seccomp_test.go checks seccomp.json
+		base := filepath.Base(profilePath)
+		path = strings.TrimSuffix(strings.TrimSuffix(base, filepath.Ext(base)), "_test") + ".json"
+	}
+
+	var buf bytes.Buffer
+	err := goTestTemplate.Execute(&buf, map[string]interface{}{
+		"Binary":   filepath.Base(analyzedBinary),
+		"Package":  *goPackageName,
+		"Source":   source,
+		"Syscalls": syscalls,
+		"Profile":  path,
+	})
+	if err != nil {
+		return err
+	}
+
+	code, err := gofmt.Source(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(code)
+	return err
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+func init() {
+	commands["check-gvisor"] = checkGvisorCommand
+}
+
+// syscalls gVisor's Sentry doesn't implement, and what they return under runsc instead, from its amd64 and arm64
+// syscall tables. The ones needing a capability fail with EPERM without it, and ENOSYS with it.
+// reference: https://gvisor.dev/docs/user_guide/compatibility/linux/amd64/
+var gvisorUnsupported = map[string]string{
+	"uselib":                  "ENOSYS, obsolete",
+	"ustat":                   "ENOSYS, needs filesystem support",
+	"sysfs":                   "ENOSYS",
+	"_sysctl":                 "EPERM, use /proc/sys instead",
+	"modify_ldt":              "EPERM",
+	"pivot_root":              "EPERM",
+	"vhangup":                 "EPERM, needs CAP_SYS_TTY_CONFIG",
+	"adjtimex":                "EPERM, needs CAP_SYS_TIME",
+	"settimeofday":            "EPERM, needs CAP_SYS_TIME",
+	"clock_adjtime":           "EPERM, needs CAP_SYS_TIME",
+	"acct":                    "EPERM, needs CAP_SYS_PACCT",
+	"swapon":                  "EPERM, needs CAP_SYS_ADMIN",
+	"swapoff":                 "EPERM, needs CAP_SYS_ADMIN",
+	"reboot":                  "EPERM, needs CAP_SYS_BOOT",
+	"kexec_load":              "EPERM, needs CAP_SYS_BOOT",
+	"kexec_file_load":         "EPERM, needs CAP_SYS_BOOT",
+	"iopl":                    "EPERM, needs CAP_SYS_RAWIO",
+	"ioperm":                  "EPERM, needs CAP_SYS_RAWIO",
+	"create_module":           "EPERM, needs CAP_SYS_MODULE",
+	"init_module":             "EPERM, needs CAP_SYS_MODULE",
+	"finit_module":            "EPERM, needs CAP_SYS_MODULE",
+	"delete_module":           "EPERM, needs CAP_SYS_MODULE",
+	"get_kernel_syms":         "ENOSYS, removed from Linux",
+	"query_module":            "ENOSYS, removed from Linux",
+	"quotactl":                "EPERM, needs CAP_SYS_ADMIN",
+	"quotactl_fd":             "ENOSYS",
+	"nfsservctl":              "ENOSYS, removed from Linux",
+	"getpmsg":                 "ENOSYS",
+	"putpmsg":                 "ENOSYS",
+	"afs_syscall":             "ENOSYS",
+	"tuxcall":                 "ENOSYS",
+	"security":                "ENOSYS",
+	"lookup_dcookie":          "EPERM, needs CAP_SYS_ADMIN",
+	"epoll_ctl_old":           "ENOSYS",
+	"epoll_wait_old":          "ENOSYS",
+	"remap_file_pages":        "ENOSYS, deprecated",
+	"vserver":                 "ENOSYS",
+	"add_key":                 "EACCES, no keyrings",
+	"request_key":             "EACCES, no keyrings",
+	"migrate_pages":           "EPERM, needs CAP_SYS_NICE",
+	"move_pages":              "EPERM, needs CAP_SYS_NICE",
+	"perf_event_open":         "ENODEV, no perf events",
+	"fanotify_init":           "ENOSYS",
+	"fanotify_mark":           "ENOSYS",
+	"name_to_handle_at":       "EOPNOTSUPP",
+	"open_by_handle_at":       "EOPNOTSUPP",
+	"kcmp":                    "ENOSYS",
+	"sched_setattr":           "ENOSYS",
+	"sched_getattr":           "ENOSYS",
+	"bpf":                     "EPERM, needs CAP_SYS_ADMIN",
+	"userfaultfd":             "ENOSYS",
+	"pkey_mprotect":           "ENOSYS",
+	"pkey_alloc":              "ENOSYS",
+	"pkey_free":               "ENOSYS",
+	"io_pgetevents":           "ENOSYS",
+	"io_uring_register":       "ENOSYS",
+	"open_tree":               "ENOSYS",
+	"move_mount":              "ENOSYS",
+	"fsopen":                  "ENOSYS",
+	"fsconfig":                "ENOSYS",
+	"fsmount":                 "ENOSYS",
+	"fspick":                  "ENOSYS",
+	"clone3":                  "ENOSYS, libcs fall back to clone",
+	"process_madvise":         "ENOSYS",
+	"mount_setattr":           "ENOSYS",
+	"landlock_create_ruleset": "ENOSYS",
+	"landlock_add_rule":       "ENOSYS",
+	"landlock_restrict_self":  "ENOSYS",
+	"memfd_secret":            "ENOSYS",
+	"process_mrelease":        "ENOSYS",
+	"futex_waitv":             "ENOSYS",
+	"set_mempolicy_home_node": "ENOSYS",
+}
+
+// syscalls gVisor implements in part, and what it leaves out
+var gvisorPartial = map[string]string{
+	"personality":    "the personality can't be changed",
+	"mbind":          "the policy is stored but has no effect",
+	"vmsplice":       "copies the pages instead of mapping them",
+	"sched_setparam": "only the priority of the current policy is accepted",
+	"io_uring_setup": "only with runsc --iouring",
+	"io_uring_enter": "only with runsc --iouring",
+	"ioctl":          "only the requests of the files and devices gVisor emulates",
+	"prctl":          "not every option is supported",
+	"fcntl":          "not every command is supported",
+	"madvise":        "not every advice is supported",
+	"setsockopt":     "not every option is supported",
+	"getsockopt":     "not every option is supported",
+	"mount":          "only the filesystems gVisor implements",
+	"ptrace":         "not every request is supported",
+}
+
+// checkGvisorCommand reports the syscalls of a binary, or allowed by a profile, that gVisor (runsc) doesn't
+// implement, or only in part, failing when there's any of the first ones
+func checkGvisorCommand(args []string) {
+	fs := flag.NewFlagSet("check-gvisor", flag.ExitOnError)
+	parseFlags(fs, args)
+
+	if fs.NArg() < 1 {
+		fmt.Println("Usage: go2seccomp check-gvisor /path/to/binary")
+		fmt.Println("       go2seccomp check-gvisor /path/to/profile.json")
+		os.Exit(1)
+	}
+
+	var names []string
+	path := fs.Arg(0)
+	if strings.HasSuffix(path, ".json") || isYAMLPath(path) {
+		names = allowedNames(loadProfile(path))
+	} else {
+		graph, arch := analyzeBinary(bazelWorkingPath(path))
+		names = getSyscallList(graph.allSyscalls(), arch)
+		fmt.Printf("Syscalls detected (total: %v): %v\n", len(names), names)
+	}
+	sort.Strings(names)
+
+	var unsupported, partial []string
+	for _, name := range names {
+		if reason, ok := gvisorUnsupported[name]; ok {
+			unsupported = append(unsupported, fmt.Sprintf("%v (%v)", name, reason))
+		}
+		if limit, ok := gvisorPartial[name]; ok {
+			partial = append(partial, fmt.Sprintf("%v (%v)", name, limit))
+		}
+	}
+
+	if len(partial) > 0 {
+		fmt.Println("Syscalls gVisor implements in part, check the program doesn't need the rest:")
+		for _, p := range partial {
+			fmt.Printf("    %v\n", p)
+		}
+	}
+	if len(unsupported) == 0 {
+		fmt.Println("OK")
+		return
+	}
+	fmt.Println("Syscalls gVisor doesn't implement, which fail under runsc:")
+	for _, u := range unsupported {
+		fmt.Printf("    %v\n", u)
+	}
+	os.Exit(1)
+}
@@ -1,26 +1,113 @@
 package main
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"debug/elf"
 	"encoding/json"
 	"fmt"
-	"log"
+	"io/ioutil"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"syscall"
 
 	"github.com/opencontainers/runtime-spec/specs-go"
 )
 
+// stringList is a flag that can be repeated or given a comma separated list
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*l = append(*l, v)
+		}
+	}
+	return nil
+}
+
+// fileSHA256 returns the hex encoded sha256 of the file contents
+func fileSHA256(path string) string {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		fatalf("Failed to read %v: %v", path, err)
+	}
+	return sha256Hex(data)
+}
+
+func sha256Hex(data []byte) string {
+	return fmt.Sprintf("%x", sha256.Sum256(data))
+}
+
+// writeFileAtomic writes the data to a temporary file in the same directory and renames it into place,
+// so readers never see a partially written file
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	return writeFileAtomicOwned(path, data, perm, -1, -1)
+}
+
+// writeFileAtomicOwned is writeFileAtomic giving the file to uid and gid, left unchanged when -1
+func writeFileAtomicOwned(path string, data []byte, perm os.FileMode, uid, gid int) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	if uid != -1 || gid != -1 {
+		if err := os.Chown(tmp.Name(), uid, gid); err != nil {
+			return err
+		}
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// lockFile takes an exclusive lock on the file, waiting for other go2seccomp runs holding it, and
+// returns the function releasing it
+func lockFile(path string) func() {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		fatalf("Failed to open lock file: %v", err)
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		fatalf("Failed to lock %v: %v", path, err)
+	}
+	return func() {
+		syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		f.Close()
+	}
+}
+
 func openElf(filename string) *elf.File {
 	bin, err := os.OpenFile(filename, os.O_RDONLY, 0)
 	if err != nil {
-		log.Fatalln("can't open file", err)
+		fatalln("can't open file", err)
 	}
 
 	f, err := elf.NewFile(bin)
 	if err != nil {
-		log.Fatalln("elf read error", err)
+		fatalln("elf read error", err)
 	}
 
 	return f
@@ -36,9 +123,26 @@ func isGoBinary(file *elf.File) bool {
 	if sect := file.Section(".note.go.buildid"); sect != nil {
 		return true
 	}
+
+	// external linking, which rules_go uses for cgo, can drop the build ID note but keeps these
+	for _, name := range []string{".go.buildinfo", ".gopclntab"} {
+		if file.Section(name) != nil {
+			return true
+		}
+	}
 	return false
 }
 
+// isGoELF tells if the file at path is an ELF Go binary, without exiting on errors like openElf
+func isGoELF(path string) bool {
+	f, err := elf.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	return isGoBinary(f)
+}
+
 // convert debug/elf based name to specs.Arch
 func getArch(file *elf.File) specs.Arch {
 	var arch specs.Arch
@@ -50,55 +154,197 @@ func getArch(file *elf.File) specs.Arch {
 		arch = specs.ArchX86
 	case "EM_ARM":
 		arch = specs.ArchARM
+	case "EM_AARCH64":
+		arch = specs.ArchAARCH64
+	case "EM_PPC64":
+		if file.Data == elf.ELFDATA2MSB {
+			fatalf("Unsuported arch : %v (big endian)\n", file.Machine.String())
+		}
+		arch = specs.ArchPPC64LE
+	case "EM_MIPS":
+		// Go builds O32 for mips and mipsle, N64 for mips64 and mips64le
+		switch {
+		case file.Class == elf.ELFCLASS64 && file.Data == elf.ELFDATA2MSB:
+			arch = specs.ArchMIPS64
+		case file.Class == elf.ELFCLASS64:
+			arch = specs.ArchMIPSEL64
+		case file.Data == elf.ELFDATA2MSB:
+			arch = specs.ArchMIPS
+		default:
+			arch = specs.ArchMIPSEL
+		}
 	default:
-		log.Fatalf("Unsuported arch : %v\n", file.Machine.String())
+		if file.Data == elf.ELFDATA2MSB {
+			fatalf("Unsuported arch : %v (big endian)\n", file.Machine.String())
+		}
+		fatalf("Unsuported arch : %v\n", file.Machine.String())
 	}
 
 	fmt.Println("Arch : ", arch)
 	return arch
 }
 
-// write the seccomp profile to the profilePath file given an architecture and a list of syscalls (name)
-func writeProfile(syscallsList []string, arch specs.Arch, profilePath string) {
+// archByName accepts GOARCH style names (amd64), kernel style names (x86_64) or the
+// seccomp names (SCMP_ARCH_X86_64) and returns the matching specs.Arch
+func archByName(name string) (specs.Arch, bool) {
+	switch strings.ToLower(name) {
+	case "amd64", "x86_64", "x86-64", strings.ToLower(string(specs.ArchX86_64)):
+		return specs.ArchX86_64, true
+	case "x32", strings.ToLower(string(specs.ArchX32)):
+		return specs.ArchX32, true
+	case "386", "x86", "i386", strings.ToLower(string(specs.ArchX86)):
+		return specs.ArchX86, true
+	case "arm", strings.ToLower(string(specs.ArchARM)):
+		return specs.ArchARM, true
+	case "arm64", "aarch64", strings.ToLower(string(specs.ArchAARCH64)):
+		return specs.ArchAARCH64, true
+	case "ppc64le", strings.ToLower(string(specs.ArchPPC64LE)):
+		return specs.ArchPPC64LE, true
+	case "mips", strings.ToLower(string(specs.ArchMIPS)):
+		return specs.ArchMIPS, true
+	case "mipsle", "mipsel", strings.ToLower(string(specs.ArchMIPSEL)):
+		return specs.ArchMIPSEL, true
+	case "mips64", strings.ToLower(string(specs.ArchMIPS64)):
+		return specs.ArchMIPS64, true
+	case "mips64le", "mipsel64", strings.ToLower(string(specs.ArchMIPSEL64)):
+		return specs.ArchMIPSEL64, true
+	}
+	return "", false
+}
+
+// loadSyscallTable reads a JSON file mapping arch names to syscall ID->name tables, e.g.
+// {"amd64": {"451": "cachestat"}}, and adds them to syscallIDtoName, overriding existing entries
+func loadSyscallTable(path string) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		fatalf("Failed to read syscall table: %v", err)
+	}
+
+	var table map[string]map[string]string
+	if err := json.Unmarshal(data, &table); err != nil {
+		fatalf("Failed to parse syscall table %v: %v", path, err)
+	}
+
+	for archName, names := range table {
+		arch, ok := archByName(archName)
+		if !ok {
+			fatalf("Unknown arch %v in syscall table %v", archName, path)
+		}
+		if syscallIDtoName[arch] == nil {
+			syscallIDtoName[arch] = make(map[int64]string)
+		}
+		for idStr, name := range names {
+			id, err := strconv.ParseInt(idStr, 0, 64)
+			if err != nil {
+				fatalf("Invalid syscall ID %q for %v in syscall table %v", idStr, archName, path)
+			}
+			if old, ok := syscallIDtoName[arch][id]; ok && old != name && verbose {
+				fmt.Printf("Syscall table overrides %v ID %v: %v -> %v\n", arch, id, old, name)
+			}
+			syscallIDtoName[arch][id] = name
+		}
+	}
+}
 
-	profile := specs.LinuxSeccomp{
+// buildProfile returns the seccomp profile allowing the syscalls in the list (names) for the architecture
+func buildProfile(syscallsList []string, arch specs.Arch) specs.LinuxSeccomp {
+	// sorted whatever the passes adding names did, so generating the profile again gives the same file
+	names := append([]string(nil), syscallsList...)
+	sort.Strings(names)
+	return applySpecFields(specs.LinuxSeccomp{
 		DefaultAction: specs.ActErrno,
-		Architectures: []specs.Arch{arch},
-		Syscalls: []specs.LinuxSyscall{
-			specs.LinuxSyscall{
-				Names:  syscallsList,
-				Action: specs.ActAllow,
-			},
-		},
+		Architectures: profileArchitectures(arch),
+		Syscalls:      styleRules(names),
+	})
+}
+
+// generatedProfile returns the profile for the syscalls, with the -actions, -trace, -forbid-exec,
+// -socket-families, -interactive and -include changes
+func generatedProfile(syscallsList []string, arch specs.Arch) specs.LinuxSeccomp {
+	return applyIncludes(applyDenied(applySocketFamilies(applyForbidExec(applyTrace(applySyscallActions(buildProfile(syscallsList, arch)))))))
+}
+
+// write the seccomp profile to the profilePath file given an architecture and a list of syscalls (name),
+// in each of the formats selected with -format
+func writeProfile(syscallsList []string, arch specs.Arch, profilePath string) {
+	selected := selectedFormats()
+	if profilePath == stdoutPath {
+		if len(selected) > 1 || *outDir != "" {
+			fatalf("A single format can be written to stdout, without -out-dir")
+		}
+		if *attest || *writeLock || *sign || *signKey != "" || *lockProfiles {
+			fatalf("-attest, -write-lock, -sign and -lock need a profile file, not stdout")
+		}
+	}
+	if *outDir != "" {
+		if err := os.MkdirAll(*outDir, 0755); err != nil {
+			fatalf("Failed to create %v: %v", *outDir, err)
+		}
+	}
+	for _, format := range selected {
+		outputPath := formatOutputPath(profilePath, format, len(selected))
+		writeFormattedProfile(format, syscallsList, arch, profilePath, outputPath)
+		if *attest {
+			writeAttestation(outputPath, format)
+		}
+		if *writeLock {
+			writeProfileLock(outputPath, format, arch)
+		}
+	}
+	if *bpfReport {
+		reportBPF(generatedProfile(syscallsList, arch))
 	}
+}
 
-	profileFile, err := os.Create(profilePath)
-	if err != nil {
-		log.Fatalf("Failed to create seccomp profile: %v", err)
+func writeProfileFormat(format string, syscallsList []string, arch specs.Arch, profilePath string) {
+	writeFormattedProfile(format, syscallsList, arch, profilePath, profilePath)
+}
+
+// writeFormattedProfile writes the profile in the format to outputPath, profilePath is the path of the
+// profile the format refers to, which differs from outputPath when several formats are written
+func writeFormattedProfile(format string, syscallsList []string, arch specs.Arch, profilePath, outputPath string) {
+	profile := generatedProfile(syscallsList, arch)
+
+	var buf bytes.Buffer
+	if err := formatters[format].Write(&buf, profile, profilePath); err != nil {
+		fatalf("Failed to write seccomp profile: %v", err)
 	}
-	defer profileFile.Close()
 
-	enc := json.NewEncoder(profileFile)
-	enc.SetIndent("", "    ")
-	enc.Encode(profile)
-	fmt.Printf("Saved seccomp profile at %v\n", profilePath)
+	if outputPath == stdoutPath {
+		if _, err := profileStdout.Write(buf.Bytes()); err != nil {
+			fatalf("Failed to write seccomp profile: %v", err)
+		}
+		return
+	}
+
+	if *lockProfiles {
+		unlock := lockFile(outputPath + ".lock")
+		defer unlock()
+	}
+	// a crash or a concurrent run can't leave a truncated profile behind
+	writeProfileFile(outputPath, buf.Bytes())
+	fmt.Printf("Saved seccomp profile at %v\n", outputPath)
+
+	if *sign || *signKey != "" {
+		signProfile(outputPath)
+	}
 }
 
-// run go tool objdump (objdump for go)
-func disassamble(binaryPath string) *os.File {
+// run go tool objdump (objdump for go), with extra arguments like -s to only disassemble some functions
+func disassamble(binaryPath string, args ...string) *os.File {
 	disassambled, err := os.Create("disassembled.asm")
 
 	if err != nil {
-		log.Fatalf("Failed to disassembling output file, reason: %v", err)
+		fatalf("Failed to disassembling output file, reason: %v", err)
 	}
 
 	fmt.Printf("Using go tool objdump to disassemble %v\n", binaryPath)
-	cmd := exec.Command("go", "tool", "objdump", binaryPath)
+	cmd := exec.Command("go", append(append([]string{"tool", "objdump"}, args...), binaryPath)...)
 	cmd.Stdout = disassambled
 	err = cmd.Run()
 
 	if err != nil {
-		log.Fatalf("Couldn't run go tool objdump: %v\n", err)
+		fatalf("Couldn't run go tool objdump: %v\n", err)
 	}
 
 	// Point to the beginning of the disassembled binary to start looking for syscalls
@@ -110,12 +356,14 @@ func getCallOpByArch(arch specs.Arch) string {
 	var j string
 
 	switch arch {
-	case specs.ArchX86_64, specs.ArchX86:
+	case specs.ArchX86_64, specs.ArchX86, specs.ArchAARCH64, specs.ArchPPC64LE:
 		j = "CALL "
 	case specs.ArchARM:
 		j = "BL "
+	case specs.ArchMIPS, specs.ArchMIPSEL, specs.ArchMIPS64, specs.ArchMIPSEL64:
+		j = "JAL "
 	default:
-		log.Fatalln("Arch not suppported")
+		fatalln("Arch not suppported")
 	}
 
 	return j
@@ -123,7 +371,7 @@ func getCallOpByArch(arch specs.Arch) string {
 
 func parseFunctionName(instruction string) string {
 	texts := strings.Split(instruction, " ")
-	currentFunction := texts[1]
+	currentFunction := strings.TrimSuffix(texts[1], "(SB)")
 	if verbose {
 		fmt.Printf("Entering function %v\n", currentFunction)
 	}
@@ -131,30 +379,47 @@ func parseFunctionName(instruction string) string {
 }
 
 func isSyscallPkgCall(arch specs.Arch, instruction string) bool {
-	j := getCallOpByArch(arch)
-	return strings.Contains(instruction, j+"syscall.Syscall(SB)") || strings.Contains(instruction, j+"syscall.Syscall6(SB)") ||
-		strings.Contains(instruction, j+"syscall.RawSyscall(SB)") || strings.Contains(instruction, j+"syscall.RawSyscall6(SB)") ||
-		strings.Contains(instruction, j+"syscall.rawVforkSyscall(SB)")
+	return isGenericSyscallFunction(callTarget(arch, instruction))
 }
 
 func isRuntimeSyscall(arch specs.Arch, instruction, currentFunction string) bool {
-	// SYSCALL => x86_64, INT 0x80 => x86, SVC or SWI => ARM
+	// SYSCALL => x86_64, INT 0x80 => x86, SVC or SWI => ARM, SVC => arm64, SC => ppc64le, syscall => MIPS
 	var isRuntimeSC bool
 	switch arch {
 	case specs.ArchX86:
-		isRuntimeSC = (strings.Contains(instruction, "INT $0x80") || strings.Contains(instruction, "SYSENTER"))
+		isRuntimeSC = (strings.Contains(instruction, "INT $0x80") || strings.Contains(instruction, "SYSENTER")) &&
+			!isSyscallPkgFunction(currentFunction)
 	case specs.ArchX86_64:
 		// there are SYSCALL instructions in each of the 5 functions on the syscall package, so we ignore those
 		isRuntimeSC = strings.Contains(instruction, "SYSCALL") &&
 			!strings.Contains(currentFunction, "syscall.Syscall") &&
 			!strings.Contains(currentFunction, "syscall.RawSyscall") &&
-			!strings.Contains(currentFunction, "syscall.rawVforkSyscall")
+			!strings.Contains(currentFunction, "syscall.rawVforkSyscall") &&
+			!isGenericSyscallFunction(currentFunction)
 	case specs.ArchARM:
-		isRuntimeSC = strings.Contains(instruction, "SVC $0") || strings.Contains(instruction, "SWI $0")
+		isRuntimeSC = (strings.Contains(instruction, "SVC $0") || strings.Contains(instruction, "SWI $0")) &&
+			!isSyscallPkgFunction(currentFunction)
+	case specs.ArchAARCH64:
+		isRuntimeSC = strings.Contains(instruction, "SVC $0") && !isSyscallPkgFunction(currentFunction)
+	case specs.ArchPPC64LE:
+		isRuntimeSC = strings.Contains(instruction, "\tSC $0") && !isSyscallPkgFunction(currentFunction)
+	case specs.ArchMIPS, specs.ArchMIPSEL, specs.ArchMIPS64, specs.ArchMIPSEL64:
+		isRuntimeSC = strings.HasSuffix(instruction, "\tsyscall") && !isSyscallPkgFunction(currentFunction)
 	}
 	return isRuntimeSC
 }
 
+// isSyscallPkgFunction tells if the function is one of the syscall package functions that take the
+// syscall ID as an argument, whose callers are the ones to look at
+func isSyscallPkgFunction(currentFunction string) bool {
+	return strings.Contains(currentFunction, "syscall.Syscall") ||
+		strings.Contains(currentFunction, "syscall.RawSyscall") ||
+		strings.Contains(currentFunction, "syscall.rawSyscallNoError") ||
+		strings.Contains(currentFunction, "syscall.rawVforkSyscall") ||
+		strings.Contains(currentFunction, "syscall/linux.Syscall6") ||
+		isGenericSyscallFunction(currentFunction)
+}
+
 // Got these from https://github.com/moby/moby/issues/22252
 // Even if they are not found in the binary, they are needed for starting the container
 func getDefaultSyscalls(arch specs.Arch) map[int64]bool {
@@ -182,8 +447,36 @@ func getDefaultSyscalls(arch specs.Arch) map[int64]bool {
 		syscalls[106] = true
 		// execve
 		syscalls[11] = true
+	case specs.ArchAARCH64:
+		// futex
+		syscalls[98] = true
+		// newfstatat, arm64 has no stat
+		syscalls[79] = true
+		// execve
+		syscalls[221] = true
+	case specs.ArchPPC64LE:
+		// futex
+		syscalls[221] = true
+		// stat
+		syscalls[106] = true
+		// execve
+		syscalls[11] = true
+	case specs.ArchMIPS, specs.ArchMIPSEL:
+		// futex
+		syscalls[4238] = true
+		// stat
+		syscalls[4106] = true
+		// execve
+		syscalls[4011] = true
+	case specs.ArchMIPS64, specs.ArchMIPSEL64:
+		// futex
+		syscalls[5194] = true
+		// stat
+		syscalls[5004] = true
+		// execve
+		syscalls[5057] = true
 	default:
-		log.Fatalln(arch, "not supported")
+		fatalln(arch, "not supported")
 	}
 
 	return syscalls
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const defaultIgnoreFile = ".go2seccompignore"
+
+var ignoreFile = flag.String("ignore-file", defaultIgnoreFile, "file with patterns of functions and packages whose syscalls are ignored, one per line")
+
+// patterns of the functions and packages whose syscalls are left out
+var ignorePatterns []string
+
+// loadIgnoreFile reads the patterns of the ignore file, one per line with # starting comments. The default
+// file is optional.
+func loadIgnoreFile(path string) []string {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) && path == defaultIgnoreFile {
+		return nil
+	}
+	if err != nil {
+		fatalf("Failed to open ignore file: %v", err)
+	}
+	defer f.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i != -1 {
+			line = line[:i]
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			patterns = append(patterns, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		fatalf("Failed to read ignore file: %v", err)
+	}
+	return patterns
+}
+
+// ignored tells whether the function matches one of the patterns, as a function name or as a package
+// path matching all its functions
+func ignored(function string) bool {
+	for _, pattern := range ignorePatterns {
+		if globMatch(pattern, function) || globMatch(pattern+".*", function) {
+			return true
+		}
+	}
+	return false
+}
+
+// ignoreFunctions drops the syscalls and sites of the ignored functions. They're kept in the graph so
+// what they call is still reachable.
+func (g *callGraph) ignoreFunctions() {
+	if len(ignorePatterns) == 0 {
+		return
+	}
+	count := 0
+	for function, ids := range g.syscalls {
+		if len(ids) > 0 && ignored(function) {
+			g.syscalls[function] = make(map[int64]bool)
+			count++
+		}
+	}
+
+	var sites []syscallSite
+	for _, site := range g.sites {
+		if !ignored(site.Function) {
+			sites = append(sites, site)
+		}
+	}
+	g.sites = sites
+	var argSites []argSite
+	for _, site := range g.argSites {
+		if !ignored(site.Function) {
+			argSites = append(argSites, site)
+		}
+	}
+	g.argSites = argSites
+	var paths []pathConstant
+	for _, path := range g.paths {
+		if !ignored(path.Function) {
+			paths = append(paths, path)
+		}
+	}
+	g.paths = paths
+
+	if count > 0 {
+		fmt.Printf("Ignoring the syscalls of %v functions matching %v\n", count, *ignoreFile)
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"archive/tar"
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// extractGoBinaries saves the Go binaries found under prefix ("" for anywhere) in the filesystem of a
+// container image to dir and returns their paths. docker is used to pull the image and export its filesystem.
+func extractGoBinaries(image, dir, prefix string) ([]string, error) {
+	fmt.Printf("Exporting filesystem of image %v\n", image)
+
+	// images built from scratch may not have a command, and docker create refuses to create a
+	// container without one. The container is never started so any command will do.
+	out, err := exec.Command("docker", "create", image, "go2seccomp").Output()
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't create container from image %v: %v", image, err)
+	}
+	containerID := strings.TrimSpace(string(out))
+	defer exec.Command("docker", "rm", containerID).Run()
+
+	cmd := exec.Command("docker", "export", containerID)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't export image %v: %v", image, err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("Couldn't run docker export: %v", err)
+	}
+
+	binaries, err := extractGoBinariesFromTar(stdout, dir, prefix)
+	if err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return nil, err
+	}
+
+	if err := cmd.Wait(); err != nil {
+		return nil, fmt.Errorf("docker export of image %v failed: %v", image, err)
+	}
+	if len(binaries) == 0 {
+		return nil, fmt.Errorf("No Go binaries found in image %v", image)
+	}
+	return binaries, nil
+}
+
+// extractGoBinariesFromTar goes through a tar stream saving the executable Go binaries under prefix to dir
+func extractGoBinariesFromTar(r io.Reader, dir, prefix string) ([]string, error) {
+	var binaries []string
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read image filesystem: %v", err)
+		}
+		if hdr.Typeflag != tar.TypeReg || hdr.Mode&0111 == 0 {
+			continue
+		}
+
+		name := strings.TrimPrefix(path.Clean("/"+hdr.Name), "/")
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+
+		br := bufio.NewReader(tr)
+		magic, _ := br.Peek(4)
+		if string(magic) != "\x7fELF" {
+			continue
+		}
+
+		binary := filepath.Join(dir, strings.Replace(name, "/", "_", -1))
+		out, err := os.Create(binary)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to extract %v: %v", name, err)
+		}
+		_, err = io.Copy(out, br)
+		out.Close()
+		if err != nil {
+			return nil, fmt.Errorf("Failed to extract %v: %v", name, err)
+		}
+
+		if !isGoELF(binary) {
+			os.Remove(binary)
+			continue
+		}
+		fmt.Printf("Found Go binary /%v\n", name)
+		binaries = append(binaries, binary)
+	}
+
+	return binaries, nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"path/filepath"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+// fragments given with -include, merged into the generated profiles
+var includedFragments stringList
+
+// profiles can list fragments to merge into them, relative to the profile, e.g.
+// "include": ["fragments/logging.json", "fragments/dns.json"]
+type profileIncludes struct {
+	Include []string `json:"include"`
+}
+
+// resolveIncludes merges the fragments the profile, read from path, includes. including has the
+// profiles being resolved, to catch cycles.
+func resolveIncludes(profile specs.LinuxSeccomp, data []byte, path string, including map[string]bool) specs.LinuxSeccomp {
+	var includes profileIncludes
+	if err := json.Unmarshal(data, &includes); err != nil || len(includes.Include) == 0 {
+		return profile
+	}
+
+	abs, _ := filepath.Abs(path)
+	including[abs] = true
+	defer delete(including, abs)
+
+	for _, include := range includes.Include {
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(filepath.Dir(path), include)
+		}
+		if abs, _ := filepath.Abs(include); including[abs] {
+			fatalf("Include cycle: %v includes %v, which includes it back", path, include)
+		}
+		profile = mergeProfiles(profile, readProfile(include, including))
+	}
+	return profile
+}
+
+// mergeProfiles adds the rules and architectures of the fragment to the profile, which keeps its
+// default action unless it has none
+func mergeProfiles(profile, fragment specs.LinuxSeccomp) specs.LinuxSeccomp {
+	if profile.DefaultAction == "" {
+		profile.DefaultAction = fragment.DefaultAction
+		profile.DefaultErrnoRet = fragment.DefaultErrnoRet
+	}
+	for _, arch := range fragment.Architectures {
+		found := false
+		for _, a := range profile.Architectures {
+			found = found || a == arch
+		}
+		if !found {
+			profile.Architectures = append(profile.Architectures, arch)
+		}
+	}
+	profile.Syscalls = append(profile.Syscalls, fragment.Syscalls...)
+	return profile
+}
+
+// applyIncludes merges the -include fragments into a generated profile
+func applyIncludes(profile specs.LinuxSeccomp) specs.LinuxSeccomp {
+	for _, path := range includedFragments {
+		profile = mergeProfiles(profile, loadProfile(path))
+	}
+	return profile
+}
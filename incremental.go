@@ -0,0 +1,300 @@
+package main
+
+import (
+	"crypto/sha256"
+	"debug/buildinfo"
+	"debug/elf"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+var incremental = flag.Bool("incremental", false, "only disassemble and scan the functions changed since the previous analysis of the same program, reusing the cached results of the others")
+var cacheDir = flag.String("cache-dir", "", "directory of the -incremental cache, go2seccomp in the user cache directory by default")
+
+// past this length the objdump symbol regexp would go over the size of a command line argument
+const maxSymbolPatternLength = 100000
+
+// analysisCache is what the analysis of a program found in each of its functions, by function name
+type analysisCache struct {
+	// the options the functions were scanned with, changing them invalidates the cache
+	Options   string                     `json:"options"`
+	Functions map[string]*cachedFunction `json:"functions"`
+}
+
+// cachedFunction is what the analysis found in a function, with the addresses relative to its start
+type cachedFunction struct {
+	Hash       string           `json:"hash"`
+	Syscalls   []int64          `json:"syscalls,omitempty"`
+	References []string         `json:"references,omitempty"`
+	Sites      []cachedSite     `json:"sites,omitempty"`
+	ArgSites   []argSite        `json:"argSites,omitempty"`
+	Unresolved []unresolvedSite `json:"unresolved,omitempty"`
+	Paths      []string         `json:"paths,omitempty"`
+}
+
+// cachedSite is a syscall site with its ID, which reports leave out
+type cachedSite struct {
+	syscallSite
+	ID int64 `json:"id"`
+}
+
+// the address of the functions and the hash of their machine code, from the symbol table
+type functionSymbol struct {
+	address uint64
+	hash    string
+}
+
+// functionSymbols returns the functions of the binary, ones defined more than once are left out
+func functionSymbols(f *elf.File) map[string]functionSymbol {
+	symbols, err := f.Symbols()
+	if err != nil {
+		return nil
+	}
+	text := f.Section(".text")
+	if text == nil {
+		return nil
+	}
+	data, err := text.Data()
+	if err != nil {
+		return nil
+	}
+
+	functions := make(map[string]functionSymbol)
+	seen := make(map[string]bool)
+	for _, s := range symbols {
+		if elf.ST_TYPE(s.Info) != elf.STT_FUNC || s.Value < text.Addr || s.Value+s.Size > text.Addr+uint64(len(data)) {
+			continue
+		}
+		if seen[s.Name] {
+			delete(functions, s.Name)
+			continue
+		}
+		seen[s.Name] = true
+		code := data[s.Value-text.Addr : s.Value-text.Addr+s.Size]
+		functions[s.Name] = functionSymbol{s.Value, fmt.Sprintf("%x", sha256.Sum256(code))}
+	}
+	return functions
+}
+
+// analysisCachePath returns where the analysis of the binary is cached, named after its main package, which
+// stays the same across builds, or its path when it has no build info
+func analysisCachePath(binaryPath string, arch specs.Arch) string {
+	dir := *cacheDir
+	if dir == "" {
+		userCache, err := os.UserCacheDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(userCache, "go2seccomp")
+	}
+	key, _ := filepath.Abs(analyzedBinary)
+	if info, err := buildinfo.ReadFile(binaryPath); err == nil && info.Path != "" {
+		key = info.Path
+	}
+	return filepath.Join(dir, sha256Hex([]byte(key + " " + string(arch)))[:32]+".json")
+}
+
+// analysisOptions returns the options changing what the scan finds in a function, and the go2seccomp binary
+// itself, whose new versions can find more
+func analysisOptions() string {
+	options := []string{*minConfidence, strings.Join(extraSyscallPackages, ",")}
+	if *rulesPath != "" {
+		if data, err := ioutil.ReadFile(*rulesPath); err == nil {
+			options = append(options, sha256Hex(data))
+		}
+	}
+	if executable, err := os.Executable(); err == nil {
+		if data, err := ioutil.ReadFile(executable); err == nil {
+			options = append(options, sha256Hex(data))
+		}
+	}
+	return strings.Join(options, " ")
+}
+
+func loadAnalysisCache(path string) *analysisCache {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var cache analysisCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		fmt.Printf("Ignoring the unreadable analysis cache %v: %v\n", path, err)
+		return nil
+	}
+	return &cache
+}
+
+// scanIncrementally scans the functions of the binary changed since the cached analysis of the same program,
+// restores the results of the others from the cache, and caches the results of this analysis. Functions
+// are unchanged when their machine code is, so a change moving code around rescans the functions calling it.
+func scanIncrementally(binaryPath string, f *elf.File, arch specs.Arch) *callGraph {
+	symbols := functionSymbols(f)
+	path := analysisCachePath(binaryPath, arch)
+	if symbols == nil || path == "" {
+		fmt.Println("Can't analyze incrementally, analyzing the whole binary")
+		return scanBinary(binaryPath, f, arch)
+	}
+	options := analysisOptions()
+
+	cache := loadAnalysisCache(path)
+	if cache != nil && cache.Options != options {
+		fmt.Println("The options or go2seccomp changed since the cached analysis, analyzing the whole binary")
+		cache = nil
+	}
+
+	var changed, unchanged []string
+	for name, symbol := range symbols {
+		if cached, ok := cache.function(name); ok && cached.Hash == symbol.hash {
+			unchanged = append(unchanged, name)
+		} else {
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+
+	var graph *callGraph
+	pattern := symbolPattern(changed)
+	full := cache == nil || len(pattern) > maxSymbolPatternLength
+	switch {
+	case full:
+		graph = scanBinary(binaryPath, f, arch)
+	case len(changed) == 0:
+		fmt.Println("No function changed since the cached analysis")
+		graph = newCallGraph()
+	default:
+		fmt.Printf("%v of %v functions changed since the cached analysis\n", len(changed), len(symbols))
+		graph = scanBinary(binaryPath, f, arch, "-s", pattern)
+	}
+	if !full {
+		for _, name := range unchanged {
+			cache.Functions[name].restore(graph, name, symbols[name].address)
+		}
+	}
+
+	updated := &analysisCache{Options: options, Functions: make(map[string]*cachedFunction)}
+	for name, symbol := range symbols {
+		updated.Functions[name] = &cachedFunction{Hash: symbol.hash}
+	}
+	updated.record(graph, symbols)
+	data, err := json.Marshal(updated)
+	if err == nil {
+		os.MkdirAll(filepath.Dir(path), 0755)
+		err = writeFileAtomic(path, data, 0644)
+	}
+	if err != nil {
+		fmt.Printf("Failed to cache the analysis: %v\n", err)
+	}
+	return graph
+}
+
+// symbolPattern returns the go tool objdump -s regexp matching the functions
+func symbolPattern(functions []string) string {
+	quoted := make([]string, len(functions))
+	for i, function := range functions {
+		quoted[i] = regexp.QuoteMeta(function)
+	}
+	return "^(?:" + strings.Join(quoted, "|") + ")$"
+}
+
+func (c *analysisCache) function(name string) (*cachedFunction, bool) {
+	if c == nil {
+		return nil, false
+	}
+	cached, ok := c.Functions[name]
+	return cached, ok
+}
+
+// record caches what the graph has for each of the functions, their addresses made relative to them
+func (c *analysisCache) record(graph *callGraph, symbols map[string]functionSymbol) {
+	for name, ids := range graph.syscalls {
+		if cached, ok := c.Functions[name]; ok {
+			for id := range ids {
+				cached.Syscalls = append(cached.Syscalls, id)
+			}
+			for reference := range graph.references[name] {
+				cached.References = append(cached.References, reference)
+			}
+		}
+	}
+	for _, site := range graph.sites {
+		if cached, ok := c.Functions[site.Function]; ok {
+			site.Address = relativeAddress(site.Address, symbols[site.Function].address)
+			cached.Sites = append(cached.Sites, cachedSite{site, site.ID})
+		}
+	}
+	for _, site := range graph.argSites {
+		if cached, ok := c.Functions[site.Function]; ok {
+			site.Address = relativeAddress(site.Address, symbols[site.Function].address)
+			cached.ArgSites = append(cached.ArgSites, site)
+		}
+	}
+	for _, site := range graph.unresolved {
+		if cached, ok := c.Functions[site.Function]; ok {
+			site.Address = relativeAddress(site.Address, symbols[site.Function].address)
+			cached.Unresolved = append(cached.Unresolved, site)
+		}
+	}
+	for _, path := range graph.paths {
+		if cached, ok := c.Functions[path.Function]; ok {
+			cached.Paths = append(cached.Paths, path.Path)
+		}
+	}
+}
+
+// restore adds what the analysis found in the function to the graph, with its addresses moved to where the
+// function now is
+func (c *cachedFunction) restore(graph *callGraph, name string, address uint64) {
+	graph.addFunction(name)
+	for _, id := range c.Syscalls {
+		graph.syscalls[name][id] = true
+	}
+	for _, reference := range c.References {
+		graph.references[name][reference] = true
+	}
+	for _, cached := range c.Sites {
+		site := cached.syscallSite
+		site.ID = cached.ID
+		site.Address = absoluteAddress(site.Address, address)
+		graph.sites = append(graph.sites, site)
+	}
+	for _, site := range c.ArgSites {
+		site.Address = absoluteAddress(site.Address, address)
+		graph.argSites = append(graph.argSites, site)
+	}
+	for _, site := range c.Unresolved {
+		site.Address = absoluteAddress(site.Address, address)
+		graph.unresolved = append(graph.unresolved, site)
+	}
+	for _, path := range c.Paths {
+		graph.paths = append(graph.paths, pathConstant{name, path})
+	}
+}
+
+func relativeAddress(address string, start uint64) string {
+	addr, err := strconv.ParseUint(address, 0, 64)
+	if err != nil || addr < start {
+		return address
+	}
+	return fmt.Sprintf("+%#x", addr-start)
+}
+
+func absoluteAddress(offset string, start uint64) string {
+	if !strings.HasPrefix(offset, "+") {
+		return offset
+	}
+	off, err := strconv.ParseUint(offset[1:], 0, 64)
+	if err != nil {
+		return offset
+	}
+	return fmt.Sprintf("%#x", start+off)
+}
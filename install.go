@@ -0,0 +1,80 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+func init() {
+	commands["install"] = installCommand
+}
+
+// installCommand validates a profile and installs it in the seccomp directory of the kubelet
+// (or containerd/CRI-O), printing the localhostProfile to reference it with
+func installCommand(args []string) {
+	fs := flag.NewFlagSet("install", flag.ExitOnError)
+	dest := fs.String("dest", "/var/lib/kubelet/seccomp/go2seccomp", "directory to install the profile to")
+	root := fs.String("root", "/var/lib/kubelet/seccomp", "seccomp root directory localhostProfile paths are relative to")
+	name := fs.String("name", "", "file name of the installed profile (default the name of the profile file)")
+	fileMode := fs.String("file-mode", "0644", "permissions of the installed profile")
+	parseFlags(fs, args)
+
+	if fs.NArg() < 1 {
+		fmt.Println("Usage: go2seccomp install [-dest dir] [-name name.json] /path/to/profile.json")
+		os.Exit(1)
+	}
+	profilePath := fs.Arg(0)
+
+	perm, err := parseMode(*fileMode)
+	if err != nil {
+		fatalf("Invalid -file-mode: %v", err)
+	}
+
+	if errs := validateProfile(loadProfile(profilePath)); len(errs) > 0 {
+		for _, err := range errs {
+			fmt.Printf("%v: %v\n", profilePath, err)
+		}
+		fatalf("Not installing invalid profile %v", profilePath)
+	}
+
+	data, err := ioutil.ReadFile(profilePath)
+	if err != nil {
+		fatalf("Failed to read profile: %v", err)
+	}
+
+	if err := os.MkdirAll(*dest, 0755); err != nil {
+		fatalf("Failed to create %v: %v", *dest, err)
+	}
+	if *name == "" {
+		*name = filepath.Base(profilePath)
+	}
+	installed := filepath.Join(*dest, *name)
+	if err := writeFileAtomic(installed, data, perm); err != nil {
+		fatalf("Failed to install profile: %v", err)
+	}
+
+	// the profile is owned by whoever owns the directory, the kubelet runs as root but the
+	// directory may be managed by someone else
+	if info, err := os.Stat(*dest); err == nil {
+		if stat, ok := info.Sys().(*syscall.Stat_t); ok && os.Geteuid() == 0 {
+			if err := os.Chown(installed, int(stat.Uid), int(stat.Gid)); err != nil {
+				fatalf("Failed to set ownership of %v: %v", installed, err)
+			}
+		}
+	}
+	fmt.Printf("Installed profile at %v\n", installed)
+
+	absRoot, _ := filepath.Abs(*root)
+	absInstalled, _ := filepath.Abs(installed)
+	rel, err := filepath.Rel(absRoot, absInstalled)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		fmt.Printf("%v is outside of the seccomp root %v, reference it with its full path\n", installed, *root)
+		return
+	}
+	fmt.Printf("Reference it with:\n\nseccompProfile:\n  type: Localhost\n  localhostProfile: %v\n", filepath.ToSlash(rel))
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"flag"
+	"io"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+func init() {
+	registerFormatter(formatFunc{"jsonpatch", ".json", writeJSONPatch})
+}
+
+var jsonPatchPath = flag.String("jsonpatch-path", "/linux/seccomp", "JSON pointer the jsonpatch output adds the profile at")
+
+// jsonPatchOperation is an operation of an RFC 6902 JSON Patch
+type jsonPatchOperation struct {
+	Op    string             `json:"op"`
+	Path  string             `json:"path"`
+	Value specs.LinuxSeccomp `json:"value"`
+}
+
+// writeJSONPatch writes a JSON Patch adding the profile to a runtime config, the linux.seccomp of a config.json
+// by default. add replaces the profile the config already has.
+func writeJSONPatch(w io.Writer, profile specs.LinuxSeccomp, profilePath string) error {
+	return newProfileEncoder(w).Encode([]jsonPatchOperation{{Op: "add", Path: *jsonPatchPath, Value: profile}})
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+var kconfigPath = flag.String("kconfig", "", "kernel config (e.g. /boot/config-$(uname -r) or /proc/config.gz) to check the detected syscalls against")
+var kconfigDrop = flag.Bool("kconfig-drop", false, "leave out of the profile the syscalls disabled by the -kconfig kernel, relying on the program's fallbacks")
+
+// kernel config option backing each syscall, which returns ENOSYS without it
+var kconfigSyscalls = map[string][]string{
+	"CONFIG_IO_URING":           {"io_uring_setup", "io_uring_enter", "io_uring_register"},
+	"CONFIG_AIO":                {"io_setup", "io_destroy", "io_submit", "io_cancel", "io_getevents", "io_pgetevents", "io_pgetevents_time64"},
+	"CONFIG_BPF_SYSCALL":        {"bpf"},
+	"CONFIG_USERFAULTFD":        {"userfaultfd"},
+	"CONFIG_PERF_EVENTS":        {"perf_event_open"},
+	"CONFIG_FANOTIFY":           {"fanotify_init", "fanotify_mark"},
+	"CONFIG_INOTIFY_USER":       {"inotify_init", "inotify_init1", "inotify_add_watch", "inotify_rm_watch"},
+	"CONFIG_EPOLL":              {"epoll_create", "epoll_create1", "epoll_ctl", "epoll_wait", "epoll_pwait", "epoll_pwait2"},
+	"CONFIG_EVENTFD":            {"eventfd", "eventfd2"},
+	"CONFIG_SIGNALFD":           {"signalfd", "signalfd4"},
+	"CONFIG_TIMERFD":            {"timerfd_create", "timerfd_settime", "timerfd_gettime", "timerfd_settime64", "timerfd_gettime64"},
+	"CONFIG_MEMFD_CREATE":       {"memfd_create"},
+	"CONFIG_SECRETMEM":          {"memfd_secret"},
+	"CONFIG_POSIX_MQUEUE":       {"mq_open", "mq_unlink", "mq_timedsend", "mq_timedreceive", "mq_notify", "mq_getsetattr", "mq_timedsend_time64", "mq_timedreceive_time64"},
+	"CONFIG_SYSVIPC":            {"msgget", "msgsnd", "msgrcv", "msgctl", "semget", "semop", "semctl", "semtimedop", "semtimedop_time64", "shmget", "shmat", "shmdt", "shmctl", "ipc"},
+	"CONFIG_KEYS":               {"add_key", "request_key", "keyctl"},
+	"CONFIG_FHANDLE":            {"name_to_handle_at", "open_by_handle_at"},
+	"CONFIG_ADVISE_SYSCALLS":    {"madvise", "process_madvise", "fadvise64", "fadvise64_64", "arm_fadvise64_64"},
+	"CONFIG_SECURITY_LANDLOCK":  {"landlock_create_ruleset", "landlock_add_rule", "landlock_restrict_self"},
+	"CONFIG_MEMBARRIER":         {"membarrier"},
+	"CONFIG_RSEQ":               {"rseq"},
+	"CONFIG_NUMA":               {"mbind", "set_mempolicy", "get_mempolicy", "migrate_pages", "move_pages", "set_mempolicy_home_node"},
+	"CONFIG_SWAP":               {"swapon", "swapoff"},
+	"CONFIG_KEXEC":              {"kexec_load"},
+	"CONFIG_KEXEC_FILE":         {"kexec_file_load"},
+	"CONFIG_BSD_PROCESS_ACCT":   {"acct"},
+	"CONFIG_QUOTACTL":           {"quotactl", "quotactl_fd"},
+	"CONFIG_USELIB":             {"uselib"},
+	"CONFIG_SYSFS_SYSCALL":      {"sysfs"},
+	"CONFIG_CHECKPOINT_RESTORE": {"kcmp"},
+}
+
+// kernel config options changing what syscalls do rather than removing them, they fail with EINVAL or EPERM.
+// clone is left out, the Go runtime uses it for every thread.
+var kconfigFeatures = map[string]struct {
+	feature  string
+	syscalls []string
+}{
+	"CONFIG_USER_NS": {"user namespaces", []string{"unshare", "setns"}},
+	"CONFIG_PID_NS":  {"PID namespaces", []string{"unshare", "setns"}},
+	"CONFIG_NET_NS":  {"network namespaces", []string{"unshare", "setns"}},
+	"CONFIG_SECCOMP": {"seccomp filters", []string{"seccomp"}},
+}
+
+// the options enabled in the -kconfig kernel, loaded once in main
+var kernelConfig map[string]bool
+
+// the -kconfig warnings already printed, subcommands get the syscall list of each of their binaries
+var kernelConfigWarned = make(map[string]bool)
+
+// loadKernelConfig returns the options enabled, built in or as modules, in a kernel config
+func loadKernelConfig(path string) map[string]bool {
+	f, err := os.Open(path)
+	if err != nil {
+		fatalf("Failed to read kernel config %v: %v", path, err)
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			fatalf("Failed to read kernel config %v: %v", path, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	enabled := make(map[string]bool)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		parts := strings.SplitN(scanner.Text(), "=", 2)
+		if len(parts) == 2 && strings.HasPrefix(parts[0], "CONFIG_") && (parts[1] == "y" || parts[1] == "m") {
+			enabled[parts[0]] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		fatalf("Failed to read kernel config %v: %v", path, err)
+	}
+	return enabled
+}
+
+// applyKernelConfig warns about the syscalls in the list backed by features the -kconfig kernel has
+// disabled, and with -kconfig-drop leaves the ones failing with ENOSYS out
+func applyKernelConfig(syscallsList []string) []string {
+	if kernelConfig == nil {
+		return syscallsList
+	}
+	enabled := kernelConfig
+	warn := func(format string, args ...interface{}) {
+		if message := fmt.Sprintf(format, args...); !kernelConfigWarned[message] {
+			kernelConfigWarned[message] = true
+			warnf("%v", message)
+		}
+	}
+
+	inList := make(map[string]bool)
+	for _, name := range syscallsList {
+		inList[name] = true
+	}
+
+	disabled := make(map[string]string)
+	for option, names := range kconfigSyscalls {
+		if enabled[option] {
+			continue
+		}
+		for _, name := range names {
+			if inList[name] {
+				disabled[name] = option
+			}
+		}
+	}
+
+	var options []string
+	for option := range kconfigFeatures {
+		options = append(options, option)
+	}
+	sort.Strings(options)
+	for _, option := range options {
+		feature := kconfigFeatures[option]
+		if enabled[option] {
+			continue
+		}
+		var used []string
+		for _, name := range feature.syscalls {
+			if inList[name] {
+				used = append(used, name)
+			}
+		}
+		if len(used) > 0 {
+			warn("%v disables %v, %v may fail where the program relies on them", option, feature.feature, strings.Join(used, ", "))
+		}
+	}
+
+	if len(disabled) == 0 {
+		return syscallsList
+	}
+	var kept []string
+	for _, name := range syscallsList {
+		option, ok := disabled[name]
+		if !ok {
+			kept = append(kept, name)
+			continue
+		}
+		if *kconfigDrop {
+			fmt.Printf("Leaving out %v, disabled by %v\n", name, option)
+		} else {
+			warn("%v returns ENOSYS without %v, which %v disables", name, option, *kconfigPath)
+			kept = append(kept, name)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,298 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+func init() {
+	commands["check-kernel"] = checkKernelCommand
+}
+
+// kernelVersion is a major.minor kernel version
+type kernelVersion [2]int
+
+func (v kernelVersion) String() string {
+	return fmt.Sprintf("%v.%v", v[0], v[1])
+}
+
+func (v kernelVersion) less(o kernelVersion) bool {
+	return v[0] < o[0] || (v[0] == o[0] && v[1] < o[1])
+}
+
+// parseKernelVersion parses the major.minor of a kernel release, e.g. 5.15.0-91-generic
+func parseKernelVersion(release string) (kernelVersion, error) {
+	parts := strings.SplitN(strings.TrimSpace(release), ".", 3)
+	if len(parts) < 2 {
+		return kernelVersion{}, fmt.Errorf("invalid kernel version %q", release)
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return kernelVersion{}, fmt.Errorf("invalid kernel version %q", release)
+	}
+	// the minor may be followed by the rest of the release, e.g. 6.8-rc1
+	minor := parts[1]
+	if i := strings.IndexFunc(minor, func(r rune) bool { return r < '0' || r > '9' }); i != -1 {
+		minor = minor[:i]
+	}
+	m, err := strconv.Atoi(minor)
+	if err != nil {
+		return kernelVersion{}, fmt.Errorf("invalid kernel version %q", release)
+	}
+	return kernelVersion{major, m}, nil
+}
+
+// seccomp filtering (SECCOMP_MODE_FILTER) was added in 3.5
+var seccompFilterVersion = kernelVersion{3, 5}
+
+// kernel version each seccomp action was added in
+var actionVersions = map[specs.LinuxSeccompAction]kernelVersion{
+	specs.ActKill:        {3, 5},
+	specs.ActKillThread:  {3, 5},
+	specs.ActTrap:        {3, 5},
+	specs.ActErrno:       {3, 5},
+	specs.ActTrace:       {3, 5},
+	specs.ActAllow:       {3, 5},
+	specs.ActLog:         {4, 14},
+	specs.ActKillProcess: {4, 14},
+	specs.ActNotify:      {5, 0},
+}
+
+// kernel version each seccomp(2) flag was added in
+var flagVersions = map[specs.LinuxSeccompFlag]kernelVersion{
+	specs.LinuxSeccompFlagLog:              {4, 14},
+	specs.LinuxSeccompFlagSpecAllow:        {4, 17},
+	specs.LinuxSeccompFlagWaitKillableRecv: {5, 19},
+}
+
+// names of the actions in /proc/sys/kernel/seccomp/actions_avail
+var actionKernelNames = map[specs.LinuxSeccompAction]string{
+	specs.ActKill:        "kill_thread",
+	specs.ActKillThread:  "kill_thread",
+	specs.ActKillProcess: "kill_process",
+	specs.ActTrap:        "trap",
+	specs.ActErrno:       "errno",
+	specs.ActTrace:       "trace",
+	specs.ActAllow:       "allow",
+	specs.ActLog:         "log",
+	specs.ActNotify:      "user_notif",
+}
+
+// kernel version the syscalls added since 3.0 appeared in, on x86_64. The older ones are available on
+// any kernel that supports seccomp filtering. The 32 bit *_time64 syscalls were all added in 5.1.
+var syscallVersions = map[string]kernelVersion{
+	"process_vm_readv":             {3, 2},
+	"process_vm_writev":            {3, 2},
+	"kcmp":                         {3, 5},
+	"finit_module":                 {3, 8},
+	"sched_setattr":                {3, 14},
+	"sched_getattr":                {3, 14},
+	"renameat2":                    {3, 15},
+	"seccomp":                      {3, 17},
+	"getrandom":                    {3, 17},
+	"memfd_create":                 {3, 17},
+	"kexec_file_load":              {3, 17},
+	"bpf":                          {3, 18},
+	"execveat":                     {3, 19},
+	"userfaultfd":                  {4, 3},
+	"membarrier":                   {4, 3},
+	"mlock2":                       {4, 4},
+	"copy_file_range":              {4, 5},
+	"preadv2":                      {4, 6},
+	"pwritev2":                     {4, 6},
+	"pkey_mprotect":                {4, 9},
+	"pkey_alloc":                   {4, 9},
+	"pkey_free":                    {4, 9},
+	"statx":                        {4, 11},
+	"io_pgetevents":                {4, 18},
+	"rseq":                         {4, 18},
+	"pidfd_send_signal":            {5, 1},
+	"io_uring_setup":               {5, 1},
+	"io_uring_enter":               {5, 1},
+	"io_uring_register":            {5, 1},
+	"clock_gettime64":              {5, 1},
+	"clock_settime64":              {5, 1},
+	"clock_adjtime64":              {5, 1},
+	"clock_getres_time64":          {5, 1},
+	"clock_nanosleep_time64":       {5, 1},
+	"timer_gettime64":              {5, 1},
+	"timer_settime64":              {5, 1},
+	"timerfd_gettime64":            {5, 1},
+	"timerfd_settime64":            {5, 1},
+	"utimensat_time64":             {5, 1},
+	"pselect6_time64":              {5, 1},
+	"ppoll_time64":                 {5, 1},
+	"io_pgetevents_time64":         {5, 1},
+	"recvmmsg_time64":              {5, 1},
+	"mq_timedsend_time64":          {5, 1},
+	"mq_timedreceive_time64":       {5, 1},
+	"semtimedop_time64":            {5, 1},
+	"rt_sigtimedwait_time64":       {5, 1},
+	"futex_time64":                 {5, 1},
+	"sched_rr_get_interval_time64": {5, 1},
+	"open_tree":                    {5, 2},
+	"move_mount":                   {5, 2},
+	"fsopen":                       {5, 2},
+	"fsconfig":                     {5, 2},
+	"fsmount":                      {5, 2},
+	"fspick":                       {5, 2},
+	"pidfd_open":                   {5, 3},
+	"clone3":                       {5, 3},
+	"openat2":                      {5, 6},
+	"pidfd_getfd":                  {5, 6},
+	"faccessat2":                   {5, 8},
+	"close_range":                  {5, 9},
+	"process_madvise":              {5, 10},
+	"epoll_pwait2":                 {5, 11},
+	"mount_setattr":                {5, 12},
+	"landlock_create_ruleset":      {5, 13},
+	"landlock_add_rule":            {5, 13},
+	"landlock_restrict_self":       {5, 13},
+	"quotactl_fd":                  {5, 14},
+	"memfd_secret":                 {5, 14},
+	"process_mrelease":             {5, 15},
+	"futex_waitv":                  {5, 16},
+	"set_mempolicy_home_node":      {5, 17},
+	"cachestat":                    {6, 5},
+	"fchmodat2":                    {6, 6},
+	"map_shadow_stack":             {6, 6},
+	"futex_wake":                   {6, 7},
+	"futex_wait":                   {6, 7},
+	"futex_requeue":                {6, 7},
+	"statmount":                    {6, 8},
+	"listmount":                    {6, 8},
+	"lsm_get_self_attr":            {6, 8},
+	"lsm_set_self_attr":            {6, 8},
+	"lsm_list_modules":             {6, 8},
+	"mseal":                        {6, 10},
+}
+
+// knownSyscallNames returns the names of the syscalls in the tables of every arch
+func knownSyscallNames() map[string]bool {
+	names := make(map[string]bool)
+	for _, table := range syscallIDtoName {
+		for _, name := range table {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+// checkKernelCommand checks a profile can be loaded by the running kernel, or a given kernel version:
+// seccomp filtering is supported, and so are the actions used and the syscalls named in it
+func checkKernelCommand(args []string) {
+	fs := flag.NewFlagSet("check-kernel", flag.ExitOnError)
+	release := fs.String("kernel", "", "kernel version to check against, e.g. 4.19 (default the running kernel)")
+	parseFlags(fs, args)
+
+	if fs.NArg() < 1 {
+		fmt.Println("Usage: go2seccomp check-kernel [-kernel 4.19] /path/to/profile.json")
+		os.Exit(1)
+	}
+	profile := loadProfile(fs.Arg(0))
+
+	var problems []string
+	running := *release == ""
+	if running {
+		data, err := ioutil.ReadFile("/proc/sys/kernel/osrelease")
+		if err != nil {
+			fmt.Printf("Failed to read the running kernel version: %v\n", err)
+			os.Exit(1)
+		}
+		*release = string(data)
+	}
+	version, err := parseKernelVersion(*release)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	fmt.Printf("Checking %v against kernel %v\n", fs.Arg(0), version)
+
+	if version.less(seccompFilterVersion) {
+		problems = append(problems, fmt.Sprintf("seccomp filtering needs kernel %v", seccompFilterVersion))
+	}
+
+	// the running kernel tells what it supports, which also covers kernels built without seccomp
+	var available map[string]bool
+	if running {
+		if !procStatusHas("Seccomp:") {
+			problems = append(problems, "the running kernel is built without seccomp support (CONFIG_SECCOMP)")
+		}
+		if data, err := ioutil.ReadFile("/proc/sys/kernel/seccomp/actions_avail"); err == nil {
+			available = make(map[string]bool)
+			for _, name := range strings.Fields(string(data)) {
+				available[name] = true
+			}
+		}
+	}
+
+	checkAction := func(action specs.LinuxSeccompAction, where string) {
+		if available != nil {
+			if !available[actionKernelNames[action]] {
+				problems = append(problems, fmt.Sprintf("%v action %v isn't supported by the running kernel", where, action))
+			}
+			return
+		}
+		if v, ok := actionVersions[action]; ok && version.less(v) {
+			problems = append(problems, fmt.Sprintf("%v action %v needs kernel %v", where, action, v))
+		}
+	}
+	checkAction(profile.DefaultAction, "default")
+
+	known := knownSyscallNames()
+	var unknown, unsupported []string
+	for i, rule := range profile.Syscalls {
+		checkAction(rule.Action, fmt.Sprintf("syscalls[%v]", i))
+		for _, name := range rule.Names {
+			if v, ok := syscallVersions[name]; ok {
+				if version.less(v) {
+					unsupported = append(unsupported, fmt.Sprintf("%v (added in %v)", name, v))
+				}
+			} else if !known[name] {
+				unknown = append(unknown, name)
+			}
+		}
+	}
+	sort.Strings(unknown)
+	sort.Strings(unsupported)
+	if len(unsupported) > 0 {
+		problems = append(problems, fmt.Sprintf("syscalls unknown to kernel %v: %v", version, strings.Join(unsupported, ", ")))
+	}
+	if len(unknown) > 0 {
+		problems = append(problems, fmt.Sprintf("syscalls unknown to go2seccomp, check they exist: %v", strings.Join(unknown, ", ")))
+	}
+
+	if len(problems) == 0 {
+		fmt.Println("OK")
+		return
+	}
+	for _, problem := range problems {
+		fmt.Println(problem)
+	}
+	os.Exit(1)
+}
+
+// procStatusHas tells if /proc/self/status has the field
+func procStatusHas(field string) bool {
+	f, err := os.Open("/proc/self/status")
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if strings.HasPrefix(scanner.Text(), field) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+func init() {
+	commands["ko"] = koCommand
+}
+
+// ko puts the binary at /ko-app/<name> in the images it builds
+const koAppDir = "ko-app/"
+
+// media type of the profiles attached to images
+const profileArtifactType = "application/vnd.go2seccomp.profile+json"
+
+// koCommand generates the profile for an image built with ko. It either takes the published image
+// reference, or runs ko build itself with the given arguments and uses the image it publishes.
+func koCommand(args []string) {
+	fs := flag.NewFlagSet("ko", flag.ExitOnError)
+	output := fs.String("o", "profile.json", "path to write the profile to")
+	attach := fs.Bool("attach", false, "attach the profile to the image as an OCI artifact, using oras")
+	parseFlags(fs, args)
+
+	if fs.NArg() < 1 {
+		fmt.Println("Usage: go2seccomp ko [-o profile.json] [-attach] IMAGE")
+		fmt.Println("       go2seccomp ko [-o profile.json] [-attach] build [ko build flags] ./cmd/app")
+		os.Exit(1)
+	}
+
+	image := fs.Arg(0)
+	if image == "build" {
+		image = koBuild(fs.Args())
+	}
+
+	tmpDir, err := ioutil.TempDir("", "go2seccomp")
+	if err != nil {
+		fatalf("Failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	binaries, err := extractGoBinaries(image, tmpDir, koAppDir)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	if len(binaries) > 1 {
+		fatalf("Expected a single binary in %v of image %v, found %v", koAppDir, image, len(binaries))
+	}
+
+	graph, arch := analyzeBinary(binaries[0])
+	syscallsList := getSyscallList(graph.allSyscalls(), arch)
+	fmt.Printf("Syscalls detected (total: %v): %v\n", len(syscallsList), syscallsList)
+	writeProfile(syscallsList, arch, *output)
+
+	if *attach {
+		attachProfile(image, *output)
+	}
+}
+
+// koBuild runs ko with the given arguments and returns the reference of the image it published,
+// which ko prints as the last line of its output
+func koBuild(args []string) string {
+	fmt.Printf("Running ko %v\n", strings.Join(args, " "))
+	cmd := exec.Command("ko", args...)
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		fatalf("ko build failed: %v", err)
+	}
+
+	image := ""
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			image = line
+		}
+	}
+	if image == "" {
+		fatalln("ko build didn't print the published image")
+	}
+	fmt.Printf("ko published %v\n", image)
+	return image
+}
+
+// attachProfile attaches the profile to the image in its registry as an OCI artifact referring to it
+func attachProfile(image, profilePath string) {
+	cmd := exec.Command("oras", "attach", "--artifact-type", profileArtifactType, image, profilePath+":application/json")
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		fatalf("Failed to attach profile to %v: %v", image, err)
+	}
+	fmt.Printf("Attached profile to %v\n", image)
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"debug/elf"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+func init() {
+	registerFormatter(formatFunc{"landlock", ".json", writeLandlockRuleset})
+}
+
+// stringRef is a string constant loaded by a function, "LEAQ 0x62b9(IP), AX" followed by "MOVL $0xa, BX"
+type stringRef struct {
+	Function string
+	Address  uint64
+	Length   int64
+}
+
+// pathConstant is an absolute path found among the string constants of a function
+type pathConstant struct {
+	Function string
+	Path     string
+}
+
+// paths worth confining, long enough not to be a bare "/" or a URL path
+var pathConstantRegexp = regexp.MustCompile(`^/(bin|boot|dev|etc|home|lib|lib64|media|mnt|opt|proc|root|run|sbin|srv|sys|tmp|usr|var)(/[\w.@+-]+)*/?$`)
+
+// where the programs usually write, anything else in the binary is suggested read-only
+var writablePathPrefixes = []string{"/tmp", "/var", "/run", "/home", "/srv", "/dev/shm", "/mnt", "/media"}
+
+// absolute paths among the string constants of the functions the analysis kept, set by recordPathConstants
+var binaryPaths []string
+
+// scanStringRef records the string constant loaded by the instruction and the previous one. Only amd64
+// loads the address and the length of the strings in a way easy to tell apart.
+func scanStringRef(graph *callGraph, arch specs.Arch, function, instruction string, previousInstructions []string, curPos int) {
+	if arch != specs.ArchX86_64 {
+		return
+	}
+	fields := strings.Fields(instruction)
+	if len(fields) != 6 || (fields[3] != "MOVL" && fields[3] != "MOVQ") || !strings.HasPrefix(fields[4], "$") {
+		return
+	}
+	length, err := strconv.ParseInt(strings.TrimSuffix(fields[4][1:], ","), 0, 64)
+	if err != nil || length < 2 || length > 4096 {
+		return
+	}
+
+	previous := strings.Fields(previousInstructions[(curPos-1+previousInstructionsBufferSize)%previousInstructionsBufferSize])
+	if len(previous) != 6 || previous[3] != "LEAQ" || !strings.HasSuffix(previous[4], "(IP),") {
+		return
+	}
+	offset, err := strconv.ParseInt(strings.TrimSuffix(previous[4], "(IP),"), 0, 64)
+	if err != nil {
+		return
+	}
+	address, err := strconv.ParseUint(previous[1], 0, 64)
+	if err != nil {
+		return
+	}
+	// the offset is relative to the end of the instruction, printed in hex
+	next := address + uint64(len(previous[2])/2)
+	graph.stringRefs = append(graph.stringRefs, stringRef{function, uint64(int64(next) + offset), length})
+}
+
+// resolvePathConstants reads the recorded string constants from .rodata and keeps the absolute paths
+func (g *callGraph) resolvePathConstants(f *elf.File) {
+	rodata := f.Section(".rodata")
+	if rodata == nil {
+		return
+	}
+	for _, ref := range g.stringRefs {
+		if ref.Address < rodata.Addr || ref.Address+uint64(ref.Length) > rodata.Addr+rodata.Size {
+			continue
+		}
+		data := make([]byte, ref.Length)
+		if _, err := rodata.ReadAt(data, int64(ref.Address-rodata.Addr)); err != nil {
+			continue
+		}
+		if path := string(data); pathConstantRegexp.MatchString(path) {
+			g.paths = append(g.paths, pathConstant{ref.Function, path})
+		}
+	}
+	g.stringRefs = nil
+}
+
+// recordPathConstants records the paths found in the given functions (all if nil), for the landlock format
+func recordPathConstants(graph *callGraph, functions map[string]bool) {
+	binaryPaths = nil
+	for _, path := range graph.paths {
+		if functions == nil || functions[path.Function] {
+			binaryPaths = append(binaryPaths, path.Path)
+		}
+	}
+	sort.Strings(binaryPaths)
+	binaryPaths = uniqueStrings(binaryPaths)
+}
+
+// Landlock filesystem access rights of the first ABI, in kernel 5.13, as named by the LANDLOCK_ACCESS_FS_ constants
+var landlockAccessRights = []string{
+	"execute", "write_file", "read_file", "read_dir", "remove_dir", "remove_file", "make_char", "make_dir",
+	"make_reg", "make_sock", "make_fifo", "make_block", "make_sym",
+}
+
+// write access rights the syscalls need
+var syscallWriteRights = map[string][]string{
+	"open": {"write_file", "make_reg"}, "openat": {"write_file", "make_reg"}, "openat2": {"write_file", "make_reg"},
+	"creat": {"write_file", "make_reg"}, "truncate": {"write_file"},
+	"mkdir": {"make_dir"}, "mkdirat": {"make_dir"}, "rmdir": {"remove_dir"},
+	"unlink": {"remove_file"}, "unlinkat": {"remove_file", "remove_dir"},
+	"rename": {"remove_file", "make_reg"}, "renameat": {"remove_file", "make_reg"}, "renameat2": {"remove_file", "make_reg"},
+	"link": {"make_reg"}, "linkat": {"make_reg"}, "symlink": {"make_sym"}, "symlinkat": {"make_sym"},
+	"mknod": {"make_fifo", "make_char", "make_block", "make_sock"}, "mknodat": {"make_fifo", "make_char", "make_block", "make_sock"},
+	"bind": {"make_sock"},
+}
+
+// landlockRuleset is a suggested Landlock ruleset: the handled access rights are denied outside the paths given
+type landlockRuleset struct {
+	Comment         string            `json:"comment"`
+	MinKernel       string            `json:"minKernel"`
+	ABI             int               `json:"abi"`
+	HandledAccessFS []string          `json:"handledAccessFs"`
+	ReadOnly        landlockPathRules `json:"readOnly"`
+	ReadWrite       landlockPathRules `json:"readWrite"`
+}
+
+type landlockPathRules struct {
+	Access []string `json:"access"`
+	Paths  []string `json:"paths"`
+}
+
+// writeLandlockRuleset writes a Landlock ruleset suggestion to pair with the seccomp profile: the path constants
+// of the binary split in read-only and read-write ones, with the access rights the allowed syscalls need
+func writeLandlockRuleset(w io.Writer, profile specs.LinuxSeccomp, profilePath string) error {
+	allowed := make(map[string]bool)
+	for _, rule := range profile.Syscalls {
+		if runningActions[rule.Action] {
+			for _, name := range rule.Names {
+				allowed[name] = true
+			}
+		}
+	}
+
+	readRights := []string{"read_file", "read_dir"}
+	if runsPrograms {
+		readRights = append(readRights, "execute")
+	}
+	writeRights := make(map[string]bool)
+	for syscall := range allowed {
+		for _, right := range syscallWriteRights[syscall] {
+			writeRights[right] = true
+		}
+	}
+	readWriteRights := append([]string(nil), readRights...)
+	for _, right := range landlockAccessRights {
+		if writeRights[right] {
+			readWriteRights = append(readWriteRights, right)
+		}
+	}
+
+	ruleset := landlockRuleset{
+		Comment: "Landlock ruleset suggested by go2seccomp for " + analyzedBinary + " from the paths in the binary, " +
+			"remove the paths that don't exist and add the ones built at run time",
+		MinKernel:       "5.13",
+		ABI:             1,
+		HandledAccessFS: landlockAccessRights,
+		ReadOnly:        landlockPathRules{Access: readRights, Paths: []string{}},
+		ReadWrite:       landlockPathRules{Access: readWriteRights, Paths: []string{}},
+	}
+	for _, path := range binaryPaths {
+		if len(writeRights) > 0 && hasPathPrefix(path, writablePathPrefixes) {
+			ruleset.ReadWrite.Paths = append(ruleset.ReadWrite.Paths, path)
+		} else {
+			ruleset.ReadOnly.Paths = append(ruleset.ReadOnly.Paths, path)
+		}
+	}
+
+	enc := newProfileEncoder(w)
+	return enc.Encode(ruleset)
+}
+
+// hasPathPrefix tells if the path is one of the directories or under it
+func hasPathPrefix(path string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+func init() {
+	commands["validate"] = validateCommand
+}
+
+var writeLock = flag.Bool("write-lock", false, "write a lock file next to each profile (profile.lock.json), recording everything needed to reproduce it")
+
+// options that don't change the profile, left out when reproducing it
+var lockIgnoredOptions = map[string]bool{
+	"lock": true, "write-lock": true, "attest": true, "report": true, "out-dir": true, "format": true, "v": true, "vv": true,
+}
+
+// profileLock records the inputs a profile was generated from, to check it's reproducible
+type profileLock struct {
+	Binary  inTotoSubject     `json:"binary"`
+	Profile inTotoSubject     `json:"profile"`
+	Format  string            `json:"format"`
+	Arch    specs.Arch        `json:"arch"`
+	Tool    map[string]string `json:"tool"`
+	// go version of the toolchain whose objdump disassembled the binary
+	Disassembler string `json:"disassembler"`
+	// sha256 of the syscall table of the arch, with the -syscall-table overrides
+	SyscallTable string `json:"syscallTable"`
+	// every flag, with its effective value
+	Options map[string]string `json:"options"`
+}
+
+// lockPath returns where the lock file of a profile is written, profile.json => profile.lock.json
+func lockPath(profilePath string) string {
+	ext := filepath.Ext(profilePath)
+	return strings.TrimSuffix(profilePath, ext) + ".lock.json"
+}
+
+// syscallTableDigest returns the sha256 of the syscall table of the arch
+func syscallTableDigest(arch specs.Arch) string {
+	ids := make([]int, 0, len(syscallIDtoName[arch]))
+	for id := range syscallIDtoName[arch] {
+		ids = append(ids, int(id))
+	}
+	sort.Ints(ids)
+	var buf bytes.Buffer
+	for _, id := range ids {
+		fmt.Fprintf(&buf, "%v %v\n", id, syscallIDtoName[arch][int64(id)])
+	}
+	return sha256Hex(buf.Bytes())
+}
+
+// disassemblerVersion returns the version of the go toolchain running objdump
+func disassemblerVersion() string {
+	out, err := exec.Command("go", "version").Output()
+	if err != nil {
+		return "unknown"
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// newProfileLock records the inputs of the profile generated for the analyzed binary
+func newProfileLock(profilePath, format string, arch specs.Arch) profileLock {
+	options := make(map[string]string)
+	flag.VisitAll(func(f *flag.Flag) {
+		options[f.Name] = f.Value.String()
+	})
+	return profileLock{
+		Binary: inTotoSubject{
+			Name:   analyzedBinary,
+			Digest: map[string]string{"sha256": analyzedBinarySHA256()},
+		},
+		Profile: inTotoSubject{
+			Name:   filepath.Base(profilePath),
+			Digest: map[string]string{"sha256": fileSHA256(profilePath)},
+		},
+		Format: format,
+		Arch:   arch,
+		Tool: map[string]string{
+			"name":    "go2seccomp",
+			"version": toolVersion(),
+			"go":      runtime.Version(),
+		},
+		Disassembler: disassemblerVersion(),
+		SyscallTable: syscallTableDigest(arch),
+		Options:      options,
+	}
+}
+
+// writeProfileLock writes the lock file of the profile generated for the analyzed binary
+func writeProfileLock(profilePath, format string, arch specs.Arch) {
+	data, err := json.MarshalIndent(newProfileLock(profilePath, format, arch), "", "    ")
+	if err != nil {
+		fatalf("Failed to encode lock file: %v", err)
+	}
+	if err := writeFileAtomic(lockPath(profilePath), append(data, '\n'), 0644); err != nil {
+		fatalf("Failed to write lock file: %v", err)
+	}
+	fmt.Printf("Saved lock file at %v\n", lockPath(profilePath))
+}
+
+// validateCommand checks a profile would be accepted by runtimes and, with -lock, that it's reproducible:
+// the inputs recorded in the lock file are the same, and generating it again gives the same profile
+func validateCommand(args []string) {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	lockFile := fs.String("lock", "", "lock file of the profile, to check it's reproducible")
+	binaryPath := fs.String("binary", "", "binary the profile was generated for, the one in the lock file by default")
+	parseFlags(fs, args)
+
+	if fs.NArg() != 1 {
+		fmt.Println("Usage: go2seccomp validate [-lock profile.lock.json] [-binary /path/to/binary] profile.json")
+		os.Exit(1)
+	}
+	profilePath := fs.Arg(0)
+
+	var problems []string
+	for _, err := range validateProfile(loadProfile(profilePath)) {
+		problems = append(problems, err.Error())
+	}
+	if *lockFile != "" {
+		problems = append(problems, checkLock(profilePath, *lockFile, *binaryPath)...)
+	}
+
+	if len(problems) > 0 {
+		for _, problem := range problems {
+			fmt.Printf("    %v\n", problem)
+		}
+		if *lockFile != "" {
+			fatalf("%v isn't valid or reproducible", profilePath)
+		}
+		fatalf("%v isn't valid", profilePath)
+	}
+	if *lockFile != "" {
+		fmt.Printf("%v is valid and reproducible\n", profilePath)
+	} else {
+		fmt.Printf("%v is valid\n", profilePath)
+	}
+}
+
+// checkLock returns the differences between the inputs recorded in the lock file and the current ones,
+// and whether the profile generated again from them differs
+func checkLock(profilePath, lockFile, binaryPath string) []string {
+	data, err := ioutil.ReadFile(lockFile)
+	if err != nil {
+		fatalf("Failed to read lock file: %v", err)
+	}
+	var lock profileLock
+	if err := json.Unmarshal(data, &lock); err != nil {
+		fatalf("Failed to parse lock file: %v", err)
+	}
+	if binaryPath == "" {
+		binaryPath = lock.Binary.Name
+	}
+
+	var problems []string
+	differs := func(what, locked, current string) {
+		if locked != current {
+			problems = append(problems, fmt.Sprintf("%v is %v, locked %v", what, current, locked))
+		}
+	}
+	differs("profile sha256", lock.Profile.Digest["sha256"], fileSHA256(profilePath))
+	differs("binary sha256", lock.Binary.Digest["sha256"], fileSHA256(binaryPath))
+	differs("go2seccomp version", lock.Tool["version"], toolVersion())
+	differs("disassembler", lock.Disassembler, disassemblerVersion())
+	if *syscallTablePath == "" && lock.Options["syscall-table"] != "" {
+		loadSyscallTable(lock.Options["syscall-table"])
+	}
+	differs("syscall table sha256", lock.SyscallTable, syscallTableDigest(lock.Arch))
+	if lock.Options["interactive"] == "true" {
+		problems = append(problems, "the profile was reviewed with -interactive, it can't be generated again")
+		return problems
+	}
+
+	regenerated := regenerateProfile(lock, binaryPath)
+	differs("regenerated profile sha256", lock.Profile.Digest["sha256"], fileSHA256(regenerated))
+	os.RemoveAll(filepath.Dir(regenerated))
+	return problems
+}
+
+// regenerateProfile runs go2seccomp again with the options of the lock file, without the environment
+// setting other ones, and returns the path of the profile it writes
+func regenerateProfile(lock profileLock, binaryPath string) string {
+	dir, err := ioutil.TempDir("", "go2seccomp")
+	if err != nil {
+		fatalf("Failed to create temporary directory: %v", err)
+	}
+	output := filepath.Join(dir, lock.Profile.Name)
+
+	args := []string{"-format=" + lock.Format}
+	for name, value := range lock.Options {
+		f := flag.Lookup(name)
+		if f == nil || lockIgnoredOptions[name] || value == f.DefValue {
+			continue
+		}
+		args = append(args, "-"+name+"="+value)
+	}
+	sort.Strings(args)
+	args = append(args, binaryPath, output)
+
+	executable, err := os.Executable()
+	if err != nil {
+		fatalf("Failed to find the go2seccomp executable: %v", err)
+	}
+	fmt.Printf("Generating the profile again with %v\n", strings.Join(args, " "))
+	cmd := exec.Command(executable, args...)
+	for _, env := range os.Environ() {
+		if !strings.HasPrefix(env, envPrefix) {
+			cmd.Env = append(cmd.Env, env)
+		}
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		fatalf("Failed to generate the profile again: %v\n%s", err, out)
+	}
+	return output
+}
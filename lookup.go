@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+func init() {
+	commands["lookup"] = lookupCommand
+}
+
+// lookupCommand translates syscall numbers to names and names to numbers, with the same tables
+// (including -syscall-table overrides) the profiles are generated with
+func lookupCommand(args []string) {
+	fs := flag.NewFlagSet("lookup", flag.ExitOnError)
+	archName := fs.String("arch", "amd64", "architecture of the syscall table (amd64, 386, arm, arm64, ppc64le, mips, mipsle, mips64, mips64le)")
+	parseFlags(fs, args)
+
+	if fs.NArg() < 1 {
+		fmt.Println("Usage: go2seccomp lookup [-arch amd64] name|number...")
+		os.Exit(1)
+	}
+
+	arch, ok := archByName(*archName)
+	if !ok {
+		fmt.Printf("Architecture %v is not supported\n", *archName)
+		os.Exit(1)
+	}
+	table := syscallIDtoName[arch]
+
+	nameToID := make(map[string]int64, len(table))
+	for id, name := range table {
+		nameToID[name] = id
+	}
+
+	failed := false
+	for _, arg := range fs.Args() {
+		if id, err := strconv.ParseInt(arg, 0, 64); err == nil {
+			if name, ok := table[id]; ok {
+				fmt.Printf("%v %v\n", id, name)
+				continue
+			}
+		} else if id, ok := nameToID[arg]; ok {
+			fmt.Printf("%v %v\n", arg, id)
+			continue
+		}
+		fmt.Printf("%v: not found on %v\n", arg, arch)
+		failed = true
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+// the syscall setting up thread local storage on each arch, which programs make before anything else
+var tlsSyscalls = map[specs.Arch]string{
+	specs.ArchX86_64: "arch_prctl",
+	specs.ArchX86:    "set_thread_area",
+}
+
+// functions making a low level syscall that's easily missed, often because the number is passed
+// around in registers, and the syscall they make. "tls" stands for the TLS syscall of the arch.
+var lowLevelFunctions = map[string]string{
+	"runtime.settls":                    "tls",
+	"runtime.setldt":                    "tls",
+	"__libc_setup_tls":                  "tls",
+	"arch_prctl":                        "arch_prctl",
+	"personality":                       "personality",
+	"__personality":                     "personality",
+	"golang.org/x/sys/unix.Personality": "personality",
+}
+
+// addLowLevelSyscalls records the syscalls of the low level functions in the binary, and the TLS syscall
+// the dynamic loader of dynamically linked binaries makes before the program starts
+func (g *callGraph) addLowLevelSyscalls(arch specs.Arch) {
+	nameToID := make(map[string]int64)
+	for id, name := range syscallIDtoName[arch] {
+		nameToID[name] = id
+	}
+	add := func(function, syscall string) {
+		if syscall == "tls" {
+			syscall = tlsSyscalls[arch]
+		}
+		if id, ok := nameToID[syscall]; ok && !g.syscalls[function][id] {
+			g.addSyscall(function, id, "", confidenceDerived)
+		}
+	}
+
+	for function := range g.syscalls {
+		if syscall, ok := lowLevelFunctions[canonicalSyscallFunction(strings.TrimSuffix(function, ".abi0"))]; ok {
+			add(function, syscall)
+		}
+	}
+	if dynamicBinary {
+		add("dynamic loader", "tls")
+	}
+}
@@ -2,62 +2,92 @@ package main
 
 import (
 	"bufio"
+	"debug/elf"
+	"encoding/binary"
 	"flag"
 	"fmt"
 	"log"
 	"os"
-	"sort"
+	"path/filepath"
 	"strconv"
 	"strings"
 
 	"github.com/opencontainers/runtime-spec/specs-go"
 )
 
-// TODO add a verbose flag and do a proper verbose mode
+// set by -v and -vv
 var verbose = false
 
+var rulesPath = flag.String("rules", "", "YAML file with custom syscall detection rules")
+var entrypoints = flag.String("entrypoints", "", "comma separated applets (name or name=function glob) to generate one profile each for, plus their union")
+var entryFuncs stringList
+var unpack = flag.Bool("unpack", false, "unpack binaries packed with UPX before analyzing them")
+var debugFileDir = flag.String("debug-file-directory", "/usr/lib/debug", "directory with separate debug info for stripped binaries")
+var debuginfod = flag.Bool("debuginfod", false, "fetch debug info for stripped binaries from the DEBUGINFOD_URLS servers")
+var reportPath = flag.String("report", "", "write a JSON report with the call sites of each syscall to this file")
+var basePath = flag.String("base", "", "existing profile whose allowed syscalls are added to the generated one")
+var format = flag.String("format", "oci", "comma separated output formats")
+var lockProfiles = flag.Bool("lock", false, "take a lock file next to each profile while writing it, for concurrent runs sharing an output")
+var syscallTablePath = flag.String("syscall-table", "", "JSON file overriding or extending the syscall ID->name tables per arch")
+var outputPath = flag.String("output", "", "path to write the profile to instead of the second argument, - for stdout")
+var expectedArch = flag.String("arch", "", "arch the binary must be built for (amd64, arm64, arm, 386, ppc64le, mips...), failing when it's built for another one")
+
 // need to save the previous instructions to go back and look for the syscall ID
 // have found MOVs to 0(SP) as far as 10 instructions behind, so 15 seems like a safe number
 const previousInstructionsBufferSize = 15
 
-// wrapper for each findSyscallID by arch
-func findSyscallID(arch specs.Arch, previouInstructions []string, curPos int) (int64, error) {
+// wrapper for each findSyscallID by arch, returning the ID and how confident it is
+func findSyscallID(arch specs.Arch, previouInstructions []string, curPos int) (int64, string, error) {
 	var i int64
+	var confidence string
 	var err error
 
 	switch arch {
 	case specs.ArchX86_64:
-		i, err = findSyscallIDx86_64(previouInstructions, curPos)
+		i, confidence, err = findSyscallIDx86_64(previouInstructions, curPos)
 	case specs.ArchX86:
-		i, err = findSyscallIDx86(previouInstructions, curPos)
+		i, confidence, err = findSyscallIDx86(previouInstructions, curPos)
 	case specs.ArchARM:
-		i, err = findSyscallIDARM(previouInstructions, curPos)
+		i, confidence, err = findSyscallIDARM(previouInstructions, curPos)
+	case specs.ArchAARCH64:
+		i, confidence, err = findSyscallIDarm64(previouInstructions, curPos)
+	case specs.ArchPPC64LE:
+		i, confidence, err = findSyscallIDppc64le(previouInstructions, curPos)
+	case specs.ArchMIPS, specs.ArchMIPSEL, specs.ArchMIPS64, specs.ArchMIPSEL64:
+		i, confidence, err = findSyscallIDmips(arch, previouInstructions, curPos)
 	default:
-		log.Fatalln(arch, "is not supported")
+		fatalln(arch, "is not supported")
 	}
 
-	return i, err
+	return i, confidence, err
 }
 
-func findRuntimeSyscallID(arch specs.Arch, previouInstructions []string, curPos int) (int64, error) {
+func findRuntimeSyscallID(arch specs.Arch, previouInstructions []string, curPos int) (int64, string, error) {
 	var i int64
+	var confidence string
 	var err error
 
 	switch arch {
 	case specs.ArchX86_64:
-		i, err = findRuntimeSyscallIDx86_64(previouInstructions, curPos)
+		i, confidence, err = findRuntimeSyscallIDx86_64(previouInstructions, curPos)
 	case specs.ArchX86:
-		i, err = findRuntimeSyscallIDx86_64(previouInstructions, curPos) // Same as x86_64 ?
+		i, confidence, err = findRuntimeSyscallIDx86(previouInstructions, curPos)
 	case specs.ArchARM:
-		i, err = findRuntimeSyscallIDARM(previouInstructions, curPos)
+		i, confidence, err = findRuntimeSyscallIDARM(previouInstructions, curPos)
+	case specs.ArchAARCH64:
+		i, confidence, err = findRuntimeSyscallIDarm64(previouInstructions, curPos)
+	case specs.ArchPPC64LE:
+		i, confidence, err = findRuntimeSyscallIDppc64le(previouInstructions, curPos)
+	case specs.ArchMIPS, specs.ArchMIPSEL, specs.ArchMIPS64, specs.ArchMIPSEL64:
+		i, confidence, err = findRuntimeSyscallIDmips(previouInstructions, curPos)
 	default:
-		log.Fatalln(arch, "is not supported")
+		fatalln(arch, "is not supported")
 	}
 
-	return i, err
+	return i, confidence, err
 }
 
-func findRuntimeSyscallIDx86_64(previouInstructions []string, curPos int) (int64, error) {
+func findRuntimeSyscallIDx86_64(previouInstructions []string, curPos int) (int64, string, error) {
 	i := 0
 
 	for i < previousInstructionsBufferSize {
@@ -73,13 +103,13 @@ func findRuntimeSyscallIDx86_64(previouInstructions []string, curPos int) (int64
 		// which must be faster to zero the register than using a MOV, so we need to account for this
 		isRead := strings.Index(instruction, "XOR") != -1 && strings.Index(instruction, " AX, AX") != -1
 		if isRead {
-			return 0, nil
+			return 0, confidenceExact, nil
 		}
 
 		if isMOV && isAXRegister {
 			syscallIDBeginning := strings.Index(instruction, "$")
 			if syscallIDBeginning == -1 {
-				return -1, fmt.Errorf("Failed to find syscall ID on line: %v", instruction)
+				return -1, "", fmt.Errorf("Failed to find syscall ID on line: %v", instruction)
 			}
 			syscallIDEnd := strings.Index(instruction, ", AX")
 
@@ -87,49 +117,172 @@ func findRuntimeSyscallIDx86_64(previouInstructions []string, curPos int) (int64
 			id, err := strconv.ParseInt(hex, 0, 64)
 
 			if err != nil {
-				return -1, fmt.Errorf("Error parsing hex id: %v", err)
+				return -1, "", fmt.Errorf("Error parsing hex id: %v", err)
 			}
-			return id, nil
+			return id, windowConfidence(i), nil
 		}
 		i++
 		curPos--
 	}
-	return -1, fmt.Errorf("Failed to find syscall ID")
+	return -1, "", fmt.Errorf("Failed to find syscall ID")
 }
 
-func findRuntimeSyscallIDARM(previouInstructions []string, curPos int) (int64, error) {
+// findRuntimeSyscallIDx86 goes back from the INT $0x80 until it finds the instruction setting AX,
+// which holds the syscall ID. The runtime on syscall/sys_linux_386.s sets it with MOVL $ID, AX, but the
+// arguments are loaded into the other registers in between, and AX may be copied elsewhere first, e.g.:
+// MOVL $0x14, AX
+// INT $0x80
+// MOVL AX, BX
+// MOVL $0xe0, AX
+// INT $0x80
+func findRuntimeSyscallIDx86(previouInstructions []string, curPos int) (int64, string, error) {
 	i := 0
 
 	for i < previousInstructionsBufferSize {
-		instruction := previouInstructions[curPos%previousInstructionsBufferSize]
-		isR7 := strings.Index(instruction, ", R7") != -1
-		isNotReg := strings.Index(instruction, "),") == -1 // get the "(R15)," ending in MOVW 0x2c(R15), R7
-
-		if isR7 && isNotReg {
-			syscallIDBeginning := strings.Index(instruction, "$")
-			if syscallIDBeginning == -1 {
-				return -1, fmt.Errorf("Failed to find syscall ID on line: %v", instruction)
+		instruction := strings.TrimSpace(previouInstructions[curPos%previousInstructionsBufferSize])
+		fields := strings.Split(instruction, "\t")
+		op := strings.TrimSpace(fields[len(fields)-1])
+
+		// only instructions with AX as their destination matter, not MOVL AX, BX or MOVL CX, 0(AX)
+		if strings.HasSuffix(op, ", AX") {
+			if strings.HasPrefix(op, "XORL AX, AX") {
+				return 0, confidenceExact, nil
 			}
-			syscallIDEnd := strings.Index(instruction, ", R7")
-
-			hex := instruction[syscallIDBeginning+1 : syscallIDEnd]
-			id, err := strconv.ParseInt(hex, 0, 64)
-
+			if !strings.HasPrefix(op, "MOVL $") {
+				return -1, "", fmt.Errorf("Syscall ID isn't a constant on line: %v", instruction)
+			}
+			id, err := strconv.ParseInt(strings.TrimSuffix(strings.TrimPrefix(op, "MOVL $"), ", AX"), 0, 64)
 			if err != nil {
-				return -1, fmt.Errorf("Error parsing hex id: %v", err)
+				return -1, "", fmt.Errorf("Error parsing hex id: %v", err)
 			}
-			return id, nil
+			return id, windowConfidence(i), nil
 		}
 		i++
 		curPos--
 	}
-	return -1, fmt.Errorf("Failed to find syscall ID")
+	return -1, "", fmt.Errorf("Failed to find syscall ID")
+}
+
+// findRuntimeSyscallIDARM goes back from the SVC/SWI $0 until it finds the instruction setting R7, which
+// holds the syscall ID on EABI. Besides MOVW $ID, R7, the ID can be built with MVN or loaded from the
+// constant pool after the function (MOVW 0xc(R15), R7) when it doesn't fit in an immediate, e.g. on
+// GOARM=5, and adjusted with arithmetic on R7 before the SVC, e.g.:
+// MOVW $0, R7
+// ADD $240, R7
+// SVC $0
+func findRuntimeSyscallIDARM(previouInstructions []string, curPos int) (int64, string, error) {
+	i := 0
+	// the operations applied to R7 after it was set, from the last one
+	var ops []string
+
+	for i < previousInstructionsBufferSize {
+		instruction := strings.TrimSpace(previouInstructions[curPos%previousInstructionsBufferSize])
+		fields := strings.Split(instruction, "\t")
+		op := strings.TrimSpace(fields[len(fields)-1])
+		i++
+		curPos--
+
+		if !strings.HasSuffix(op, ", R7") {
+			continue
+		}
+		mnemonic := strings.SplitN(op, " ", 2)[0]
+		operands := strings.Split(strings.TrimPrefix(op, mnemonic+" "), ", ")
+
+		// MOVW 0xc(R15), R7 loads from the constant pool, relative to the instruction address plus 8
+		if mnemonic == "MOVW" && strings.HasSuffix(operands[0], "(R15)") && len(fields) > 1 {
+			offset, err := strconv.ParseInt(strings.TrimSuffix(operands[0], "(R15)"), 0, 64)
+			if err != nil {
+				return -1, "", fmt.Errorf("Error parsing constant pool offset on line: %v", instruction)
+			}
+			address, err := strconv.ParseUint(strings.TrimSpace(fields[1]), 0, 64)
+			if err != nil {
+				return -1, "", fmt.Errorf("Error parsing address on line: %v", instruction)
+			}
+			word, ok := readARMWord(uint64(int64(address) + 8 + offset))
+			if !ok {
+				return -1, "", fmt.Errorf("Failed to read the constant pool for line: %v", instruction)
+			}
+			id, err := applyARMOps(int64(word), ops)
+			return id, confidenceDerived, err
+		}
+
+		if !strings.HasPrefix(operands[0], "$") {
+			return -1, "", fmt.Errorf("Syscall ID isn't a constant on line: %v", instruction)
+		}
+		imm, err := strconv.ParseInt(operands[0][1:], 0, 64)
+		if err != nil {
+			return -1, "", fmt.Errorf("Error parsing hex id: %v", err)
+		}
+
+		switch {
+		case mnemonic == "MOVW":
+			confidence := windowConfidence(i - 1)
+			if len(ops) > 0 {
+				confidence = confidenceDerived
+			}
+			id, err := applyARMOps(imm, ops)
+			return id, confidence, err
+		case mnemonic == "MVN":
+			id, err := applyARMOps(int64(^uint32(imm)), ops)
+			return id, confidenceDerived, err
+		// ADD $x, R7 and ADD $x, R7, R7 both update R7 in place
+		case len(operands) == 2 || (len(operands) == 3 && operands[1] == "R7"):
+			ops = append(ops, mnemonic+" "+operands[0][1:])
+		default:
+			return -1, "", fmt.Errorf("Syscall ID isn't a constant on line: %v", instruction)
+		}
+	}
+	return -1, "", fmt.Errorf("Failed to find syscall ID")
+}
+
+// applyARMOps applies the arithmetic found on R7 to its initial value, ops are in reverse order
+func applyARMOps(value int64, ops []string) (int64, error) {
+	v := uint32(value)
+	for i := len(ops) - 1; i >= 0; i-- {
+		parts := strings.SplitN(ops[i], " ", 2)
+		imm, _ := strconv.ParseInt(parts[1], 0, 64)
+		switch parts[0] {
+		case "ADD":
+			v += uint32(imm)
+		case "SUB":
+			v -= uint32(imm)
+		case "ORR":
+			v |= uint32(imm)
+		case "EOR":
+			v ^= uint32(imm)
+		case "BIC":
+			v &^= uint32(imm)
+		case "AND":
+			v &= uint32(imm)
+		default:
+			return -1, fmt.Errorf("Unsupported operation on the syscall ID: %v", ops[i])
+		}
+	}
+	return int64(v), nil
+}
+
+// armText is the .text section of the ARM binary being analyzed, to read its constant pools
+var armText *elf.Section
+
+// byte order of the binary being analyzed, which isn't the one of the host for big endian targets
+var binaryByteOrder binary.ByteOrder = binary.LittleEndian
+
+// readARMWord reads the word at the address of the .text section, in the byte order of the binary
+func readARMWord(address uint64) (uint32, bool) {
+	if armText == nil || address < armText.Addr || address+4 > armText.Addr+armText.Size {
+		return 0, false
+	}
+	word := make([]byte, 4)
+	if _, err := armText.ReadAt(word, int64(address-armText.Addr)); err != nil {
+		return 0, false
+	}
+	return binaryByteOrder.Uint32(word), true
 }
 
 // findSyscallIDx86_64 goes back from the call until it finds an instruction with the format
 // MOVQ $ID, 0(SP), which is the one that pushes the syscall ID onto the base address
 // at the SP register
-func findSyscallIDx86_64(previouInstructions []string, curPos int) (int64, error) {
+func findSyscallIDx86_64(previouInstructions []string, curPos int) (int64, string, error) {
 	i := 0
 
 	for i < previousInstructionsBufferSize {
@@ -141,7 +294,7 @@ func findSyscallIDx86_64(previouInstructions []string, curPos int) (int64, error
 		if isMOVQ && isBaseSPAddress {
 			syscallIDBeginning := strings.Index(instruction, "$")
 			if syscallIDBeginning == -1 {
-				return -1, fmt.Errorf("Failed to find syscall ID on line: %v", instruction)
+				return -1, "", fmt.Errorf("Failed to find syscall ID on line: %v", instruction)
 			}
 			syscallIDEnd := strings.Index(instruction, ", 0(SP)")
 
@@ -149,20 +302,20 @@ func findSyscallIDx86_64(previouInstructions []string, curPos int) (int64, error
 			id, err := strconv.ParseInt(hex, 0, 64)
 
 			if err != nil {
-				return -1, fmt.Errorf("Error parsing hex id: %v", err)
+				return -1, "", fmt.Errorf("Error parsing hex id: %v", err)
 			}
-			return id, nil
+			return id, windowConfidence(i), nil
 		}
 		i++
 		curPos--
 	}
-	return -1, fmt.Errorf("Failed to find syscall ID")
+	return -1, "", fmt.Errorf("Failed to find syscall ID")
 }
 
 // findSyscallIDx86 goes back from the call until it finds an instruction with the format
 // MOVL $ID, 0(SP), which is the one that pushes the syscall ID onto the base address
 // at the SP register
-func findSyscallIDx86(previouInstructions []string, curPos int) (int64, error) {
+func findSyscallIDx86(previouInstructions []string, curPos int) (int64, string, error) {
 	i := 0
 	for i < previousInstructionsBufferSize {
 		instruction := previouInstructions[curPos%previousInstructionsBufferSize]
@@ -173,7 +326,7 @@ func findSyscallIDx86(previouInstructions []string, curPos int) (int64, error) {
 		if isMOVL && isBaseSPAddress {
 			syscallIDBeginning := strings.Index(instruction, "$")
 			if syscallIDBeginning == -1 {
-				return -1, fmt.Errorf("Failed to find syscall ID on line: %v", instruction)
+				return -1, "", fmt.Errorf("Failed to find syscall ID on line: %v", instruction)
 			}
 			syscallIDEnd := strings.Index(instruction, ", 0(SP)")
 
@@ -181,17 +334,17 @@ func findSyscallIDx86(previouInstructions []string, curPos int) (int64, error) {
 			id, err := strconv.ParseInt(hex, 0, 64)
 
 			if err != nil {
-				return -1, fmt.Errorf("Error parsing hex id: %v", err)
+				return -1, "", fmt.Errorf("Error parsing hex id: %v", err)
 			}
-			return id, nil
+			return id, windowConfidence(i), nil
 		}
 		i++
 		curPos--
 	}
-	return -1, fmt.Errorf("Failed to find syscall ID")
+	return -1, "", fmt.Errorf("Failed to find syscall ID")
 }
 
-func findSyscallIDARM(previouInstructions []string, curPos int) (int64, error) {
+func findSyscallIDARM(previouInstructions []string, curPos int) (int64, string, error) {
 	i := 0
 
 	for i < previousInstructionsBufferSize {
@@ -208,24 +361,131 @@ func findSyscallIDARM(previouInstructions []string, curPos int) (int64, error) {
 			id, err := strconv.ParseInt(hex, 0, 64)
 
 			if err != nil {
-				return -1, fmt.Errorf("Error parsing hex id: %v", err)
+				return -1, "", fmt.Errorf("Error parsing hex id: %v", err)
 			}
-			return id, nil
+			return id, windowConfidence(i), nil
 		}
 		i++
 		curPos--
 	}
-	return -1, fmt.Errorf("Failed to find syscall ID")
+	return -1, "", fmt.Errorf("Failed to find syscall ID")
+}
+
+// findSyscallIDarm64 goes back from the call to the syscall package until it finds the instruction setting
+// R0, the first argument of the register based calling convention, which holds the syscall ID
+func findSyscallIDarm64(previouInstructions []string, curPos int) (int64, string, error) {
+	return findARM64RegisterConstant(previouInstructions, curPos, "R0")
+}
+
+// findRuntimeSyscallIDarm64 goes back from the SVC $0 until it finds the instruction setting R8, which holds
+// the syscall ID on arm64, e.g. on runtime/sys_linux_arm64.s:
+// MOVD $94, R8
+// SVC $0
+func findRuntimeSyscallIDarm64(previouInstructions []string, curPos int) (int64, string, error) {
+	return findARM64RegisterConstant(previouInstructions, curPos, "R8")
+}
+
+// findARM64RegisterConstant goes back until it finds the instruction setting the register and returns the
+// constant it sets. The assembler sets constants with MOVD or MOVW, with ORR $ID, ZR when they're a bitmask
+// immediate, e.g. ORR $56, ZR, R8 for openat, and zero with MOVD ZR.
+func findARM64RegisterConstant(previouInstructions []string, curPos int, register string) (int64, string, error) {
+	i := 0
+
+	for i < previousInstructionsBufferSize {
+		instruction := strings.TrimSpace(previouInstructions[curPos%previousInstructionsBufferSize])
+		fields := strings.Split(instruction, "\t")
+		op := strings.TrimSpace(fields[len(fields)-1])
+		i++
+		curPos--
+
+		if !strings.HasSuffix(op, ", "+register) {
+			continue
+		}
+		mnemonic := strings.SplitN(op, " ", 2)[0]
+		operands := strings.Split(strings.TrimPrefix(op, mnemonic+" "), ", ")
+
+		switch {
+		// comparisons only read the register
+		case mnemonic == "CMP" || mnemonic == "CMN" || mnemonic == "TST":
+			continue
+		case (mnemonic == "MOVD" || mnemonic == "MOVW") && operands[0] == "ZR":
+			return 0, windowConfidence(i - 1), nil
+		case (mnemonic == "MOVD" || mnemonic == "MOVW") && len(operands) == 2 && strings.HasPrefix(operands[0], "$"):
+		case mnemonic == "ORR" && len(operands) == 3 && operands[1] == "ZR" && strings.HasPrefix(operands[0], "$"):
+		default:
+			return -1, "", fmt.Errorf("Syscall ID isn't a constant on line: %v", instruction)
+		}
+
+		id, err := strconv.ParseInt(operands[0][1:], 0, 64)
+		if err != nil {
+			return -1, "", fmt.Errorf("Error parsing hex id: %v", err)
+		}
+		return id, windowConfidence(i - 1), nil
+	}
+	return -1, "", fmt.Errorf("Failed to find syscall ID")
+}
+
+// findSyscallIDppc64le goes back from the call to the syscall package until it finds the instruction setting
+// R3, the first argument of the register based calling convention, which holds the syscall ID
+func findSyscallIDppc64le(previouInstructions []string, curPos int) (int64, string, error) {
+	return findPPC64RegisterConstant(previouInstructions, curPos, "R3")
+}
+
+// findRuntimeSyscallIDppc64le goes back from the SC $0 until it finds the instruction setting R0, which holds
+// the syscall ID on ppc64le, e.g. on runtime/sys_linux_ppc64x.s:
+// MOVD $234,R0
+// SC $0
+func findRuntimeSyscallIDppc64le(previouInstructions []string, curPos int) (int64, string, error) {
+	return findPPC64RegisterConstant(previouInstructions, curPos, "R0")
+}
+
+// findPPC64RegisterConstant goes back until it finds the instruction setting the register to a constant, with
+// MOVD or MOVW, following the copies between registers, which the assembler writes as OR Rx,Rx,Ry
+func findPPC64RegisterConstant(previouInstructions []string, curPos int, register string) (int64, string, error) {
+	i := 0
+
+	for i < previousInstructionsBufferSize {
+		instruction := strings.TrimSpace(previouInstructions[curPos%previousInstructionsBufferSize])
+		fields := strings.Split(instruction, "\t")
+		op := strings.TrimSpace(fields[len(fields)-1])
+		i++
+		curPos--
+
+		// the destination is the last operand, and there's no space after the commas
+		mnemonic := strings.SplitN(op, " ", 2)[0]
+		operands := strings.Split(strings.TrimPrefix(op, mnemonic+" "), ",")
+		if operands[len(operands)-1] != register || strings.HasPrefix(mnemonic, "CMP") {
+			continue
+		}
+
+		switch {
+		case mnemonic == "OR" && len(operands) == 3 && operands[0] == operands[1]:
+			register = operands[0]
+			continue
+		case (mnemonic == "MOVD" || mnemonic == "MOVW") && len(operands) == 2 && strings.HasPrefix(operands[0], "$"):
+		default:
+			return -1, "", fmt.Errorf("Syscall ID isn't a constant on line: %v", instruction)
+		}
+
+		id, err := strconv.ParseInt(operands[0][1:], 0, 64)
+		if err != nil {
+			return -1, "", fmt.Errorf("Error parsing hex id: %v", err)
+		}
+		return id, windowConfidence(i - 1), nil
+	}
+	return -1, "", fmt.Errorf("Failed to find syscall ID")
 }
 
-func getSyscallList(disassambled *os.File, arch specs.Arch) []string {
+// scanDisassembled goes through the disassembled binary looking for syscalls. Besides the syscall IDs,
+// it records which function each one was found in and the functions each function references
+func scanDisassembled(disassambled *os.File, arch specs.Arch) *callGraph {
 
 	scanner := bufio.NewScanner(disassambled)
 
 	// keep a few of the past instructions in a buffer so we can look back and find the syscall ID
 	previousInstructions := make([]string, previousInstructionsBufferSize)
 	lineCount := 0
-	syscalls := getDefaultSyscalls(arch)
+	graph := newCallGraph()
 
 	fmt.Println("Scanning disassembled binary for syscall IDs")
 
@@ -236,76 +496,300 @@ func getSyscallList(disassambled *os.File, arch specs.Arch) []string {
 
 		if len(instruction) > 5 && instruction[0:4] == "TEXT" {
 			currentFunction = parseFunctionName(instruction)
+			graph.addFunction(currentFunction)
+		} else {
+			graph.addReferences(currentFunction, instruction)
+			scanArgSite(graph, arch, currentFunction, instruction, previousInstructions, lineCount)
+			scanStringRef(graph, arch, currentFunction, instruction, previousInstructions, lineCount)
 		}
 
 		// function call to one of the 5 functions from the syscall package
 		if isSyscallPkgCall(arch, instruction) {
-			id, err := findSyscallID(arch, previousInstructions, lineCount)
+			id, confidence, err := findSyscallID(arch, previousInstructions, lineCount)
 			if err != nil {
 				log.Printf("Failed to find syscall ID for line %v: %v, reason: %v\n", lineCount+1, instruction, err)
+				graph.addUnresolved(currentFunction, instruction, previousInstructions, lineCount, err)
 				lineCount++
 				continue
 			}
-			syscalls[id] = true
+			graph.addSyscall(currentFunction, id, instruction, confidence)
 		}
 		// the runtime package doesn't use the functions on the syscall package, instead it uses SYSCALL directly
 		if isRuntimeSyscall(arch, instruction, currentFunction) {
-			id, err := findRuntimeSyscallID(arch, previousInstructions, lineCount)
+			id, confidence, err := findRuntimeSyscallID(arch, previousInstructions, lineCount)
 			if err != nil {
 				log.Printf("Failed to find syscall ID for line %v: \n\t%v\n\treason: %v\n", lineCount+1, instruction, err)
+				graph.addUnresolved(currentFunction, instruction, previousInstructions, lineCount, err)
 				lineCount++
 				continue
 			}
-			syscalls[id] = true
+			graph.addSyscall(currentFunction, id, instruction, confidence)
+		}
+		// user supplied rules, for forks or wrappers of the syscall package and the runtime
+		for i := range customRules {
+			rule := &customRules[i]
+			if !rule.matches(arch, instruction, currentFunction) {
+				continue
+			}
+			id, confidence, err := rule.findID(previousInstructions, lineCount)
+			if err != nil {
+				log.Printf("Failed to find syscall ID for line %v: %v, reason: %v\n", lineCount+1, instruction, err)
+				graph.addUnresolved(currentFunction, instruction, previousInstructions, lineCount, err)
+				continue
+			}
+			graph.addSyscall(currentFunction, id, instruction, confidence)
 		}
 		lineCount++
 	}
 
-	syscallsList := make([]string, len(syscalls))
-	i := 0
+	return graph
+}
 
-	for id := range syscalls {
-		name, ok := syscallIDtoName[arch][id]
-		if !ok {
-			fmt.Printf("Sycall ID %v not available on the ID->name map\n", id)
-		} else {
-			syscallsList[i] = name
-			i++
+// getSyscallList returns the sorted names of the syscalls going in the profile, given the detected syscall IDs
+func getSyscallList(ids map[int64]bool, arch specs.Arch) []string {
+	return sortedNames(syscallSources(ids, arch))
+}
+
+// scanBinary disassembles the binary, with extra go tool objdump arguments, and scans it
+func scanBinary(binaryPath string, f *elf.File, arch specs.Arch, objdumpArgs ...string) *callGraph {
+	var disassambled *os.File
+	if isMIPS(arch) {
+		disassambled = disassambleMIPS(binaryPath, objdumpArgs...)
+	} else {
+		disassambled = disassamble(binaryPath, objdumpArgs...)
+	}
+	defer disassambled.Close()
+	defer os.Remove("disassembled.asm")
+
+	graph := scanDisassembled(disassambled, arch)
+	graph.resolvePathConstants(f)
+	return graph
+}
+
+// analyzeBinary disassembles the Go binary at binaryPath and scans it for syscalls
+func analyzeBinary(binaryPath string) (*callGraph, specs.Arch) {
+	remoteBinarySHA256 = ""
+	if isRemoteBinary(binaryPath) {
+		analyzedBinary = binaryPath
+		downloaded := fetchRemoteBinary(binaryPath)
+		defer os.Remove(downloaded)
+		binaryPath = downloaded
+	} else {
+		binaryPath = resolveBazelBinary(binaryPath)
+		analyzedBinary = binaryPath
+	}
+
+	if packer := detectPacker(binaryPath); packer != "" {
+		if !*unpack {
+			fatalf("%v seems to be packed with %v, analyzing it would give an incomplete profile. Use -unpack to unpack it before the analysis", binaryPath, packer)
 		}
+		unpacked := unpackBinary(binaryPath, packer)
+		defer os.Remove(unpacked)
+		binaryPath = unpacked
 	}
 
-	sort.Strings(syscallsList)
+	f := openElf(binaryPath)
+
+	// go tool objdump needs the symbol table, which distros strip and ship separately
+	if f.Section(".symtab") == nil {
+		debugFile, downloaded := findDebugFile(binaryPath, f)
+		if debugFile == "" {
+			fatalf("%v is stripped and no separate debug info was found for it", binaryPath)
+		}
+		if downloaded {
+			defer os.Remove(debugFile)
+		}
+		unstripped := mergeDebugInfo(binaryPath, debugFile)
+		defer os.Remove(unstripped)
+		binaryPath = unstripped
+		f = openElf(binaryPath)
+	}
 
-	return syscallsList
+	if !isGoBinary(f) {
+		fmt.Println(binaryPath, "doesn't seems to be a Go binary")
+		os.Exit(1)
+	}
+
+	arch := getArch(f)
+	// in cross-compilation pipelines, a build for the wrong arch would get a profile for the wrong arch
+	if expected, ok := archByName(*expectedArch); ok && expected != arch {
+		fatalf("%v is built for %v, expected %v", binaryPath, arch, expected)
+	}
+	binaryByteOrder = f.ByteOrder
+	dynamicBinary = isDynamic(f)
+	binaryGodebug = readBinaryGodebug(binaryPath)
+	if arch == specs.ArchARM {
+		armText = f.Section(".text")
+	}
+
+	var graph *callGraph
+	if *incremental {
+		graph = scanIncrementally(binaryPath, f, arch)
+	} else {
+		graph = scanBinary(binaryPath, f, arch)
+	}
+	raceBuild = isRaceBuild(graph)
+	graph.addLowLevelSyscalls(arch)
+	graph.ignoreFunctions()
+	if lines := loadSourceLines(f); lines != nil {
+		graph.resolveSourceLines(lines)
+	}
+	return graph, arch
 }
 
+// subcommands, selected by the first argument after the global flags
+var commands = map[string]func(args []string){}
+
 func main() {
+	flag.Var(&addedSyscalls, "add", "syscall to add to the profile even if not detected, can be repeated")
+	flag.Var(&includedFragments, "include", "profile fragment whose rules are merged into the generated profile, can be repeated")
+	flag.Var(&tracedSyscalls, "trace", "syscall to mark with SCMP_ACT_TRACE, for a ptrace supervisor to inspect, can be repeated")
+	flag.Var(&extraSyscallPackages, "syscall-package", "import path of a fork or copy of the syscall or x/sys/unix package, handled like them, can be repeated")
+	flag.Var(&entryFuncs, "entry-func", "only consider code reachable from this function (name or glob), can be repeated")
+	flag.Var(&seccompFlags, "seccomp-flag", "seccomp(2) flag runtimes load the filter with, e.g. SECCOMP_FILTER_FLAG_SPEC_ALLOW, can be repeated")
+	// the formatters are only all registered once the init functions ran
+	flag.Lookup("format").Usage = "comma separated output formats: " + formatNames()
 	flag.Parse()
+	applyEnvironment(flag.CommandLine)
+	// filled in as the run goes, so that failed runs get a summary too
+	summary = &runSummary{}
 
-	if len(flag.Args()) < 2 {
+	for _, name := range selectedFormats() {
+		if _, ok := formatters[name]; !ok {
+			fatalf("Unknown format %v, available formats: %v", name, formatNames())
+		}
+	}
+	if *ruleStyle != ruleStyleSingle && *ruleStyle != ruleStyleGrouped && *ruleStyle != ruleStyleCategory {
+		fatalf("Invalid -rule-style %v, use single, grouped or category", *ruleStyle)
+	}
+	if _, ok := confidenceRanks[*minConfidence]; !ok {
+		fatalf("Invalid -min-confidence %v, use exact, derived, heuristic or default", *minConfidence)
+	}
+	if _, ok := archByName(*expectedArch); *expectedArch != "" && !ok {
+		fatalf("Invalid -arch %v, use amd64, arm64, arm, 386, ppc64le, mips, mipsle, mips64 or mips64le", *expectedArch)
+	}
+	if *defaultErrnoRet < -1 || *defaultErrnoRet > maxErrno {
+		fatalf("Invalid -default-errno-ret %v, use an errno between 0 and %v", *defaultErrnoRet, maxErrno)
+	}
+	if *profileMode != modeEnforce && *profileMode != modeAudit {
+		fatalf("Invalid -mode %v, use enforce or audit", *profileMode)
+	}
+	if action, ok := parseDefaultAction(*defaultAction); !ok {
+		fatalf("Invalid -default-action %v, use errno, kill, kill_process, trap or log", *defaultAction)
+	} else if auditMode() && action != specs.ActErrno && action != specs.ActLog {
+		fatalf("-mode audit profiles log the syscalls they don't allow, -default-action %v can't be used with it", *defaultAction)
+	}
+	if action := profileDefaultAction(); *defaultErrnoRet != -1 && action != specs.ActErrno {
+		fatalf("-default-errno-ret needs the errno default action, not %v", action)
+	}
+	if *actionsPath != "" {
+		syscallActions = loadSyscallActions(*actionsPath)
+	}
+	for _, f := range seccompFlags {
+		if !validFlags[specs.LinuxSeccompFlag(f)] {
+			fatalf("Invalid -seccomp-flag %v, use SECCOMP_FILTER_FLAG_LOG, SECCOMP_FILTER_FLAG_SPEC_ALLOW or SECCOMP_FILTER_FLAG_WAIT_KILLABLE_RECV", f)
+		}
+	}
+	if *listenerMetadata != "" && *listenerPath == "" {
+		fatalf("-listener-metadata needs -listener-path")
+	}
+	if *jsonIndent < 0 {
+		fatalf("Invalid -indent %v", *jsonIndent)
+	}
+	if _, err := parseMode(*profileFileMode); err != nil {
+		fatalf("Invalid -file-mode: %v", err)
+	}
+	if _, _, err := parseOwner(*profileOwner); err != nil {
+		fatalf("Invalid -owner: %v", err)
+	}
+	verbose = *verboseFlag || *veryVerbose
+	if *rulesPath != "" {
+		customRules = loadRules(*rulesPath)
+	}
+	ignorePatterns = loadIgnoreFile(*ignoreFile)
+	if *syscallTablePath != "" {
+		loadSyscallTable(*syscallTablePath)
+	}
+	if *basePath != "" {
+		baseSyscalls = loadAllowedSyscalls(*basePath)
+	}
+	if *kconfigPath != "" {
+		kernelConfig = loadKernelConfig(*kconfigPath)
+	}
+
+	if len(flag.Args()) > 0 {
+		if command, ok := commands[flag.Args()[0]]; ok {
+			command(flag.Args()[1:])
+			return
+		}
+	}
+
+	// with -out-dir the profile can be named after the binary
+	if len(flag.Args()) < 2 && !(len(flag.Args()) == 1 && (*outDir != "" || *outputPath != "")) {
 		fmt.Println("Usage: go2seccomp /path/to/binary /path/to/profile.json")
+		fmt.Println("       go2seccomp -out-dir dir /path/to/binary")
 		os.Exit(1)
 	}
 
-	binaryPath := flag.Args()[0]
-	profilePath := flag.Args()[1]
+	binaryPath := bazelWorkingPath(flag.Args()[0])
+	profilePath := filepath.Base(binaryPath) + ".json"
+	if isBazelLabel(binaryPath) {
+		profilePath = bazelLabelName(binaryPath) + ".json"
+	}
+	if isRemoteBinary(binaryPath) {
+		profilePath = remoteBinaryName(binaryPath) + ".json"
+	}
+	if len(flag.Args()) > 1 {
+		profilePath = bazelWorkingPath(flag.Args()[1])
+	}
+	if *outputPath != "" {
+		profilePath = bazelWorkingPath(*outputPath)
+	}
+	streamProfile(profilePath)
+	*outDir = bazelWorkingPath(*outDir)
+	*reportPath = bazelWorkingPath(*reportPath)
+	summary.Binary, summary.Profile = binaryPath, profilePath
 
-	f := openElf(binaryPath)
+	graph, arch := analyzeBinary(binaryPath)
+	summary.Arch = arch
 
-	if !isGoBinary(f) {
-		fmt.Println(binaryPath, "doesn't seems to be a Go binary")
-		os.Exit(1)
+	if *entrypoints != "" {
+		writeEntrypointProfiles(graph, arch, parseEntrypoints(*entrypoints), profilePath)
+		return
 	}
 
-	arch := getArch(f)
-
-	disassambled := disassamble(binaryPath)
-	defer disassambled.Close()
-	defer os.Remove("disassembled.asm")
+	ids := graph.allSyscalls()
+	// functions the profile covers, nil for all of them
+	var functions map[string]bool
+	if len(entryFuncs) > 0 {
+		functions = graph.reachableFunctions(resolveEntryFuncs(graph, entryFuncs))
+		ids = graph.syscallsIn(functions)
+	}
 
-	syscallsList := getSyscallList(disassambled, arch)
+	reportRaceBuild()
+	reportGodebug()
+	detectSelfSandbox(graph, arch, ids, functions)
+	sources := syscallSources(ids, arch)
+	syscallsList := sortedNames(sources)
+	recordProvenance(graph, arch, syscallsList, sources, functions)
 
 	fmt.Printf("Syscalls detected (total: %v): %v\n", len(syscallsList), syscallsList)
+	reportExecMappings(graph, arch, functions)
+	reportSocketFamilies(graph, arch, functions)
+	recordExecFunctions(graph, functions)
+	recordPathConstants(graph, functions)
+	namespaces := namespaceUses(graph, arch, ids, functions)
+	reportNamespaces(namespaces)
+	syscallsList = reviewIfInteractive(syscallsList)
+	sources = sourcesOf(sources, syscallsList)
+	profileSyscalls = syscallsList
 
 	writeProfile(syscallsList, arch, profilePath)
+
+	if *reportPath != "" {
+		writeReport(buildReport(binaryPath, graph, arch, sources, ids, functions), *reportPath)
+	}
+	summary = newRunSummary(binaryPath, profilePath, graph, arch, sources, functions)
+	checkNewNamespaces(namespaces)
+	exitWithSummary(0)
 }
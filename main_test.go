@@ -0,0 +1,88 @@
+package main
+
+import "testing"
+
+// instructionWindow puts the objdump lines, the last one being the syscall instruction, in a ring buffer
+// like the scanner's and returns it with the position of the last line
+func instructionWindow(lines ...string) ([]string, int) {
+	buffer := make([]string, previousInstructionsBufferSize)
+	curPos := previousInstructionsBufferSize
+	for i, line := range lines {
+		buffer[(curPos+i)%previousInstructionsBufferSize] = line
+	}
+	return buffer, curPos + len(lines) - 1
+}
+
+func TestFindRuntimeSyscallIDx86(t *testing.T) {
+	tests := []struct {
+		name       string
+		lines      []string
+		id         int64
+		confidence string
+		err        bool
+	}{
+		{
+			name: "constant",
+			lines: []string{
+				"  sys_linux_386.s:96\t0x8098f80\t\tb8e0000000\t\tMOVL $0xe0, AX",
+				"  sys_linux_386.s:97\t0x8098f85\t\t8b5c2404\t\tMOVL 0x4(SP), BX",
+				"  sys_linux_386.s:98\t0x8098f89\t\tcd80\t\t\tINT $0x80",
+			},
+			id:         0xe0,
+			confidence: confidenceExact,
+		},
+		{
+			name: "AX copied before being set",
+			lines: []string{
+				"  sys_linux_386.s:10\t0x8098f00\t\tb814000000\t\tMOVL $0x14, AX",
+				"  sys_linux_386.s:11\t0x8098f05\t\tcd80\t\t\tINT $0x80",
+				"  sys_linux_386.s:12\t0x8098f07\t\t89c3\t\t\tMOVL AX, BX",
+				"  sys_linux_386.s:13\t0x8098f09\t\tb8e0000000\t\tMOVL $0xe0, AX",
+				"  sys_linux_386.s:14\t0x8098f0e\t\tcd80\t\t\tINT $0x80",
+			},
+			id:         0xe0,
+			confidence: confidenceExact,
+		},
+		{
+			name: "zeroed",
+			lines: []string{
+				"  sys_linux_386.s:20\t0x8098f20\t\t31c0\t\t\tXORL AX, AX",
+				"  sys_linux_386.s:21\t0x8098f22\t\tcd80\t\t\tINT $0x80",
+			},
+			id:         0,
+			confidence: confidenceExact,
+		},
+		{
+			name: "not a constant",
+			lines: []string{
+				"  sys_linux_386.s:30\t0x8098f30\t\t8b442404\t\tMOVL 0x4(SP), AX",
+				"  sys_linux_386.s:31\t0x8098f34\t\tcd80\t\t\tINT $0x80",
+			},
+			err: true,
+		},
+		{
+			name: "no match in the window",
+			lines: []string{
+				"  sys_linux_386.s:40\t0x8098f40\t\t8b5c2404\t\tMOVL 0x4(SP), BX",
+				"  sys_linux_386.s:41\t0x8098f44\t\t8b4c2408\t\tMOVL 0x8(SP), CX",
+				"  sys_linux_386.s:42\t0x8098f48\t\tcd80\t\t\tINT $0x80",
+			},
+			err: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			buffer, curPos := instructionWindow(test.lines...)
+			id, confidence, err := findRuntimeSyscallIDx86(buffer, curPos)
+			if test.err {
+				if err == nil {
+					t.Errorf("findRuntimeSyscallIDx86() = %v, want an error", id)
+				}
+				return
+			}
+			if err != nil || id != test.id || confidence != test.confidence {
+				t.Errorf("findRuntimeSyscallIDx86() = %v, %v, %v, want %#x, %v", id, confidence, err, test.id, test.confidence)
+			}
+		})
+	}
+}
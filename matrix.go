@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+func init() {
+	commands["matrix"] = matrixCommand
+}
+
+// buildVariant is one combination of the toggles of the matrix
+type buildVariant struct {
+	Name string   `json:"name"`
+	Tags []string `json:"tags,omitempty"`
+	Env  []string `json:"env,omitempty"`
+	// syscalls of the variant, and the ones it adds and lacks compared to the build without toggles
+	Syscalls []string `json:"syscalls"`
+	Added    []string `json:"added,omitempty"`
+	Removed  []string `json:"removed,omitempty"`
+}
+
+// matrixVariants returns every combination of the toggles, the build without any of them first.
+// Toggles with a = are environment variables, like CGO_ENABLED=0, the others build tags.
+func matrixVariants(toggles []string) []buildVariant {
+	variants := make([]buildVariant, 0, 1<<uint(len(toggles)))
+	for mask := 0; mask < 1<<uint(len(toggles)); mask++ {
+		var v buildVariant
+		var names []string
+		for i, toggle := range toggles {
+			if mask&(1<<uint(i)) == 0 {
+				continue
+			}
+			names = append(names, toggle)
+			if strings.Contains(toggle, "=") {
+				v.Env = append(v.Env, toggle)
+			} else {
+				v.Tags = append(v.Tags, toggle)
+			}
+		}
+		v.Name = "default"
+		if len(names) > 0 {
+			v.Name = strings.Join(names, "+")
+		}
+		variants = append(variants, v)
+	}
+	return variants
+}
+
+// matrixCommand builds the package with every combination of the given build tags and environment toggles,
+// analyzes each variant and writes the profile with the union of their syscalls, and how each one differs
+func matrixCommand(args []string) {
+	fs := flag.NewFlagSet("matrix", flag.ExitOnError)
+	var toggles stringList
+	fs.Var(&toggles, "toggle", "build tag (netgo) or environment variable (CGO_ENABLED=0) to build with and without, can be repeated")
+	output := fs.String("o", "", "path of the profile with the syscalls of every variant, named after the package by default")
+	parseFlags(fs, args)
+
+	if fs.NArg() != 1 || len(toggles) == 0 {
+		fmt.Println("Usage: go2seccomp matrix -toggle netgo -toggle osusergo [-o profile.json] ./cmd/app")
+		os.Exit(1)
+	}
+	pkg := fs.Arg(0)
+	name := path.Base(filepath.ToSlash(pkg))
+	if strings.HasPrefix(pkg, ".") {
+		if abs, err := filepath.Abs(pkg); err == nil {
+			name = filepath.Base(abs)
+		}
+	}
+	if *output == "" {
+		*output = name + ".json"
+	}
+
+	buildDir, err := ioutil.TempDir("", "go2seccomp")
+	if err != nil {
+		fatalf("Failed to create build directory: %v", err)
+	}
+	defer os.RemoveAll(buildDir)
+
+	variants := matrixVariants(toggles)
+	union := make(map[string]bool)
+	// the variants are all built for the same arch
+	var arch specs.Arch
+	for i := range variants {
+		v := &variants[i]
+		binaryPath := filepath.Join(buildDir, fmt.Sprintf("%v-%v", name, i))
+		fmt.Printf("Building %v (%v)\n", pkg, v.Name)
+		build := exec.Command("go", "build", "-tags", strings.Join(v.Tags, ","), "-o", binaryPath, pkg)
+		build.Env = append(append(os.Environ(), "GOOS=linux"), v.Env...)
+		build.Stdout, build.Stderr = os.Stdout, os.Stderr
+		if err := build.Run(); err != nil {
+			fatalf("Failed to build %v (%v): %v", pkg, v.Name, err)
+		}
+
+		var graph *callGraph
+		graph, arch = analyzeBinary(binaryPath)
+		v.Syscalls = getSyscallList(graph.allSyscalls(), arch)
+		for _, syscall := range v.Syscalls {
+			union[syscall] = true
+		}
+	}
+
+	// the differences are against the build without toggles
+	base := make(map[string]bool)
+	for _, syscall := range variants[0].Syscalls {
+		base[syscall] = true
+	}
+	for i := range variants[1:] {
+		v := &variants[i+1]
+		in := make(map[string]bool)
+		for _, syscall := range v.Syscalls {
+			in[syscall] = true
+			if !base[syscall] {
+				v.Added = append(v.Added, syscall)
+			}
+		}
+		for _, syscall := range variants[0].Syscalls {
+			if !in[syscall] {
+				v.Removed = append(v.Removed, syscall)
+			}
+		}
+	}
+
+	for _, v := range variants {
+		fmt.Printf("%v (total: %v)", v.Name, len(v.Syscalls))
+		if len(v.Added) > 0 {
+			fmt.Printf(" +%v", v.Added)
+		}
+		if len(v.Removed) > 0 {
+			fmt.Printf(" -%v", v.Removed)
+		}
+		fmt.Println()
+	}
+
+	syscallsList := make([]string, 0, len(union))
+	for syscall := range union {
+		syscallsList = append(syscallsList, syscall)
+	}
+	sort.Strings(syscallsList)
+	fmt.Printf("Syscalls of every variant (total: %v): %v\n", len(syscallsList), syscallsList)
+	writeProfile(syscallsList, arch, *output)
+
+	variantsPath := strings.TrimSuffix(*output, filepath.Ext(*output)) + ".variants.json"
+	if *outDir != "" {
+		variantsPath = filepath.Join(*outDir, filepath.Base(variantsPath))
+	}
+	data, err := json.MarshalIndent(variants, "", "    ")
+	if err != nil {
+		fatalf("Failed to encode the variants: %v", err)
+	}
+	if err := ioutil.WriteFile(variantsPath, append(data, '\n'), 0644); err != nil {
+		fatalf("Failed to write %v: %v", variantsPath, err)
+	}
+	fmt.Printf("Saved the variants at %v\n", variantsPath)
+}
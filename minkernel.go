@@ -0,0 +1,187 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+var minKernel = flag.String("min-kernel", "", "oldest kernel version the profile has to work on, e.g. 4.19, adding the fallbacks of newer syscalls")
+var pruneFallbacks = flag.Bool("prune-fallbacks", false, "leave out the syscalls only added as fallbacks of newer ones, for fleets whose kernels all have the newer ones")
+
+// the syscalls added only as fallbacks, with the newer syscalls they're fallbacks of
+var fallbackOnly map[string][]string
+
+// addFallbackOnly records that fallback was added for the newer syscall
+func addFallbackOnly(fallback, newer string) {
+	if fallbackOnly == nil {
+		fallbackOnly = make(map[string][]string)
+	}
+	fallbackOnly[fallback] = append(fallbackOnly[fallback], newer)
+}
+
+// syscalls programs fall back to when a newer one returns ENOSYS on older kernels
+var syscallFallbacks = map[string][]string{
+	"clone3":            {"clone"},
+	"faccessat2":        {"faccessat", "access"},
+	"openat2":           {"openat", "open"},
+	"fchmodat2":         {"fchmodat", "chmod"},
+	"renameat2":         {"renameat", "rename"},
+	"execveat":          {"execve"},
+	"statx":             {"newfstatat", "fstatat64", "fstat", "fstat64", "stat", "stat64", "lstat", "lstat64"},
+	"epoll_pwait2":      {"epoll_pwait", "epoll_wait"},
+	"close_range":       {"close"},
+	"preadv2":           {"preadv"},
+	"pwritev2":          {"pwritev"},
+	"mlock2":            {"mlock"},
+	"getrandom":         {"openat", "open", "read"},
+	"copy_file_range":   {"read", "write", "pread64", "pwrite64"},
+	"pidfd_open":        {"wait4", "waitid"},
+	"pidfd_send_signal": {"kill"},
+	"futex_waitv":       {"futex"},
+	"futex_wait":        {"futex"},
+	"futex_wake":        {"futex"},
+	"futex_requeue":     {"futex"},
+}
+
+// actions that let the syscall run, so it has to exist in the kernel
+var runningActions = map[specs.LinuxSeccompAction]bool{
+	specs.ActAllow:  true,
+	specs.ActLog:    true,
+	specs.ActTrace:  true,
+	specs.ActNotify: true,
+}
+
+// profileMinKernel returns the oldest kernel version the profile works on: it supports every action and flag
+// used, and every syscall the profile lets run, unless the profile also allows its fallbacks
+func profileMinKernel(profile specs.LinuxSeccomp) kernelVersion {
+	allowed := make(map[string]bool)
+	for _, rule := range profile.Syscalls {
+		if runningActions[rule.Action] {
+			for _, name := range rule.Names {
+				allowed[name] = true
+			}
+		}
+	}
+
+	min := seccompFilterVersion
+	raise := func(v kernelVersion) {
+		if min.less(v) {
+			min = v
+		}
+	}
+	raise(actionVersions[profile.DefaultAction])
+	for _, f := range profile.Flags {
+		raise(flagVersions[f])
+	}
+	for _, rule := range profile.Syscalls {
+		raise(actionVersions[rule.Action])
+	}
+
+	for name := range allowed {
+		v, ok := syscallVersions[name]
+		if !ok || hasFallback(name, allowed) {
+			continue
+		}
+		raise(v)
+	}
+	return min
+}
+
+// hasFallback tells if any fallback of the syscall is allowed
+func hasFallback(name string, allowed map[string]bool) bool {
+	for _, fallback := range syscallFallbacks[name] {
+		if allowed[fallback] {
+			return true
+		}
+	}
+	return false
+}
+
+// applyMinKernel adds the fallbacks of the syscalls newer than -min-kernel that exist on the arch,
+// warning about the ones without fallbacks
+func applyMinKernel(syscallsList []string, arch specs.Arch) []string {
+	if *minKernel == "" {
+		return syscallsList
+	}
+	version, err := parseKernelVersion(*minKernel)
+	if err != nil {
+		fatalf("Invalid -min-kernel: %v", err)
+	}
+
+	inList := make(map[string]bool)
+	for _, name := range syscallsList {
+		inList[name] = true
+	}
+	archNames := make(map[string]bool)
+	for _, name := range syscallIDtoName[arch] {
+		archNames[name] = true
+	}
+
+	for _, name := range syscallsList {
+		v, ok := syscallVersions[name]
+		if !ok || !version.less(v) {
+			continue
+		}
+		var added []string
+		for _, fallback := range syscallFallbacks[name] {
+			if !archNames[fallback] {
+				continue
+			}
+			if !inList[fallback] {
+				inList[fallback] = true
+				added = append(added, fallback)
+				addFallbackOnly(fallback, name)
+			} else if _, ok := fallbackOnly[fallback]; ok {
+				addFallbackOnly(fallback, name)
+			}
+		}
+		switch {
+		case len(added) > 0:
+			fmt.Printf("%v needs kernel %v, adding its fallbacks for %v: %v\n", name, v, version, added)
+		case len(syscallFallbacks[name]) == 0:
+			warnf("%v needs kernel %v, newer than -min-kernel %v, and has no fallback", name, v, version)
+		}
+	}
+
+	list := make([]string, 0, len(inList))
+	for name := range inList {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// applyPruneFallbacks lists the syscalls only added as fallbacks, leaving them out with -prune-fallbacks
+func applyPruneFallbacks(syscallsList []string) []string {
+	if len(fallbackOnly) == 0 {
+		return syscallsList
+	}
+	names := make([]string, 0, len(fallbackOnly))
+	for name := range fallbackOnly {
+		sort.Strings(fallbackOnly[name])
+		fallbackOnly[name] = uniqueStrings(fallbackOnly[name])
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Println("Syscalls only added as fallbacks, for kernels without the newer ones:")
+	for _, name := range names {
+		fmt.Printf("    %v (for %v)\n", name, strings.Join(fallbackOnly[name], ", "))
+	}
+	if !*pruneFallbacks {
+		fmt.Println("Leave them out with -prune-fallbacks when every kernel has the newer ones")
+		return syscallsList
+	}
+
+	var list []string
+	for _, name := range syscallsList {
+		if _, ok := fallbackOnly[name]; !ok {
+			list = append(list, name)
+		}
+	}
+	fmt.Printf("Left out with -prune-fallbacks: %v\n", names)
+	return list
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+// go tool objdump can't disassemble MIPS, llvm-objdump can
+const mipsObjdump = "llvm-objdump"
+
+// llvm-objdump lines: function headers, source locations with -l, and instructions
+var (
+	llvmFunctionRegexp    = regexp.MustCompile(`^[0-9a-f]+ <(.+)>:$`)
+	llvmLocationRegexp    = regexp.MustCompile(`^; (\S+:\d+)$`)
+	llvmInstructionRegexp = regexp.MustCompile(`^\s*([0-9a-f]+):((?: [0-9a-f]{2})+)\s+(\S+)(?:\s+(.*))?$`)
+	// the symbol llvm-objdump guesses for immediates and branch targets, <go.go+0xfa1>
+	llvmAnnotationRegexp = regexp.MustCompile(`\s*<[^>]*>$`)
+)
+
+func isMIPS(arch specs.Arch) bool {
+	return arch == specs.ArchMIPS || arch == specs.ArchMIPSEL || arch == specs.ArchMIPS64 || arch == specs.ArchMIPSEL64
+}
+
+// disassambleMIPS disassembles the binary with llvm-objdump and writes it in the layout of go tool objdump, so
+// it's scanned the same way: TEXT lines for the functions, the instructions after their location and address,
+// and the calls as JAL function(SB). Like go tool objdump, -s only disassembles the functions matching the regexp.
+func disassambleMIPS(binaryPath string, args ...string) *os.File {
+	var symbols *regexp.Regexp
+	for i := 0; i+1 < len(args); i++ {
+		if args[i] == "-s" {
+			symbols = regexp.MustCompile(args[i+1])
+		}
+	}
+
+	disassambled, err := os.Create("disassembled.asm")
+	if err != nil {
+		fatalf("Failed to disassembling output file, reason: %v", err)
+	}
+
+	fmt.Printf("Using %v to disassemble %v\n", mipsObjdump, binaryPath)
+	cmd := exec.Command(mipsObjdump, "-d", "-l", binaryPath)
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		fatalf("Couldn't run %v: %v\n", mipsObjdump, err)
+	}
+	if err := cmd.Start(); err != nil {
+		fatalf("Couldn't run %v: %v\n", mipsObjdump, err)
+	}
+
+	w := bufio.NewWriter(disassambled)
+	scanner := bufio.NewScanner(out)
+	location, skip := "?", false
+	for scanner.Scan() {
+		line := scanner.Text()
+		if m := llvmFunctionRegexp.FindStringSubmatch(line); m != nil {
+			skip = symbols != nil && !symbols.MatchString(m[1])
+			if !skip {
+				fmt.Fprintf(w, "TEXT %v(SB)\n", m[1])
+			}
+			continue
+		}
+		if m := llvmLocationRegexp.FindStringSubmatch(line); m != nil {
+			location = filepath.Base(m[1])
+			continue
+		}
+		m := llvmInstructionRegexp.FindStringSubmatch(line)
+		if m == nil || skip {
+			continue
+		}
+		fmt.Fprintf(w, "  %v\t0x%v\t\t%v\t\t%v\n", location, m[1], strings.Replace(m[2], " ", "", -1), mipsInstruction(m[3], m[4]))
+	}
+	if err := scanner.Err(); err != nil {
+		fatalf("Couldn't read the output of %v: %v\n", mipsObjdump, err)
+	}
+	if err := cmd.Wait(); err != nil {
+		fatalf("Couldn't run %v: %v\n", mipsObjdump, err)
+	}
+	if err := w.Flush(); err != nil {
+		fatalf("Failed to write the disassembled binary: %v", err)
+	}
+
+	// Point to the beginning of the disassembled binary to start looking for syscalls
+	disassambled.Seek(0, 0)
+	return disassambled
+}
+
+// mipsInstruction returns the instruction without the symbols llvm-objdump guesses for immediates, calls
+// written like go tool objdump writes them
+func mipsInstruction(mnemonic, operands string) string {
+	if mnemonic == "jal" || mnemonic == "bal" {
+		if start := strings.Index(operands, "<"); start != -1 && strings.HasSuffix(operands, ">") {
+			target := operands[start+1 : len(operands)-1]
+			if !strings.Contains(target, "+") {
+				return "JAL " + target + "(SB)"
+			}
+		}
+	}
+	operands = llvmAnnotationRegexp.ReplaceAllString(operands, "")
+	if operands == "" {
+		return mnemonic
+	}
+	return mnemonic + "\t" + operands
+}
+
+// findSyscallIDmips goes back from the call to the syscall package until it finds the store of the syscall ID,
+// the first argument on the stack after the return address, and then the constant the stored register is set to:
+// addiu $1, $zero, 4006
+// sw $1, 4($sp)
+// jal syscall.Syscall(SB)
+func findSyscallIDmips(arch specs.Arch, previouInstructions []string, curPos int) (int64, string, error) {
+	slot := "4($sp)"
+	if arch == specs.ArchMIPS64 || arch == specs.ArchMIPSEL64 {
+		slot = "8($sp)"
+	}
+
+	i := 0
+	for i < previousInstructionsBufferSize {
+		mnemonic, operands := mipsOperands(previouInstructions[curPos%previousInstructionsBufferSize])
+		i++
+		curPos--
+
+		if (mnemonic == "sw" || mnemonic == "sd") && len(operands) == 2 && operands[1] == slot {
+			return findMIPSRegisterConstant(previouInstructions, curPos, operands[0], i)
+		}
+	}
+	return -1, "", fmt.Errorf("Failed to find syscall ID")
+}
+
+// findRuntimeSyscallIDmips goes back from the syscall until it finds the instruction setting $2 (v0), which
+// holds the syscall ID, numbered from 4000 on O32 and from 5000 on N64, e.g. on runtime/sys_linux_mipsx.s:
+// addiu $2, $zero, 4246
+// syscall
+func findRuntimeSyscallIDmips(previouInstructions []string, curPos int) (int64, string, error) {
+	return findMIPSRegisterConstant(previouInstructions, curPos, "$2", 0)
+}
+
+// findMIPSRegisterConstant goes back until it finds the instruction setting the register to a constant, with
+// addiu, daddiu or ori from $zero, following the copies between registers. i is how far back the search starts.
+func findMIPSRegisterConstant(previouInstructions []string, curPos int, register string, i int) (int64, string, error) {
+	for i < previousInstructionsBufferSize {
+		if register == "$zero" {
+			return 0, windowConfidence(i), nil
+		}
+		instruction := previouInstructions[curPos%previousInstructionsBufferSize]
+		mnemonic, operands := mipsOperands(instruction)
+		i++
+		curPos--
+
+		// stores, branches and jumps don't write their first operand
+		if len(operands) == 0 || operands[0] != register || mnemonic == "sw" || mnemonic == "sd" ||
+			strings.HasPrefix(mnemonic, "b") || strings.HasPrefix(mnemonic, "j") || strings.HasPrefix(mnemonic, "t") {
+			continue
+		}
+
+		switch {
+		case (mnemonic == "addiu" || mnemonic == "daddiu" || mnemonic == "ori") && len(operands) == 3 && operands[1] == "$zero":
+			id, err := strconv.ParseInt(operands[2], 0, 64)
+			if err != nil {
+				return -1, "", fmt.Errorf("Error parsing hex id: %v", err)
+			}
+			return id, windowConfidence(i - 1), nil
+		case mnemonic == "move" && len(operands) == 2:
+			register = operands[1]
+		// or, addu and sllv with $zero copy the other register, or zero when both are $zero
+		case (mnemonic == "or" || mnemonic == "addu" || mnemonic == "daddu") && len(operands) == 3 && operands[1] == "$zero":
+			register = operands[2]
+		case (mnemonic == "or" || mnemonic == "addu" || mnemonic == "daddu") && len(operands) == 3 && operands[2] == "$zero":
+			register = operands[1]
+		case mnemonic == "sllv" && len(operands) == 3 && operands[2] == "$zero":
+			register = operands[1]
+		default:
+			return -1, "", fmt.Errorf("Syscall ID isn't a constant on line: %v", strings.TrimSpace(instruction))
+		}
+	}
+	return -1, "", fmt.Errorf("Failed to find syscall ID")
+}
+
+// mipsOperands splits the instruction of a disassambleMIPS line into its mnemonic and operands
+func mipsOperands(instruction string) (string, []string) {
+	fields := strings.Split(strings.TrimSpace(instruction), "\t")
+	if len(fields) < 2 {
+		return "", nil
+	}
+	if fields[len(fields)-2] == "" {
+		// no operands, the last field is the mnemonic
+		return fields[len(fields)-1], nil
+	}
+	return fields[len(fields)-2], strings.Split(fields[len(fields)-1], ", ")
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+func init() {
+	commands["monorepo"] = monorepoCommand
+}
+
+// monorepoCommand builds every main package matching the patterns and writes a profile for each one,
+// the profile of the syscalls they all share, and the delta of each one on top of it
+func monorepoCommand(args []string) {
+	fs := flag.NewFlagSet("monorepo", flag.ExitOnError)
+	fs.StringVar(outDir, "out-dir", "profiles", "directory to write the profiles to")
+	commonName := fs.String("common", "common", "name of the profile with the syscalls every binary uses")
+	parseFlags(fs, args)
+
+	if fs.NArg() < 1 {
+		fmt.Println("Usage: go2seccomp monorepo [-out-dir profiles] ./cmd/...")
+		os.Exit(1)
+	}
+
+	cmd := exec.Command("go", append([]string{"list", "-f", "{{if eq .Name \"main\"}}{{.ImportPath}}{{end}}"}, fs.Args()...)...)
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		fatalf("Failed to list packages: %v", err)
+	}
+	packages := strings.Fields(string(out))
+	if len(packages) == 0 {
+		fatalf("No main packages match %v", strings.Join(fs.Args(), " "))
+	}
+
+	buildDir, err := ioutil.TempDir("", "go2seccomp")
+	if err != nil {
+		fatalf("Failed to create build directory: %v", err)
+	}
+	defer os.RemoveAll(buildDir)
+
+	names := binaryNames(packages)
+	lists := make(map[string][]string)
+	counts := make(map[string]int)
+	// the binaries are all built for the same arch
+	var arch specs.Arch
+	for _, pkg := range packages {
+		name := names[pkg]
+		binaryPath := filepath.Join(buildDir, name)
+		fmt.Printf("Building %v\n", pkg)
+		build := exec.Command("go", "build", "-o", binaryPath, pkg)
+		build.Env = append(os.Environ(), "GOOS=linux")
+		build.Stdout, build.Stderr = os.Stdout, os.Stderr
+		if err := build.Run(); err != nil {
+			fatalf("Failed to build %v: %v", pkg, err)
+		}
+
+		var graph *callGraph
+		graph, arch = analyzeBinary(binaryPath)
+		syscallsList := getSyscallList(graph.allSyscalls(), arch)
+		fmt.Printf("Syscalls detected for %v (total: %v): %v\n", name, len(syscallsList), syscallsList)
+		writeProfile(syscallsList, arch, name+".json")
+
+		lists[name] = syscallsList
+		for _, syscall := range syscallsList {
+			counts[syscall]++
+		}
+	}
+
+	var common []string
+	for syscall, count := range counts {
+		if count == len(packages) {
+			common = append(common, syscall)
+		}
+	}
+	sort.Strings(common)
+	fmt.Printf("Syscalls common to every binary (total: %v): %v\n", len(common), common)
+	writeProfile(common, arch, *commonName+".json")
+
+	for _, pkg := range packages {
+		name := names[pkg]
+		var delta []string
+		for _, syscall := range lists[name] {
+			if counts[syscall] < len(packages) {
+				delta = append(delta, syscall)
+			}
+		}
+		if len(delta) == 0 {
+			fmt.Printf("%v uses no syscalls besides the %v ones\n", name, *commonName)
+			continue
+		}
+		fmt.Printf("Syscalls %v adds to %v (total: %v): %v\n", name, *commonName, len(delta), delta)
+		writeProfile(delta, arch, name+".delta.json")
+	}
+}
+
+// binaryNames names the binary of each package after its last path element, or after its whole
+// import path when several packages end with the same element
+func binaryNames(packages []string) map[string]string {
+	seen := make(map[string]int)
+	for _, pkg := range packages {
+		seen[path.Base(pkg)]++
+	}
+	names := make(map[string]string)
+	for _, pkg := range packages {
+		name := path.Base(pkg)
+		if seen[name] > 1 {
+			name = strings.Replace(pkg, "/", "_", -1)
+		}
+		names[pkg] = name
+	}
+	return names
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+func init() {
+	commands["multiarch"] = multiarchCommand
+}
+
+// the architectures of the profile merged by multiarch, instead of the arch of the analyzed binary
+var mergedArchitectures []specs.Arch
+
+// multiarchCommand analyzes the builds of a program for several arches and writes a single profile for all of
+// them, for multi-arch images. Its architectures and syscall names are the union of the ones of each build.
+func multiarchCommand(args []string) {
+	fs := flag.NewFlagSet("multiarch", flag.ExitOnError)
+	output := fs.String("o", "profile.json", "path to write the merged profile to")
+	parseFlags(fs, args)
+	streamProfile(*output)
+
+	if fs.NArg() < 2 {
+		fmt.Println("Usage: go2seccomp multiarch [-o profile.json] /path/to/app-amd64 /path/to/app-arm64 ...")
+		os.Exit(1)
+	}
+
+	var archs []specs.Arch
+	var syscallsList []string
+	for _, binary := range fs.Args() {
+		graph, arch := analyzeBinary(bazelWorkingPath(binary))
+		names := getSyscallList(graph.allSyscalls(), arch)
+		fmt.Printf("Syscalls detected in %v (total: %v): %v\n", binary, len(names), names)
+
+		for _, a := range profileArchitectures(arch) {
+			if archIndex(archs, a) == -1 {
+				archs = append(archs, a)
+			}
+		}
+		syscallsList = append(syscallsList, names...)
+	}
+	sort.Strings(syscallsList)
+	syscallsList = uniqueStrings(syscallsList)
+	fmt.Printf("Merged profile for %v (total: %v): %v\n", archs, len(syscallsList), syscallsList)
+
+	mergedArchitectures = archs
+	profileSyscalls = syscallsList
+	writeProfile(syscallsList, archs[0], bazelWorkingPath(*output))
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+func init() {
+	registerFormatter(formatFunc{"names", ".txt", writeNames})
+	registerFormatter(formatFunc{"csv", ".csv", writeCSV})
+}
+
+// allowedNames returns the sorted names of the syscalls the profile lets run
+func allowedNames(profile specs.LinuxSeccomp) []string {
+	var names []string
+	for _, rule := range profile.Syscalls {
+		if runningActions[rule.Action] {
+			names = append(names, rule.Names...)
+		}
+	}
+	sort.Strings(names)
+	return uniqueStrings(names)
+}
+
+// writeNames writes the names of the allowed syscalls, one per line, for shell scripts
+func writeNames(w io.Writer, profile specs.LinuxSeccomp, profilePath string) error {
+	for _, name := range allowedNames(profile) {
+		if _, err := fmt.Fprintln(w, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeCSV writes a name,id,arch row for each allowed syscall on each arch of the profile, after a header row.
+// Syscalls an arch doesn't have get no row for it.
+func writeCSV(w io.Writer, profile specs.LinuxSeccomp, profilePath string) error {
+	c := csv.NewWriter(w)
+	c.Write([]string{"name", "id", "arch"})
+	names := allowedNames(profile)
+	for _, arch := range profile.Architectures {
+		ids := make(map[string]int64)
+		for id, name := range syscallIDtoName[arch] {
+			ids[name] = id
+		}
+		for _, name := range names {
+			if id, ok := ids[name]; ok {
+				c.Write([]string{name, strconv.FormatInt(id, 10), string(arch)})
+			}
+		}
+	}
+	c.Flush()
+	return c.Error()
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+var namespaceBaseline = flag.String("fail-on-new-namespaces", "", "exit with status 1 when namespace syscalls not allowed by this profile are used, for CI")
+
+// syscalls creating, entering or changing namespaces and mounts, besides clone and clone3
+var namespaceSyscalls = []string{"unshare", "setns", "mount", "pivot_root"}
+
+// the clone and unshare flags creating namespaces
+var namespaceFlags = []struct {
+	flag int64
+	name string
+}{
+	{0x80, "CLONE_NEWTIME"},
+	{0x20000, "CLONE_NEWNS"},
+	{0x2000000, "CLONE_NEWCGROUP"},
+	{0x4000000, "CLONE_NEWUTS"},
+	{0x8000000, "CLONE_NEWIPC"},
+	{0x10000000, "CLONE_NEWUSER"},
+	{0x20000000, "CLONE_NEWPID"},
+	{0x40000000, "CLONE_NEWNET"},
+}
+
+// namespaceUse is a namespace syscall used by the binary, with its call sites
+type namespaceUse struct {
+	Syscall string   `json:"syscall"`
+	Sites   []string `json:"sites"`
+	// namespace flags passed as constants, where they can be told
+	Flags []string `json:"flags,omitempty"`
+}
+
+func namespaceFlagNames(flags int64) []string {
+	var names []string
+	for _, f := range namespaceFlags {
+		if flags&f.flag != 0 {
+			names = append(names, f.name)
+		}
+	}
+	return names
+}
+
+// namespaceUses returns the namespace syscalls made in the given functions (all if nil). The runtime makes
+// clone for its threads, so clone only counts when made elsewhere and not known to be without namespace flags.
+func namespaceUses(graph *callGraph, arch specs.Arch, ids map[int64]bool, functions map[string]bool) []namespaceUse {
+	nameToID := make(map[string]int64)
+	for id, name := range syscallIDtoName[arch] {
+		nameToID[name] = id
+	}
+	siteNames := func(sites []syscallSite) []string {
+		var names []string
+		for _, site := range sites {
+			names = append(names, fmt.Sprintf("%v at %v", site.Function, site.Location))
+		}
+		return names
+	}
+
+	var uses []namespaceUse
+	for _, name := range namespaceSyscalls {
+		id, ok := nameToID[name]
+		if !ok || !ids[id] {
+			continue
+		}
+		use := namespaceUse{Syscall: name, Sites: siteNames(graph.sitesOf(id, functions))}
+		for _, site := range graph.argSitesOf(name, functions) {
+			if site.Known {
+				use.Flags = append(use.Flags, namespaceFlagNames(site.Value)...)
+			}
+		}
+		uses = append(uses, use)
+	}
+
+	for _, name := range []string{"clone", "clone3"} {
+		id, ok := nameToID[name]
+		if !ok || !ids[id] {
+			continue
+		}
+		// the flags of the sites where they're constants, by address
+		known := make(map[string]int64)
+		for _, site := range graph.argSitesOf(name, functions) {
+			if site.Known {
+				known[site.Address] = site.Value
+			}
+		}
+		use := namespaceUse{Syscall: name}
+		for _, site := range graph.sitesOf(id, functions) {
+			// the runtime and the race detector runtime clone threads
+			if strings.HasPrefix(site.Function, "runtime.") || isRaceRuntimeFunction(site.Function) {
+				continue
+			}
+			if flags, ok := known[site.Address]; ok {
+				if len(namespaceFlagNames(flags)) == 0 {
+					continue
+				}
+				use.Flags = append(use.Flags, namespaceFlagNames(flags)...)
+			}
+			use.Sites = append(use.Sites, fmt.Sprintf("%v at %v", site.Function, site.Location))
+		}
+		if len(use.Sites) > 0 {
+			uses = append(uses, use)
+		}
+	}
+
+	for i := range uses {
+		sort.Strings(uses[i].Flags)
+		uses[i].Flags = uniqueStrings(uses[i].Flags)
+	}
+	return uses
+}
+
+func uniqueStrings(sorted []string) []string {
+	var unique []string
+	for i, s := range sorted {
+		if i == 0 || s != sorted[i-1] {
+			unique = append(unique, s)
+		}
+	}
+	return unique
+}
+
+// reportNamespaces prints the namespace syscalls the binary uses, with where it makes them
+func reportNamespaces(uses []namespaceUse) {
+	if len(uses) == 0 {
+		return
+	}
+	fmt.Println("Namespace syscalls:")
+	for _, use := range uses {
+		if len(use.Flags) > 0 {
+			fmt.Printf("    %v (%v)\n", use.Syscall, strings.Join(use.Flags, "|"))
+		} else {
+			fmt.Printf("    %v\n", use.Syscall)
+		}
+		for _, site := range use.Sites {
+			fmt.Printf("        %v\n", site)
+		}
+	}
+}
+
+// checkNewNamespaces exits with status 1 when the binary uses namespace syscalls the -fail-on-new-namespaces
+// profile doesn't allow
+func checkNewNamespaces(uses []namespaceUse) {
+	if *namespaceBaseline == "" {
+		return
+	}
+	allowed := make(map[string]bool)
+	for _, name := range loadAllowedSyscalls(*namespaceBaseline) {
+		allowed[name] = true
+	}
+	var added []string
+	for _, use := range uses {
+		if !allowed[use.Syscall] {
+			added = append(added, use.Syscall)
+		}
+	}
+	if len(added) == 0 {
+		return
+	}
+	log.Printf("New namespace syscalls not allowed by %v: %v", *namespaceBaseline, strings.Join(added, ", "))
+	exitWithSummary(1)
+}
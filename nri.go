@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/containerd/nri/pkg/api"
+	"github.com/containerd/nri/pkg/stub"
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+func init() {
+	commands["nri"] = nriCommand
+}
+
+// annotations containerd and CRI-O set on containers with the image they run
+var imageNameAnnotations = []string{"io.kubernetes.cri.image-name", "io.kubernetes.cri-o.ImageName"}
+
+// nriPlugin injects the profiles attached to images (see go2seccomp ko -attach) into the spec of
+// the containers running them
+type nriPlugin struct {
+	cacheDir     string
+	fetchTimeout time.Duration
+	failureTTL   time.Duration
+
+	mu sync.Mutex
+	// profile of each image, nil if it has none
+	profiles map[string]*specs.LinuxSeccomp
+	// when fetching the profile of an image last failed, it's not retried before failureTTL
+	failures map[string]time.Time
+	// the fetches in progress, closed once they're done
+	fetching map[string]chan struct{}
+}
+
+// nriCommand runs go2seccomp as a containerd/CRI-O NRI plugin
+func nriCommand(args []string) {
+	fs := flag.NewFlagSet("nri", flag.ExitOnError)
+	name := fs.String("name", "go2seccomp", "plugin name to register with")
+	idx := fs.String("idx", "50", "plugin index to register with, plugins are called in the order of their index")
+	socket := fs.String("socket", api.DefaultSocketPath, "NRI socket of the runtime")
+	cacheDir := fs.String("cache-dir", "/var/cache/go2seccomp", "directory to pull the profiles attached to images to")
+	fetchTimeout := fs.Duration("fetch-timeout", 30*time.Second, "how long fetching the profile of an image may take before its containers run without one")
+	failureTTL := fs.Duration("failure-ttl", 5*time.Minute, "how long to wait before fetching the profile of an image again after it failed")
+	parseFlags(fs, args)
+
+	if err := os.MkdirAll(*cacheDir, 0700); err != nil {
+		fatalf("Failed to create %v: %v", *cacheDir, err)
+	}
+
+	p := &nriPlugin{
+		cacheDir:     *cacheDir,
+		fetchTimeout: *fetchTimeout,
+		failureTTL:   *failureTTL,
+		profiles:     make(map[string]*specs.LinuxSeccomp),
+		failures:     make(map[string]time.Time),
+		fetching:     make(map[string]chan struct{}),
+	}
+	s, err := stub.New(p, stub.WithPluginName(*name), stub.WithPluginIdx(*idx), stub.WithSocketPath(*socket))
+	if err != nil {
+		fatalf("Failed to create NRI plugin: %v", err)
+	}
+
+	fmt.Printf("Registering NRI plugin %v-%v on %v\n", *idx, *name, *socket)
+	if err := s.Run(context.Background()); err != nil {
+		fatalf("NRI plugin exited: %v", err)
+	}
+}
+
+// CreateContainer sets the seccomp policy of the container to the profile attached to its image, if any.
+// Containers run as usual when their image has no profile or it can't be fetched.
+func (p *nriPlugin) CreateContainer(ctx context.Context, pod *api.PodSandbox, ctr *api.Container) (*api.ContainerAdjustment, []*api.ContainerUpdate, error) {
+	image := ""
+	for _, annotation := range imageNameAnnotations {
+		if image = ctr.Annotations[annotation]; image != "" {
+			break
+		}
+	}
+	if image == "" {
+		return nil, nil, nil
+	}
+
+	profile := p.profileFor(ctx, image)
+	if profile == nil {
+		return nil, nil, nil
+	}
+
+	adjust := &api.ContainerAdjustment{}
+	adjust.SetLinuxSeccompPolicy(api.FromOCILinuxSeccomp(profile))
+	log.Printf("Injecting the profile of %v into container %v of pod %v/%v\n", image, ctr.Name, pod.Namespace, pod.Name)
+	return adjust, nil, nil
+}
+
+// profileFor returns the profile attached to the image, fetching it the first time. The lock isn't held while
+// fetching, so a slow registry only holds up the containers of its images, for at most -fetch-timeout.
+func (p *nriPlugin) profileFor(ctx context.Context, image string) *specs.LinuxSeccomp {
+	p.mu.Lock()
+	for {
+		if profile, ok := p.profiles[image]; ok {
+			p.mu.Unlock()
+			return profile
+		}
+		if failed, ok := p.failures[image]; ok && time.Since(failed) < p.failureTTL {
+			p.mu.Unlock()
+			return nil
+		}
+		done, ok := p.fetching[image]
+		if !ok {
+			break
+		}
+		// another container of the image is fetching it
+		p.mu.Unlock()
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return nil
+		}
+		p.mu.Lock()
+	}
+	done := make(chan struct{})
+	p.fetching[image] = done
+	p.mu.Unlock()
+
+	fetchCtx, cancel := context.WithTimeout(ctx, p.fetchTimeout)
+	profile, err := fetchAttachedProfile(fetchCtx, image, p.cacheDir)
+	cancel()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.fetching, image)
+	close(done)
+	if err != nil {
+		log.Printf("Failed to fetch the profile of %v: %v\n", image, err)
+		p.failures[image] = time.Now()
+		return nil
+	}
+	delete(p.failures, image)
+	p.profiles[image] = profile
+	return profile
+}
+
+// orasDiscovery is the part of the oras discover -o json output with the referrers of an image
+type orasDiscovery struct {
+	Manifests []struct {
+		Digest       string `json:"digest"`
+		ArtifactType string `json:"artifactType"`
+	} `json:"manifests"`
+}
+
+// fetchAttachedProfile pulls the profile attached to the image with oras, returning nil if there's none
+func fetchAttachedProfile(ctx context.Context, image, cacheDir string) (*specs.LinuxSeccomp, error) {
+	out, err := exec.CommandContext(ctx, "oras", "discover", "--artifact-type", profileArtifactType, "--format", "json", image).Output()
+	if err != nil {
+		return nil, fmt.Errorf("oras discover failed: %v", err)
+	}
+	var discovery orasDiscovery
+	if err := json.Unmarshal(out, &discovery); err != nil {
+		return nil, fmt.Errorf("failed to parse oras discover output: %v", err)
+	}
+	if len(discovery.Manifests) == 0 {
+		return nil, nil
+	}
+
+	// the last attached profile is the most recent one
+	digest := discovery.Manifests[len(discovery.Manifests)-1].Digest
+	return pullProfile(ctx, imageRepository(image)+"@"+digest, filepath.Join(cacheDir, strings.Replace(digest, ":", "-", 1)))
+}
+
+// pullProfile pulls the profile artifact to dir with oras and loads it
+func pullProfile(ctx context.Context, reference, dir string) (*specs.LinuxSeccomp, error) {
+	if err := exec.CommandContext(ctx, "oras", "pull", "-o", dir, reference).Run(); err != nil {
+		return nil, fmt.Errorf("oras pull failed: %v", err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil || len(files) != 1 {
+		return nil, fmt.Errorf("expected a single profile in the artifact %v, found %v", reference, len(files))
+	}
+	data, err := ioutil.ReadFile(files[0])
+	if err != nil {
+		return nil, err
+	}
+	var profile specs.LinuxSeccomp
+	if err := json.Unmarshal(data, &profile); err != nil {
+		return nil, fmt.Errorf("failed to parse profile %v: %v", files[0], err)
+	}
+	if errs := validateProfile(profile); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid profile %v: %v", files[0], errs)
+	}
+	return &profile, nil
+}
+
+// imageRepository strips the tag and digest of an image reference
+func imageRepository(image string) string {
+	if i := strings.Index(image, "@"); i != -1 {
+		image = image[:i]
+	}
+	// a colon after the last slash starts the tag, before it's the registry port
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+	return image
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+var profileFileMode = flag.String("file-mode", "0644", "permissions of the written profiles, in octal")
+var profileOwner = flag.String("owner", "", "owner of the written profiles, user or user:group, by name or ID")
+var jsonIndent = flag.Int("indent", 4, "spaces to indent the JSON profiles with")
+var compact = flag.Bool("compact", false, "write the JSON profiles on a single line, for minified output")
+var followSymlinks = flag.Bool("follow-symlinks", false, "write profiles through a symlink at the destination instead of refusing to")
+
+// the profile path writing the profile to stdout, e.g. go2seccomp my_app - | kubectl apply -f -
+const stdoutPath = "-"
+
+// the stdout of the process, where the profile is written once os.Stdout is stderr
+var profileStdout = os.Stdout
+
+// streamProfile moves the messages to stderr when the profile is written to stdout, so only the profile is
+// piped to the next command
+func streamProfile(profilePath string) {
+	if profilePath == stdoutPath {
+		os.Stdout = os.Stderr
+	}
+}
+
+// newProfileEncoder returns the encoder of the JSON profiles, indented with -indent unless -compact is given.
+// The fields come in the order of their structs, and the encoder ends the profile with a newline.
+func newProfileEncoder(w io.Writer) *json.Encoder {
+	enc := json.NewEncoder(w)
+	if !*compact {
+		enc.SetIndent("", strings.Repeat(" ", *jsonIndent))
+	}
+	return enc
+}
+
+// parseMode parses the -file-mode permissions
+func parseMode(mode string) (os.FileMode, error) {
+	perm, err := strconv.ParseUint(mode, 8, 32)
+	if err != nil || perm > 0777 {
+		return 0, fmt.Errorf("invalid mode %v, use octal permissions like 0644", mode)
+	}
+	return os.FileMode(perm), nil
+}
+
+// parseOwner returns the uid and gid of the -owner user[:group], -1 for the ones not given
+func parseOwner(owner string) (int, int, error) {
+	uid, gid := -1, -1
+	if owner == "" {
+		return uid, gid, nil
+	}
+	userName, groupName := owner, ""
+	if i := strings.Index(owner, ":"); i != -1 {
+		userName, groupName = owner[:i], owner[i+1:]
+	}
+	if userName != "" {
+		id, err := strconv.Atoi(userName)
+		if err != nil {
+			u, err := user.Lookup(userName)
+			if err != nil {
+				return 0, 0, err
+			}
+			id, _ = strconv.Atoi(u.Uid)
+		}
+		uid = id
+	}
+	if groupName != "" {
+		id, err := strconv.Atoi(groupName)
+		if err != nil {
+			g, err := user.LookupGroup(groupName)
+			if err != nil {
+				return 0, 0, err
+			}
+			id, _ = strconv.Atoi(g.Gid)
+		}
+		gid = id
+	}
+	return uid, gid, nil
+}
+
+// writeProfileFile writes a profile with the -file-mode and -owner, refusing to replace a symlink at the destination
+// unless -follow-symlinks is given, in which case the file it points to is written
+func writeProfileFile(path string, data []byte) {
+	perm, err := parseMode(*profileFileMode)
+	if err != nil {
+		fatalf("Invalid -file-mode: %v", err)
+	}
+	uid, gid, err := parseOwner(*profileOwner)
+	if err != nil {
+		fatalf("Invalid -owner: %v", err)
+	}
+
+	if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		if !*followSymlinks {
+			fatalf("%v is a symlink, refusing to write the profile through it, use -follow-symlinks to write it anyway", path)
+		}
+		target, err := filepath.EvalSymlinks(path)
+		if err != nil {
+			fatalf("Failed to resolve symlink %v: %v", path, err)
+		}
+		path = target
+	}
+
+	if err := writeFileAtomicOwned(path, data, perm, uid, gid); err != nil {
+		fatalf("Failed to write seccomp profile: %v", err)
+	}
+}
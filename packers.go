@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+)
+
+// signatures packers leave in the first bytes of the binaries they pack
+var packerSignatures = []struct {
+	packer    string
+	signature []byte
+}{
+	{"UPX", []byte("UPX!")},
+	{"UPX", []byte("$Info: This file is packed with the UPX")},
+}
+
+// packers go2seccomp knows how to unpack, with the command to do it
+var unpackCommands = map[string]func(packed, unpacked string) *exec.Cmd{
+	"UPX": func(packed, unpacked string) *exec.Cmd {
+		return exec.Command("upx", "-d", "-q", "-o", unpacked, packed)
+	},
+}
+
+// detectPacker returns the name of the packer used on the binary, or "" if it doesn't seem packed.
+// Packed binaries disassemble into garbage, so analyzing them gives a tiny and wrong profile.
+func detectPacker(binaryPath string) string {
+	f, err := os.Open(binaryPath)
+	if err != nil {
+		fatalln("can't open file", err)
+	}
+	defer f.Close()
+
+	header := make([]byte, 4096)
+	n, err := io.ReadFull(f, header)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		fatalln("can't read file", err)
+	}
+	header = header[:n]
+
+	for _, p := range packerSignatures {
+		if bytes.Contains(header, p.signature) {
+			return p.packer
+		}
+	}
+	return ""
+}
+
+// unpackBinary decompresses the packed binary to a temporary file and returns its path
+func unpackBinary(binaryPath, packer string) string {
+	unpackCommand, ok := unpackCommands[packer]
+	if !ok {
+		fatalf("%v is packed with %v, which go2seccomp can't unpack. Unpack it and run go2seccomp on the result", binaryPath, packer)
+	}
+
+	tmp, err := ioutil.TempFile("", "go2seccomp-unpacked")
+	if err != nil {
+		fatalf("Failed to create temporary file: %v", err)
+	}
+	tmp.Close()
+	// upx refuses to overwrite existing files
+	os.Remove(tmp.Name())
+
+	fmt.Printf("Unpacking %v binary %v\n", packer, binaryPath)
+	cmd := unpackCommand(binaryPath, tmp.Name())
+	if out, err := cmd.CombinedOutput(); err != nil {
+		fatalf("Failed to unpack %v: %v\n%s", binaryPath, err, out)
+	}
+	return tmp.Name()
+}
@@ -0,0 +1,114 @@
+package main
+
+import "strings"
+
+// OpenBSD pledge(2) promises and the Linux syscalls they stand for. Syscalls not listed are in stdio, unless
+// they're risky enough that no promise would grant them. kill is in stdio, the runtime signals itself with it.
+var pledgePromises = []struct {
+	name     string
+	syscalls []string
+}{
+	{"rpath", []string{
+		"open", "openat", "openat2", "stat", "lstat", "newfstatat", "statx", "stat64", "lstat64", "fstatat64", "statfs",
+		"statfs64", "access", "faccessat", "faccessat2", "readlink", "readlinkat", "getdents", "getdents64", "chdir",
+		"fchdir", "getxattr", "lgetxattr", "listxattr", "llistxattr",
+	}},
+	{"wpath", []string{"creat", "truncate", "truncate64", "setxattr", "lsetxattr", "removexattr", "lremovexattr"}},
+	{"cpath", []string{
+		"creat", "mkdir", "mkdirat", "rmdir", "unlink", "unlinkat", "rename", "renameat", "renameat2", "link",
+		"linkat", "symlink", "symlinkat",
+	}},
+	{"dpath", []string{"mknod", "mknodat"}},
+	{"fattr", []string{
+		"chmod", "fchmod", "fchmodat", "fchmodat2", "utime", "utimes", "utimensat", "futimesat", "fsetxattr",
+		"fremovexattr",
+	}},
+	{"chown", []string{"chown", "fchown", "lchown", "fchownat", "chown32", "fchown32", "lchown32"}},
+	{"flock", []string{"flock"}},
+	{"proc", []string{
+		"fork", "vfork", "setpgid", "setsid", "setpriority", "pidfd_open", "pidfd_send_signal",
+		"sched_setaffinity", "sched_setscheduler", "sched_setparam",
+	}},
+	{"exec", []string{"execve", "execveat"}},
+	{"settime", []string{"settimeofday", "clock_settime", "adjtimex", "clock_adjtime"}},
+	{"id", []string{
+		"setuid", "setgid", "setreuid", "setregid", "setresuid", "setresgid", "setgroups", "setfsuid", "setfsgid",
+		"setuid32", "setgid32", "setreuid32", "setregid32", "setresuid32", "setresgid32", "setgroups32",
+		"setfsuid32", "setfsgid32", "setrlimit", "prlimit64",
+	}},
+}
+
+// the promises in the order they're listed
+var pledgeOrder = []string{
+	"stdio", "rpath", "wpath", "cpath", "dpath", "tmppath", "inet", "fattr", "chown", "flock", "unix", "dns", "getpw",
+	"proc", "exec", "prot_exec", "settime", "id", "route",
+}
+
+// promisesOf summarizes the allowed syscalls in pledge promises, with the syscalls no promise would grant.
+// The network promises come from the socket families, dns and getpw from the paths in the binary, prot_exec
+// from the executable memory analysis.
+func promisesOf(syscalls []string) ([]string, []string) {
+	promiseOf := make(map[string][]string)
+	for _, promise := range pledgePromises {
+		for _, name := range promise.syscalls {
+			promiseOf[name] = append(promiseOf[name], promise.name)
+		}
+	}
+
+	promised := make(map[string]bool)
+	allowed := make(map[string]bool)
+	var beyond []string
+	for _, name := range syscalls {
+		allowed[name] = true
+		switch {
+		case promiseOf[name] != nil:
+			for _, promise := range promiseOf[name] {
+				promised[promise] = true
+			}
+		case riskOf(name).Tier == riskHigh:
+			beyond = append(beyond, name)
+		default:
+			promised["stdio"] = true
+		}
+	}
+
+	if allowed["socket"] {
+		if observedFamilies == nil {
+			promised["inet"], promised["unix"] = true, true
+		}
+		for _, family := range observedFamilies {
+			switch family {
+			case 1:
+				promised["unix"] = true
+			case 2, 10:
+				promised["inet"] = true
+			case 16:
+				promised["route"] = true
+			}
+		}
+	}
+	for _, path := range binaryPaths {
+		switch {
+		case path == "/etc/resolv.conf" && promised["inet"]:
+			promised["dns"] = true
+		case path == "/etc/passwd" || path == "/etc/group":
+			promised["getpw"] = true
+		case path == "/tmp" || strings.HasPrefix(path, "/tmp/"):
+			promised["tmppath"] = true
+		}
+	}
+	if runsPrograms {
+		promised["proc"], promised["exec"] = true, true
+	}
+	if allowed["mmap"] && !noExecMappings {
+		promised["prot_exec"] = true
+	}
+
+	var promises []string
+	for _, promise := range pledgeOrder {
+		if promised[promise] {
+			promises = append(promises, promise)
+		}
+	}
+	return promises, beyond
+}
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"gopkg.in/yaml.v2"
+)
+
+func init() {
+	commands["pod"] = podCommand
+}
+
+// where the pod spec is in each kind of workload
+var podSpecPaths = map[string][]string{
+	"Pod":         {"spec"},
+	"Deployment":  {"spec", "template", "spec"},
+	"ReplicaSet":  {"spec", "template", "spec"},
+	"StatefulSet": {"spec", "template", "spec"},
+	"DaemonSet":   {"spec", "template", "spec"},
+	"Job":         {"spec", "template", "spec"},
+	"CronJob":     {"spec", "jobTemplate", "spec", "template", "spec"},
+}
+
+// annotation recording the sha256 of the profile generated for each container, checked by the webhook
+const profileHashAnnotation = "go2seccomp.io/profile-sha256."
+
+// podCommand generates profiles for the containers of the workloads in a manifest, and writes the
+// manifest back with the seccompProfile fields pointing to them
+func podCommand(args []string) {
+	fs := flag.NewFlagSet("pod", flag.ExitOnError)
+	union := fs.Bool("union", false, "generate one profile for the whole pod instead of one per container")
+	outDir := fs.String("out-dir", ".", "directory to write the profiles to")
+	profileDir := fs.String("profile-dir", "go2seccomp", "directory, relative to the kubelet seccomp root, the profiles will be installed to")
+	annotate := fs.Bool("annotations", false, "also reference the profiles with the seccomp annotations, for clusters still configured with them")
+	parseFlags(fs, args)
+
+	if fs.NArg() < 2 {
+		fmt.Println("Usage: go2seccomp pod [-union] [-out-dir dir] /path/to/manifest.yaml /path/to/output.yaml")
+		os.Exit(1)
+	}
+
+	in, err := os.Open(fs.Arg(0))
+	if err != nil {
+		fatalf("Failed to open manifest: %v", err)
+	}
+	defer in.Close()
+
+	var docs []yaml.MapSlice
+	dec := yaml.NewDecoder(in)
+	for {
+		var doc yaml.MapSlice
+		err := dec.Decode(&doc)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			fatalf("Failed to parse manifest %v: %v", fs.Arg(0), err)
+		}
+		docs = append(docs, doc)
+	}
+
+	tmpDir, err := ioutil.TempDir("", "go2seccomp")
+	if err != nil {
+		fatalf("Failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	pa := &podAnalyzer{tmpDir: tmpDir, images: make(map[string]imageSyscalls)}
+
+	for i, doc := range docs {
+		kind, _ := yamlGet(doc, "kind").(string)
+		specPath, ok := podSpecPaths[kind]
+		if !ok {
+			continue
+		}
+		name, _ := yamlGet(doc, "metadata", "name").(string)
+		podSpec, _ := yamlGet(doc, specPath...).(yaml.MapSlice)
+		if podSpec == nil {
+			warnf("%v %v has no pod spec at %v, leaving it as is", kind, name, strings.Join(specPath, "."))
+			continue
+		}
+		// workloads of different kinds can share a name, e.g. a Deployment and a DaemonSet
+		profileName := strings.ToLower(kind) + "-" + name
+
+		var podIDs map[int64]bool
+		var podArch specs.Arch
+		// the pod metadata is next to its spec
+		metadataPath := append(append([]string{}, specPath[:len(specPath)-1]...), "metadata")
+
+		for _, field := range []string{"initContainers", "containers"} {
+			containers, _ := yamlGet(podSpec, field).([]interface{})
+			for j, c := range containers {
+				container, _ := c.(yaml.MapSlice)
+				containerName, _ := yamlGet(container, "name").(string)
+				image, _ := yamlGet(container, "image").(string)
+				if image == "" {
+					continue
+				}
+				result := pa.analyzeImage(image)
+
+				if *union {
+					if podIDs == nil {
+						podIDs, podArch = make(map[int64]bool), result.arch
+					} else if podArch != result.arch {
+						fatalf("Containers of %v have different architectures (%v, %v)", name, podArch, result.arch)
+					}
+					for id := range result.ids {
+						podIDs[id] = true
+					}
+					continue
+				}
+
+				file := profileName + "-" + containerName + ".json"
+				writeProfileFormat("oci", getSyscallList(result.ids, result.arch), result.arch, filepath.Join(*outDir, file))
+				containers[j] = yamlSet(container, localhostProfile(*profileDir, file), "securityContext", "seccompProfile")
+				if *annotate {
+					annotations, _ := yamlGet(doc, append(metadataPath, "annotations")...).(yaml.MapSlice)
+					annotations = seccompAnnotations(annotations, path.Join(*profileDir, file), containerName, "")
+					doc = yamlSet(doc, annotations, append(metadataPath, "annotations")...)
+				}
+				doc = yamlSet(doc, fileSHA256(filepath.Join(*outDir, file)), append(metadataPath, "annotations", profileHashAnnotation+containerName)...)
+			}
+		}
+
+		if *union && podIDs != nil {
+			file := profileName + ".json"
+			writeProfileFormat("oci", getSyscallList(podIDs, podArch), podArch, filepath.Join(*outDir, file))
+			podSpec = yamlSet(podSpec, localhostProfile(*profileDir, file), "securityContext", "seccompProfile")
+			if *annotate {
+				annotations, _ := yamlGet(doc, append(metadataPath, "annotations")...).(yaml.MapSlice)
+				annotations = seccompAnnotations(annotations, path.Join(*profileDir, file), "", "")
+				doc = yamlSet(doc, annotations, append(metadataPath, "annotations")...)
+			}
+			hash := fileSHA256(filepath.Join(*outDir, file))
+			for _, field := range []string{"initContainers", "containers"} {
+				containers, _ := yamlGet(podSpec, field).([]interface{})
+				for _, c := range containers {
+					containerName, _ := yamlGet(c.(yaml.MapSlice), "name").(string)
+					doc = yamlSet(doc, hash, append(metadataPath, "annotations", profileHashAnnotation+containerName)...)
+				}
+			}
+		}
+		docs[i] = yamlSet(doc, podSpec, specPath...)
+	}
+
+	out, err := os.Create(fs.Arg(1))
+	if err != nil {
+		fatalf("Failed to create output manifest: %v", err)
+	}
+	defer out.Close()
+
+	enc := yaml.NewEncoder(out)
+	for _, doc := range docs {
+		if err := enc.Encode(doc); err != nil {
+			fatalf("Failed to write output manifest: %v", err)
+		}
+	}
+	enc.Close()
+	fmt.Printf("Saved manifest at %v\n", fs.Arg(1))
+}
+
+type imageSyscalls struct {
+	ids  map[int64]bool
+	arch specs.Arch
+}
+
+// podAnalyzer analyzes the Go binaries of container images, only once per image
+type podAnalyzer struct {
+	tmpDir string
+	images map[string]imageSyscalls
+}
+
+func (pa *podAnalyzer) analyzeImage(image string) imageSyscalls {
+	if result, ok := pa.images[image]; ok {
+		return result
+	}
+
+	dir, err := ioutil.TempDir(pa.tmpDir, "image")
+	if err != nil {
+		fatalf("Failed to create temporary directory: %v", err)
+	}
+
+	result := imageSyscalls{ids: make(map[int64]bool)}
+	binaries, err := extractGoBinaries(image, dir, "")
+	if err != nil {
+		fatalf("%v", err)
+	}
+	sort.Strings(binaries)
+	for _, binary := range binaries {
+		graph, arch := analyzeBinary(binary)
+		if result.arch != "" && result.arch != arch {
+			fatalf("Image %v has Go binaries for different architectures (%v, %v)", image, result.arch, arch)
+		}
+		result.arch = arch
+		for id := range graph.allSyscalls() {
+			result.ids[id] = true
+		}
+	}
+
+	pa.images[image] = result
+	return result
+}
+
+func localhostProfile(profileDir, file string) yaml.MapSlice {
+	return yaml.MapSlice{
+		{Key: "type", Value: "Localhost"},
+		{Key: "localhostProfile", Value: filepath.ToSlash(filepath.Join(profileDir, file))},
+	}
+}
+
+// yamlGet follows the keys through nested mappings, returning nil if any of them is missing
+func yamlGet(m yaml.MapSlice, keys ...string) interface{} {
+	var value interface{} = m
+	for _, key := range keys {
+		mapping, ok := value.(yaml.MapSlice)
+		if !ok {
+			return nil
+		}
+		value = nil
+		for _, item := range mapping {
+			if item.Key == key {
+				value = item.Value
+				break
+			}
+		}
+	}
+	return value
+}
+
+// yamlSet sets the value at the keys path, creating the missing mappings along the way,
+// and returns the updated mapping
+func yamlSet(m yaml.MapSlice, value interface{}, keys ...string) yaml.MapSlice {
+	if len(keys) == 0 {
+		return m
+	}
+	for i, item := range m {
+		if item.Key != keys[0] {
+			continue
+		}
+		if len(keys) == 1 {
+			m[i].Value = value
+		} else {
+			child, _ := item.Value.(yaml.MapSlice)
+			m[i].Value = yamlSet(child, value, keys[1:]...)
+		}
+		return m
+	}
+	if len(keys) == 1 {
+		return append(m, yaml.MapItem{Key: keys[0], Value: value})
+	}
+	return append(m, yaml.MapItem{Key: keys[0], Value: yamlSet(nil, value, keys[1:]...)})
+}
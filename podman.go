@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	commands["podman"] = podmanCommand
+}
+
+// seccomp profile podman and CRI-O use when containers.conf doesn't set one
+const defaultPodmanSeccompProfile = "/usr/share/containers/seccomp.json"
+
+// annotation CRI-O reads the OCI artifact with the profile of a container (or of the whole pod, with POD) from
+const crioSeccompAnnotation = "seccomp-profile.kubernetes.cri-o.io/"
+
+// media type CRI-O expects as the config of seccomp profile artifacts
+const crioSeccompConfigType = "application/vnd.cncf.seccomp-profile.config.v1+json"
+
+// podmanCommand prints how to use a profile with podman and CRI-O, and optionally installs it as the
+// default profile in the location set by containers.conf
+func podmanCommand(args []string) {
+	fs := flag.NewFlagSet("podman", flag.ExitOnError)
+	image := fs.String("image", "IMAGE", "image to show in the podman run command")
+	install := fs.Bool("containers-conf", false, "install the profile as the default one, at the seccomp_profile location of containers.conf")
+	force := fs.Bool("force", false, "with -containers-conf, replace the profile already there, backing it up first")
+	artifact := fs.String("crio-artifact", "", "reference of the OCI artifact with the profile, to print the CRI-O annotation for")
+	container := fs.String("container", "POD", "container the CRI-O annotation applies to, POD for all the containers of the pod")
+	parseFlags(fs, args)
+
+	if fs.NArg() < 1 {
+		fmt.Println("Usage: go2seccomp podman [-image image] [-containers-conf [-force]] [-crio-artifact ref [-container name]] /path/to/profile.json")
+		os.Exit(1)
+	}
+	profilePath := fs.Arg(0)
+
+	if errs := validateProfile(loadProfile(profilePath)); len(errs) > 0 {
+		for _, err := range errs {
+			fmt.Printf("%v: %v\n", profilePath, err)
+		}
+		fatalf("Invalid profile %v", profilePath)
+	}
+
+	if *install {
+		dest := containersConfSeccompProfile()
+		data, err := ioutil.ReadFile(profilePath)
+		if err != nil {
+			fatalf("Failed to read profile: %v", err)
+		}
+		// the profile there is the host-wide default of every container, keep a copy of it
+		if original, err := ioutil.ReadFile(dest); err == nil {
+			if !*force {
+				fatalf("%v already exists and is the default profile of every container, use -force to replace it", dest)
+			}
+			backup := dest + ".orig"
+			if _, err := os.Stat(backup); err == nil {
+				// an earlier install already saved the original profile
+				backup = dest + ".bak"
+			}
+			if err := writeFileAtomic(backup, original, 0644); err != nil {
+				fatalf("Failed to back up %v: %v", dest, err)
+			}
+			fmt.Printf("Saved the previous profile at %v\n", backup)
+		}
+		if err := writeFileAtomic(dest, data, 0644); err != nil {
+			fatalf("Failed to install profile: %v", err)
+		}
+		fmt.Printf("Installed profile at %v, podman and CRI-O use it for the containers that don't set one\n", dest)
+	}
+
+	absPath, err := filepath.Abs(profilePath)
+	if err != nil {
+		fatalf("Failed to resolve %v: %v", profilePath, err)
+	}
+	fmt.Printf("Run the container with:\n\npodman run --security-opt seccomp=%v %v\n", absPath, *image)
+
+	if *artifact != "" {
+		fmt.Printf("\nPush the profile for CRI-O with:\n\noras push %v --config /dev/null:%v %v:application/vnd.oci.image.layer.v1.tar\n",
+			*artifact, crioSeccompConfigType, filepath.Base(profilePath))
+		fmt.Printf("\nand annotate the pod with:\n\nmetadata:\n  annotations:\n    %v%v: %v\n", crioSeccompAnnotation, *container, *artifact)
+	}
+}
+
+// containersConfSeccompProfile returns the seccomp_profile set in the containers.conf files, from the
+// highest to the lowest precedence, or the default location
+func containersConfSeccompProfile() string {
+	var paths []string
+	if env := os.Getenv("CONTAINERS_CONF"); env != "" {
+		paths = append(paths, env)
+	}
+	if os.Geteuid() != 0 {
+		if home, err := os.UserHomeDir(); err == nil {
+			paths = append(paths, filepath.Join(home, ".config/containers/containers.conf"))
+		}
+	}
+	paths = append(paths, "/etc/containers/containers.conf", "/usr/share/containers/containers.conf")
+
+	for _, path := range paths {
+		if profile := readSeccompProfileSetting(path); profile != "" {
+			return profile
+		}
+	}
+	return defaultPodmanSeccompProfile
+}
+
+// readSeccompProfileSetting returns the seccomp_profile of the [containers] table of a containers.conf file
+func readSeccompProfileSetting(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	table := ""
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			table = strings.Trim(line, "[] ")
+			continue
+		}
+		parts := strings.SplitN(line, "=", 2)
+		if table != "containers" || len(parts) != 2 || strings.TrimSpace(parts[0]) != "seccomp_profile" {
+			continue
+		}
+		value := strings.TrimSpace(parts[1])
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			return unquoted
+		}
+		return strings.Trim(value, "'")
+	}
+	return ""
+}
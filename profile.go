@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+var validActions = map[specs.LinuxSeccompAction]bool{
+	specs.ActKill: true, specs.ActKillProcess: true, specs.ActKillThread: true, specs.ActTrap: true,
+	specs.ActErrno: true, specs.ActTrace: true, specs.ActAllow: true, specs.ActLog: true, specs.ActNotify: true,
+}
+
+var validArchs = map[specs.Arch]bool{
+	specs.ArchX86: true, specs.ArchX86_64: true, specs.ArchX32: true, specs.ArchARM: true, specs.ArchAARCH64: true,
+	specs.ArchMIPS: true, specs.ArchMIPS64: true, specs.ArchMIPS64N32: true, specs.ArchMIPSEL: true,
+	specs.ArchMIPSEL64: true, specs.ArchMIPSEL64N32: true, specs.ArchPPC: true, specs.ArchPPC64: true,
+	specs.ArchPPC64LE: true, specs.ArchS390: true, specs.ArchS390X: true, specs.ArchPARISC: true,
+	specs.ArchPARISC64: true, specs.ArchRISCV64: true,
+	// only defined by runtime-spec 1.3, which the NRI API doesn't build with yet
+	"SCMP_ARCH_LOONGARCH64": true, "SCMP_ARCH_M68K": true, "SCMP_ARCH_SH": true, "SCMP_ARCH_SHEB": true,
+}
+
+var validOperators = map[specs.LinuxSeccompOperator]bool{
+	specs.OpNotEqual: true, specs.OpLessThan: true, specs.OpLessEqual: true, specs.OpEqualTo: true,
+	specs.OpGreaterEqual: true, specs.OpGreaterThan: true, specs.OpMaskedEqual: true,
+}
+
+// loadProfile reads a seccomp profile in the runtime-spec JSON format, or YAML, merging the fragments
+// it includes
+func loadProfile(path string) specs.LinuxSeccomp {
+	return readProfile(path, make(map[string]bool))
+}
+
+func readProfile(path string, including map[string]bool) specs.LinuxSeccomp {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		fatalf("Failed to read profile: %v", err)
+	}
+
+	if isYAMLPath(path) {
+		if data, err = yamlToJSON(data); err != nil {
+			fatalf("Failed to parse profile %v: %v", path, err)
+		}
+	}
+
+	var profile specs.LinuxSeccomp
+	if err := json.Unmarshal(data, &profile); err != nil {
+		fatalf("Failed to parse profile %v: %v", path, err)
+	}
+	return resolveIncludes(profile, data, path, including)
+}
+
+// validateProfile returns the problems that would make a runtime refuse to load the profile
+func validateProfile(profile specs.LinuxSeccomp) []error {
+	var errs []error
+
+	if !validActions[profile.DefaultAction] {
+		errs = append(errs, fmt.Errorf("invalid defaultAction %q", profile.DefaultAction))
+	}
+	for _, arch := range profile.Architectures {
+		if !validArchs[arch] {
+			errs = append(errs, fmt.Errorf("invalid architecture %q", arch))
+		}
+	}
+	if profile.DefaultErrnoRet != nil && *profile.DefaultErrnoRet > maxErrno {
+		errs = append(errs, fmt.Errorf("invalid defaultErrnoRet %v", *profile.DefaultErrnoRet))
+	}
+	for _, f := range profile.Flags {
+		if !validFlags[f] {
+			errs = append(errs, fmt.Errorf("invalid flag %q", f))
+		}
+	}
+	if profile.ListenerMetadata != "" && profile.ListenerPath == "" {
+		errs = append(errs, fmt.Errorf("listenerMetadata without listenerPath"))
+	}
+	for i, rule := range profile.Syscalls {
+		if len(rule.Names) == 0 {
+			errs = append(errs, fmt.Errorf("syscalls[%v] has no names", i))
+		}
+		for _, name := range rule.Names {
+			if name == "" {
+				errs = append(errs, fmt.Errorf("syscalls[%v] has an empty name", i))
+			}
+		}
+		if !validActions[rule.Action] {
+			errs = append(errs, fmt.Errorf("syscalls[%v] has invalid action %q", i, rule.Action))
+		}
+		for _, arg := range rule.Args {
+			if arg.Index > 5 {
+				errs = append(errs, fmt.Errorf("syscalls[%v] has an argument with invalid index %v", i, arg.Index))
+			}
+			if !validOperators[arg.Op] {
+				errs = append(errs, fmt.Errorf("syscalls[%v] has an argument with invalid op %q", i, arg.Op))
+			}
+		}
+	}
+	return errs
+}
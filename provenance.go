@@ -0,0 +1,74 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+var annotate = flag.Bool("annotate", false, "record in each rule of OCI profiles why its syscalls are there, in an x-go2seccomp field")
+
+// syscallProvenance tells why a syscall is in the profile
+type syscallProvenance struct {
+	Sources []string `json:"sources"`
+	// one of the call sites, if detected
+	Site string `json:"site,omitempty"`
+	// written by the reviewer with -interactive
+	Note string `json:"note,omitempty"`
+}
+
+// the provenance of the syscalls of the analyzed binary, by name, for -annotate
+var provenances map[string]syscallProvenance
+
+// recordProvenance records where each syscall of the profile comes from, given their sources by syscallSources,
+// with a call site in the given functions (or in any if nil)
+func recordProvenance(graph *callGraph, arch specs.Arch, syscallsList []string, sources map[string][]string, functions map[string]bool) {
+	nameToID := make(map[string]int64)
+	for id, name := range syscallIDtoName[arch] {
+		nameToID[name] = id
+	}
+	provenances = make(map[string]syscallProvenance)
+	for _, name := range syscallsList {
+		p := syscallProvenance{Sources: sources[name]}
+		if id, ok := nameToID[name]; ok {
+			if sites := graph.sitesOf(id, functions); len(sites) > 0 {
+				p.Site = sites[0].Function
+				if sites[0].Location != "" {
+					p.Site = fmt.Sprintf("%v at %v", sites[0].Function, sites[0].Location)
+				}
+			}
+		}
+		provenances[name] = p
+	}
+}
+
+// annotatedRule is a rule with the provenance of its syscalls
+type annotatedRule struct {
+	specs.LinuxSyscall
+	Provenance map[string]syscallProvenance `json:"x-go2seccomp,omitempty"`
+}
+
+// annotatedOCIProfile replaces the rules of the OCI profile with annotated ones
+type annotatedOCIProfile struct {
+	ociProfile
+	Syscalls []annotatedRule `json:"syscalls,omitempty"`
+}
+
+// annotateRules returns the rules of the profile with the provenance of the syscalls they name
+func annotateRules(profile specs.LinuxSeccomp) []annotatedRule {
+	rules := make([]annotatedRule, 0, len(profile.Syscalls))
+	for _, rule := range profile.Syscalls {
+		annotated := annotatedRule{LinuxSyscall: rule}
+		for _, name := range rule.Names {
+			if p, ok := provenances[name]; ok {
+				if annotated.Provenance == nil {
+					annotated.Provenance = make(map[string]syscallProvenance)
+				}
+				annotated.Provenance[name] = p
+			}
+		}
+		rules = append(rules, annotated)
+	}
+	return rules
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+func init() {
+	registerFormatter(formatFunc{"quadlet", ".container", writeQuadlet})
+	registerFormatter(formatFunc{"podman-label", ".containerfile", writePodmanLabel})
+}
+
+// image label podman takes the profile of the containers of the image from, with --seccomp-policy=image
+const podmanSeccompLabel = "io.containers.seccomp.profile"
+
+// writeQuadlet writes the [Container] section of a podman quadlet giving the container the profile, to merge
+// into its .container file. podman takes the oci profile written along with it as is.
+func writeQuadlet(w io.Writer, profile specs.LinuxSeccomp, profilePath string) error {
+	// quadlet units run from anywhere, the profile path must be absolute
+	path, err := filepath.Abs(formatOutputPath(profilePath, "oci", 2))
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "# Generated by go2seccomp for %v.\n"+
+		"# Merge into the [Container] section of /etc/containers/systemd/<name>.container.\n"+
+		"[Container]\nSeccompProfile=%v\n", analyzedBinary, path)
+	return err
+}
+
+// writePodmanLabel writes the Containerfile LABEL embedding the profile in the image, which podman applies to
+// the containers run with --seccomp-policy=image
+func writePodmanLabel(w io.Writer, profile specs.LinuxSeccomp, profilePath string) error {
+	data, err := json.Marshal(profile)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "# Generated by go2seccomp for %v.\n"+
+		"# Add to the Containerfile of the image, and run its containers with podman run --seccomp-policy=image.\n"+
+		"LABEL %v=%v\n", analyzedBinary, podmanSeccompLabel, strconv.Quote(string(data)))
+	return err
+}
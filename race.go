@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+// syscalls made by the ThreadSanitizer runtime linked in -race builds. It's C code passing the syscall
+// numbers around in registers, so most of them can't be found in the disassembly.
+var raceSyscalls = []string{
+	"arch_prctl", "clock_gettime", "clone", "close", "dup", "dup2", "dup3", "execve", "exit_group", "fstat",
+	"ftruncate", "futex", "getdents64", "getpid", "getppid", "getrandom", "getrlimit", "gettid", "gettimeofday",
+	"kill", "lseek", "madvise", "mmap", "mprotect", "mremap", "munmap", "nanosleep", "newfstatat", "openat",
+	"personality", "pipe2", "prctl", "prlimit64", "ptrace", "read", "readlinkat", "renameat", "rt_sigaction",
+	"rt_sigprocmask", "sched_getaffinity", "sched_yield", "setrlimit", "sigaltstack", "tgkill", "uname",
+	"unlinkat", "wait4", "write",
+}
+
+// whether the analyzed binary was built with -race
+var raceBuild bool
+
+// the functions of the race detector runtime, C++ ones have mangled names
+var raceRuntimePrefixes = []string{"runtime/race", "__tsan_", "__sanitizer", "_ZN6__tsan", "_ZN11__sanitizer"}
+
+func isRaceRuntimeFunction(function string) bool {
+	for _, prefix := range raceRuntimePrefixes {
+		if strings.HasPrefix(function, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// isRaceBuild tells whether the binary links the race detector runtime
+func isRaceBuild(graph *callGraph) bool {
+	if _, ok := graph.syscalls["runtime.raceinit"]; ok {
+		return true
+	}
+	for function := range graph.syscalls {
+		if isRaceRuntimeFunction(function) {
+			return true
+		}
+	}
+	return false
+}
+
+// raceRuntimeSyscalls returns the syscalls of the race detector runtime that exist on the arch
+func raceRuntimeSyscalls(arch specs.Arch) []string {
+	archNames := make(map[string]bool)
+	for _, name := range syscallIDtoName[arch] {
+		archNames[name] = true
+	}
+	var names []string
+	for _, name := range raceSyscalls {
+		if archNames[name] {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func reportRaceBuild() {
+	if raceBuild {
+		fmt.Println("Race detector build, the syscalls of its runtime are added to the profile")
+	}
+}
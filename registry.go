@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+func init() {
+	commands["push"] = pushCommand
+	commands["pull"] = pullCommand
+}
+
+// annotation of the pushed profiles with the image they're for
+const profileSubjectAnnotation = "io.go2seccomp.subject"
+
+// registryReference strips the oci:// prefix of a reference, which is optional
+func registryReference(reference string) string {
+	return strings.TrimPrefix(reference, "oci://")
+}
+
+// pushCommand pushes a profile to a registry as an OCI artifact, with oras. With -subject it's also attached to
+// the image it's for, where the NRI plugin and pull -subject find it.
+func pushCommand(args []string) {
+	fs := flag.NewFlagSet("push", flag.ExitOnError)
+	subject := fs.String("subject", "", "image the profile is for, by digest, to attach the profile to as well")
+	parseFlags(fs, args)
+
+	if fs.NArg() != 2 {
+		fmt.Println("Usage: go2seccomp push [-subject registry/org/app@sha256:...] profile.json oci://registry/org/app-profile:tag")
+		os.Exit(1)
+	}
+	profilePath, reference := fs.Arg(0), registryReference(fs.Arg(1))
+
+	profile := loadProfile(profilePath)
+	if errs := validateProfile(profile); len(errs) > 0 {
+		for _, err := range errs {
+			fmt.Printf("%v: %v\n", profilePath, err)
+		}
+		os.Exit(1)
+	}
+	if *subject != "" && !strings.Contains(*subject, "@sha256:") {
+		fatalf("-subject %v has no digest, give the image as registry/org/app@sha256:...", *subject)
+	}
+
+	// oras names the file in the artifact after the path it's given, push it from its directory
+	orasArgs := []string{"push", "--artifact-type", profileArtifactType}
+	if *subject != "" {
+		orasArgs = append(orasArgs, "--annotation", profileSubjectAnnotation+"="+*subject)
+	}
+	cmd := exec.Command("oras", append(orasArgs, reference, filepath.Base(profilePath)+":application/json")...)
+	cmd.Dir = filepath.Dir(profilePath)
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		fatalf("Failed to push %v to %v: %v", profilePath, reference, err)
+	}
+	fmt.Printf("Pushed %v to %v\n", profilePath, reference)
+
+	if *subject != "" {
+		attachProfile(*subject, profilePath)
+	}
+}
+
+// pullCommand pulls a profile pushed with push, or the one attached to an image with -subject
+func pullCommand(args []string) {
+	fs := flag.NewFlagSet("pull", flag.ExitOnError)
+	output := fs.String("o", "profile.json", "path to write the profile to")
+	subject := fs.String("subject", "", "image to pull the attached profile of, instead of a profile reference")
+	parseFlags(fs, args)
+
+	if (fs.NArg() == 1) == (*subject != "") {
+		fmt.Println("Usage: go2seccomp pull [-o profile.json] oci://registry/org/app-profile:tag")
+		fmt.Println("       go2seccomp pull [-o profile.json] -subject registry/org/app@sha256:...")
+		os.Exit(1)
+	}
+
+	dir, err := ioutil.TempDir("", "go2seccomp-pull")
+	if err != nil {
+		fatalf("Failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	source := *subject
+	var profile *specs.LinuxSeccomp
+	if *subject != "" {
+		profile, err = fetchAttachedProfile(context.Background(), *subject, dir)
+		if err == nil && profile == nil {
+			err = fmt.Errorf("no profile is attached to it")
+		}
+	} else {
+		source = fs.Arg(0)
+		profile, err = pullProfile(context.Background(), registryReference(source), dir)
+	}
+	if err != nil {
+		os.RemoveAll(dir)
+		fatalf("Failed to pull the profile of %v: %v", source, err)
+	}
+
+	var buf bytes.Buffer
+	if err := writeOCIProfile(&buf, *profile, *output); err != nil {
+		os.RemoveAll(dir)
+		fatalf("Failed to write seccomp profile: %v", err)
+	}
+	writeProfileFile(*output, buf.Bytes())
+	fmt.Printf("Saved seccomp profile of %v at %v\n", source, *output)
+}
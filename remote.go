@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+var binarySHA256 = flag.String("binary-sha256", "", "sha256 the binary downloaded from a URL must have")
+var binaryChecksums = flag.String("binary-checksums", "", "checksums file (sha256sum output, like the SHA256SUMS of releases), path or URL, with the sha256 the downloaded binary must have")
+
+// the sha256 of the downloaded binary, for the attestations and lock files written once it's removed
+var remoteBinarySHA256 string
+
+// isRemoteBinary tells if the binary is given as a URL to download it from
+func isRemoteBinary(binary string) bool {
+	for _, scheme := range []string{"https://", "http://", "s3://", "oci://"} {
+		if strings.HasPrefix(binary, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteBinaryName returns the file name of the binary at the URL, to name the profile after it
+func remoteBinaryName(binaryURL string) string {
+	if strings.HasPrefix(binaryURL, "oci://") {
+		return path.Base(imageRepository(strings.TrimPrefix(binaryURL, "oci://")))
+	}
+	if u, err := url.Parse(binaryURL); err == nil {
+		return path.Base(u.Path)
+	}
+	return path.Base(binaryURL)
+}
+
+// analyzedBinarySHA256 returns the sha256 of the analyzed binary
+func analyzedBinarySHA256() string {
+	if remoteBinarySHA256 != "" {
+		return remoteBinarySHA256
+	}
+	return fileSHA256(analyzedBinary)
+}
+
+// fetchRemoteBinary downloads the binary to a temporary file and checks it against -binary-sha256 or
+// -binary-checksums: over HTTP, from S3 with the aws CLI, or from an OCI artifact with oras
+func fetchRemoteBinary(binaryURL string) string {
+	fmt.Printf("Downloading %v\n", binaryURL)
+	var downloaded string
+	var err error
+	if strings.HasPrefix(binaryURL, "oci://") {
+		downloaded, err = pullArtifactBinary(strings.TrimPrefix(binaryURL, "oci://"))
+	} else {
+		downloaded, err = downloadFile(binaryURL)
+	}
+	if err != nil {
+		fatalf("Failed to download %v: %v", binaryURL, err)
+	}
+
+	remoteBinarySHA256 = fileSHA256(downloaded)
+	expected, err := expectedBinarySHA256(remoteBinaryName(binaryURL))
+	if err != nil {
+		os.Remove(downloaded)
+		fatalf("Failed to read the checksum of %v: %v", binaryURL, err)
+	}
+	switch {
+	case expected == "":
+		warnf("%v isn't verified, give its checksum with -binary-sha256 or -binary-checksums", binaryURL)
+	case !strings.EqualFold(expected, remoteBinarySHA256):
+		os.Remove(downloaded)
+		fatalf("%v has sha256 %v, expected %v", binaryURL, remoteBinarySHA256, expected)
+	default:
+		fmt.Printf("Verified the sha256 of %v\n", binaryURL)
+	}
+	return downloaded
+}
+
+// expectedBinarySHA256 returns the sha256 given with -binary-sha256, or the one of the file in -binary-checksums
+func expectedBinarySHA256(name string) (string, error) {
+	if *binarySHA256 != "" || *binaryChecksums == "" {
+		return *binarySHA256, nil
+	}
+
+	checksums := *binaryChecksums
+	if isRemoteBinary(checksums) {
+		downloaded, err := downloadFile(checksums)
+		if err != nil {
+			return "", err
+		}
+		defer os.Remove(downloaded)
+		checksums = downloaded
+	}
+	data, err := ioutil.ReadFile(checksums)
+	if err != nil {
+		return "", err
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		// "<sha256>  name", or "<sha256> *name" for binary mode
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return fields[0], nil
+		}
+	}
+	return "", fmt.Errorf("%v isn't in %v", name, *binaryChecksums)
+}
+
+// downloadFile downloads the http(s) or s3 URL to a temporary file
+func downloadFile(fileURL string) (string, error) {
+	tmp, err := ioutil.TempFile("", "go2seccomp-download")
+	if err != nil {
+		return "", err
+	}
+	defer tmp.Close()
+
+	if strings.HasPrefix(fileURL, "s3://") {
+		cmd := exec.Command("aws", "s3", "cp", "--only-show-errors", fileURL, tmp.Name())
+		cmd.Stderr = os.Stderr
+		err = cmd.Run()
+		if err != nil {
+			err = fmt.Errorf("aws s3 cp failed: %v", err)
+		}
+	} else {
+		err = httpDownload(fileURL, tmp)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	return tmp.Name(), nil
+}
+
+func httpDownload(fileURL string, w io.Writer) error {
+	resp, err := newHTTPClient().Get(fileURL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%v", resp.Status)
+	}
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+// pullArtifactBinary pulls the OCI artifact with oras and returns its Go binary, moved to a temporary file
+func pullArtifactBinary(reference string) (string, error) {
+	dir, err := ioutil.TempDir("", "go2seccomp-artifact")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(dir)
+
+	cmd := exec.Command("oras", "pull", "-o", dir, reference)
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("oras pull failed: %v", err)
+	}
+
+	var binaries []string
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && info.Mode().IsRegular() && isGoELF(path) {
+			binaries = append(binaries, path)
+		}
+		return nil
+	})
+	if len(binaries) != 1 {
+		return "", fmt.Errorf("expected a single Go binary in the artifact, found %v", len(binaries))
+	}
+
+	tmp, err := ioutil.TempFile("", "go2seccomp-download")
+	if err != nil {
+		return "", err
+	}
+	tmp.Close()
+	data, err := ioutil.ReadFile(binaries[0])
+	if err == nil {
+		err = ioutil.WriteFile(tmp.Name(), data, 0600)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	return tmp.Name(), nil
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+// report explains where each syscall in a profile comes from
+type report struct {
+	Binary   string          `json:"binary"`
+	Arch     specs.Arch      `json:"arch"`
+	Coverage map[string]int  `json:"coverage"`
+	Syscalls []syscallReport `json:"syscalls"`
+	// calls whose arguments tell how syscalls are used, e.g. the protection of mmap
+	ArgumentSites []argSite `json:"argumentSites,omitempty"`
+	// syscalls creating, entering or changing namespaces, for container security reviews
+	Namespaces []namespaceUse `json:"namespaces,omitempty"`
+	// syscalls whose ID couldn't be found, with the instructions before them
+	Unresolved []unresolvedSite `json:"unresolved,omitempty"`
+	// syscalls only added as fallbacks, with the newer syscalls they're fallbacks of
+	FallbackOnly map[string][]string `json:"fallbackOnly,omitempty"`
+	// the syscalls summarized in OpenBSD pledge promises, and the ones no promise would grant
+	Promises     []string `json:"promises"`
+	BeyondPledge []string `json:"beyondPledge,omitempty"`
+}
+
+type syscallReport struct {
+	Name    string      `json:"name"`
+	ID      int64       `json:"id"`
+	Sources []string    `json:"sources"`
+	Risk    syscallRisk `json:"risk"`
+	// the most confident of the detections, default for the default syscalls, empty for base and added ones
+	Confidence string `json:"confidence,omitempty"`
+	// number of distinct call sites, telling core syscalls from one-off uses
+	CallSites int           `json:"callSites"`
+	Sites     []syscallSite `json:"sites,omitempty"`
+}
+
+// buildReport reports every syscall of the profile given with their sources by syscallSources, with their call
+// sites in the given functions (or in all of them if nil), the most called first. ids are the detected ones.
+func buildReport(binaryPath string, graph *callGraph, arch specs.Arch, sources map[string][]string, ids map[int64]bool, functions map[string]bool) *report {
+	nameToID := make(map[string]int64)
+	for id, name := range syscallIDtoName[arch] {
+		nameToID[name] = id
+	}
+
+	r := &report{Binary: binaryPath, Arch: arch, Coverage: make(map[string]int)}
+	for _, name := range sortedNames(sources) {
+		s := syscallReport{Name: name, ID: -1, Sources: sources[name], Risk: riskOf(name)}
+		if id, ok := nameToID[name]; ok {
+			s.ID = id
+			s.Sites = graph.sitesOf(id, functions)
+			s.CallSites = len(s.Sites)
+			for _, site := range s.Sites {
+				s.Confidence = mostConfident(s.Confidence, site.Confidence)
+			}
+		}
+		if s.Confidence == "" {
+			for _, source := range s.Sources {
+				if source == sourceDefault {
+					s.Confidence = confidenceDefault
+				}
+			}
+		}
+		for _, source := range s.Sources {
+			r.Coverage[source]++
+		}
+		r.Syscalls = append(r.Syscalls, s)
+	}
+	for _, site := range graph.argSites {
+		if functions == nil || functions[site.Function] {
+			r.ArgumentSites = append(r.ArgumentSites, site)
+		}
+	}
+	r.Namespaces = namespaceUses(graph, arch, ids, functions)
+	r.Unresolved = graph.unresolvedIn(functions)
+	r.FallbackOnly = fallbackOnly
+	r.Promises, r.BeyondPledge = promisesOf(sortedNames(sources))
+	sort.SliceStable(r.Syscalls, func(i, j int) bool { return r.Syscalls[i].CallSites > r.Syscalls[j].CallSites })
+	return r
+}
+
+// writeReport saves the report as HTML if reportPath ends with .html, or as JSON otherwise
+func writeReport(r *report, reportPath string) {
+	reportFile, err := os.Create(reportPath)
+	if err != nil {
+		fatalf("Failed to create report: %v", err)
+	}
+	defer reportFile.Close()
+
+	if filepath.Ext(reportPath) == ".html" {
+		err = writeHTMLReport(r, reportFile)
+	} else {
+		enc := json.NewEncoder(reportFile)
+		enc.SetIndent("", "    ")
+		err = enc.Encode(r)
+	}
+	if err != nil {
+		fatalf("Failed to write report: %v", err)
+	}
+	fmt.Printf("Saved report at %v\n", reportPath)
+}
+
+var htmlReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>go2seccomp report for {{.Binary}}</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; }
+td, th { border: 1px solid #ccc; padding: 4px 8px; text-align: left; vertical-align: top; }
+</style>
+</head>
+<body>
+<h1>{{.Binary}} ({{.Arch}})</h1>
+<p>Pledge promises: {{range .Promises}}{{.}} {{end}}{{if .BeyondPledge}}, beyond any promise: {{range .BeyondPledge}}{{.}} {{end}}{{end}}</p>
+<h2>Coverage</h2>
+<table>
+<tr><th>Source</th><th>Syscalls</th></tr>
+{{range $source, $count := .Coverage}}<tr><td>{{$source}}</td><td>{{$count}}</td></tr>
+{{end}}</table>
+<h2>Syscalls</h2>
+<table>
+<tr><th>Name</th><th>ID</th><th>Sources</th><th>Risk</th><th>Confidence</th><th>Count</th><th>Call sites</th></tr>
+{{range .Syscalls}}<tr>
+<td>{{.Name}}</td><td>{{.ID}}</td><td>{{range .Sources}}{{.}} {{end}}</td>
+<td>{{.Risk.Tier}}{{if .Risk.Reason}}: <a href="{{.Risk.Link}}">{{.Risk.Reason}}</a>{{end}}</td>
+<td>{{.Confidence}}</td><td>{{.CallSites}}</td>
+<td>{{range .Sites}}{{.Function}} at {{.Location}}<br>{{end}}</td>
+</tr>
+{{end}}</table>
+{{if .Namespaces}}<h2>Namespace syscalls</h2>
+<table>
+<tr><th>Syscall</th><th>Flags</th><th>Call sites</th></tr>
+{{range .Namespaces}}<tr><td>{{.Syscall}}</td><td>{{range .Flags}}{{.}} {{end}}</td><td>{{range .Sites}}{{.}}<br>{{end}}</td></tr>
+{{end}}</table>
+{{end}}</body>
+</html>
+`))
+
+func writeHTMLReport(r *report, w io.Writer) error {
+	return htmlReportTemplate.Execute(w, r)
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+var interactive = flag.Bool("interactive", false, "review each syscall before writing the profile, accepting, denying or annotating it")
+
+// syscalls denied during the -interactive review, they get an explicit SCMP_ACT_ERRNO rule
+var deniedSyscalls []string
+
+// reviewSyscalls walks the reviewer through the syscalls, the riskiest first, with where they come from,
+// and returns the accepted ones. Notes are kept in the provenance of the syscalls, written with -annotate.
+func reviewSyscalls(syscallsList []string, in io.Reader) []string {
+	names := append([]string(nil), syscallsList...)
+	rankByRisk(names)
+	reader := bufio.NewReader(in)
+
+	denied := make(map[string]bool)
+	acceptRest := false
+	for i, name := range names {
+		if acceptRest {
+			break
+		}
+		risk := riskOf(name)
+		fmt.Printf("\n[%v/%v] %v, risk %v", i+1, len(names), name, risk.Tier)
+		if risk.Reason != "" {
+			fmt.Printf(": %v", risk.Reason)
+		}
+		fmt.Println()
+		if p, ok := provenances[name]; ok {
+			fmt.Printf("    from: %v\n", strings.Join(p.Sources, ", "))
+			if p.Site != "" {
+				fmt.Printf("    site: %v\n", p.Site)
+			}
+		}
+
+		for {
+			fmt.Print("(a)ccept, (d)eny, (n)ote <text>, (A)ccept the rest: ")
+			line, err := reader.ReadString('\n')
+			if err == io.EOF && line == "" {
+				fmt.Println("\nEnd of input, accepting the rest")
+				acceptRest = true
+				break
+			}
+			line = strings.TrimSpace(line)
+			switch {
+			case line == "a" || line == "":
+			case line == "d":
+				denied[name] = true
+			case line == "A":
+				acceptRest = true
+			case strings.HasPrefix(line, "n "):
+				if provenances == nil {
+					provenances = make(map[string]syscallProvenance)
+				}
+				p := provenances[name]
+				p.Note = strings.TrimSpace(line[2:])
+				provenances[name] = p
+				*annotate = true
+				continue
+			default:
+				continue
+			}
+			break
+		}
+	}
+
+	var accepted []string
+	for _, name := range syscallsList {
+		if denied[name] {
+			deniedSyscalls = append(deniedSyscalls, name)
+		} else {
+			accepted = append(accepted, name)
+		}
+	}
+	sort.Strings(deniedSyscalls)
+	fmt.Printf("\nAccepted %v syscalls, denied %v: %v\n", len(accepted), len(deniedSyscalls), deniedSyscalls)
+	return accepted
+}
+
+// applyDenied adds a SCMP_ACT_ERRNO rule for the syscalls denied in the review, so the decision is
+// recorded in the profile whatever its default action
+func applyDenied(profile specs.LinuxSeccomp) specs.LinuxSeccomp {
+	if len(deniedSyscalls) == 0 {
+		return profile
+	}
+	profile.Syscalls = append(profile.Syscalls, specs.LinuxSyscall{Names: deniedSyscalls, Action: specs.ActErrno})
+	return profile
+}
+
+func reviewIfInteractive(syscallsList []string) []string {
+	if !*interactive {
+		return syscallsList
+	}
+	return reviewSyscalls(syscallsList, os.Stdin)
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+)
+
+func init() {
+	commands["risk"] = riskCommand
+}
+
+// risk tiers, from most to least relevant for security review
+const (
+	riskHigh   = "high"
+	riskMedium = "medium"
+	riskLow    = "low"
+)
+
+var riskOrder = map[string]int{riskHigh: 0, riskMedium: 1, riskLow: 2}
+
+type syscallRisk struct {
+	Tier   string `json:"tier"`
+	Reason string `json:"reason,omitempty"`
+	Link   string `json:"link,omitempty"`
+}
+
+// Docker's documentation explains why its default profile blocks most of the high risk syscalls
+const dockerSeccompDocs = "https://docs.docker.com/engine/security/seccomp/"
+
+// syscalls relevant to container escapes and kernel attack surface. Anything not listed is low risk.
+var syscallRisks = map[string]syscallRisk{
+	"acct":              {riskHigh, "switches process accounting on and off", dockerSeccompDocs},
+	"add_key":           {riskHigh, "kernel keyring isn't namespaced", dockerSeccompDocs},
+	"bpf":               {riskHigh, "loads programs into the kernel", dockerSeccompDocs},
+	"clock_adjtime":     {riskHigh, "time isn't namespaced", dockerSeccompDocs},
+	"clock_settime":     {riskHigh, "time isn't namespaced", dockerSeccompDocs},
+	"clone3":            {riskMedium, "can create namespaces", dockerSeccompDocs},
+	"create_module":     {riskHigh, "kernel module loading", dockerSeccompDocs},
+	"delete_module":     {riskHigh, "kernel module unloading", dockerSeccompDocs},
+	"finit_module":      {riskHigh, "kernel module loading", dockerSeccompDocs},
+	"fsconfig":          {riskHigh, "filesystem mounting", "https://man7.org/linux/man-pages/man2/fsconfig.2.html"},
+	"fsmount":           {riskHigh, "filesystem mounting", "https://man7.org/linux/man-pages/man2/fsmount.2.html"},
+	"fsopen":            {riskHigh, "filesystem mounting", "https://man7.org/linux/man-pages/man2/fsopen.2.html"},
+	"get_kernel_syms":   {riskHigh, "exposes kernel and module symbols", dockerSeccompDocs},
+	"get_mempolicy":     {riskMedium, "modifies kernel memory and NUMA settings", dockerSeccompDocs},
+	"init_module":       {riskHigh, "kernel module loading", dockerSeccompDocs},
+	"io_uring_enter":    {riskHigh, "large kernel attack surface, source of many privilege escalations", "https://man7.org/linux/man-pages/man7/io_uring.7.html"},
+	"io_uring_register": {riskHigh, "large kernel attack surface, source of many privilege escalations", "https://man7.org/linux/man-pages/man7/io_uring.7.html"},
+	"io_uring_setup":    {riskHigh, "large kernel attack surface, source of many privilege escalations", "https://man7.org/linux/man-pages/man7/io_uring.7.html"},
+	"ioperm":            {riskHigh, "access to I/O ports", dockerSeccompDocs},
+	"iopl":              {riskHigh, "access to I/O ports", dockerSeccompDocs},
+	"kcmp":              {riskMedium, "inspects other processes' kernel resources", dockerSeccompDocs},
+	"kexec_file_load":   {riskHigh, "loads a new kernel", dockerSeccompDocs},
+	"kexec_load":        {riskHigh, "loads a new kernel", dockerSeccompDocs},
+	"keyctl":            {riskHigh, "kernel keyring isn't namespaced", dockerSeccompDocs},
+	"lookup_dcookie":    {riskHigh, "tracing/profiling syscall, could leak information on the host", dockerSeccompDocs},
+	"mbind":             {riskMedium, "modifies kernel memory and NUMA settings", dockerSeccompDocs},
+	"mount":             {riskHigh, "filesystem mounting", dockerSeccompDocs},
+	"move_mount":        {riskHigh, "filesystem mounting", "https://man7.org/linux/man-pages/man2/move_mount.2.html"},
+	"move_pages":        {riskMedium, "modifies kernel memory and NUMA settings", dockerSeccompDocs},
+	"name_to_handle_at": {riskHigh, "file handles can be used to break out of containers", dockerSeccompDocs},
+	"nfsservctl":        {riskHigh, "interacts with the kernel nfs daemon", dockerSeccompDocs},
+	"open_by_handle_at": {riskHigh, "cause of an old container breakout", dockerSeccompDocs},
+	"open_tree":         {riskHigh, "filesystem mounting", "https://man7.org/linux/man-pages/man2/open_tree.2.html"},
+	"perf_event_open":   {riskHigh, "tracing/profiling syscall, could leak information on the host", dockerSeccompDocs},
+	"personality":       {riskMedium, "could enable bugs through emulation, restricted to known values by Docker", dockerSeccompDocs},
+	"pivot_root":        {riskHigh, "changes the root filesystem", dockerSeccompDocs},
+	"process_vm_readv":  {riskHigh, "inspects other processes' memory", dockerSeccompDocs},
+	"process_vm_writev": {riskHigh, "modifies other processes' memory", dockerSeccompDocs},
+	"ptrace":            {riskHigh, "traces and modifies other processes, bypassed seccomp before kernel 4.8", dockerSeccompDocs},
+	"query_module":      {riskHigh, "exposes kernel and module symbols", dockerSeccompDocs},
+	"quotactl":          {riskHigh, "quotas aren't namespaced", dockerSeccompDocs},
+	"reboot":            {riskHigh, "reboots the host", dockerSeccompDocs},
+	"request_key":       {riskHigh, "kernel keyring isn't namespaced", dockerSeccompDocs},
+	"set_mempolicy":     {riskMedium, "modifies kernel memory and NUMA settings", dockerSeccompDocs},
+	"setns":             {riskHigh, "joins other namespaces", dockerSeccompDocs},
+	"settimeofday":      {riskHigh, "time isn't namespaced", dockerSeccompDocs},
+	"stime":             {riskHigh, "time isn't namespaced", dockerSeccompDocs},
+	"swapoff":           {riskHigh, "host wide swap control", dockerSeccompDocs},
+	"swapon":            {riskHigh, "host wide swap control", dockerSeccompDocs},
+	"sysfs":             {riskMedium, "obsolete syscall", dockerSeccompDocs},
+	"_sysctl":           {riskHigh, "obsolete, replaced by /proc/sys", dockerSeccompDocs},
+	"umount":            {riskHigh, "filesystem mounting", dockerSeccompDocs},
+	"umount2":           {riskHigh, "filesystem mounting", dockerSeccompDocs},
+	"unshare":           {riskHigh, "creates new namespaces, including user namespaces", dockerSeccompDocs},
+	"uselib":            {riskHigh, "obsolete syscall related to shared libraries", dockerSeccompDocs},
+	"userfaultfd":       {riskHigh, "commonly used to make kernel exploits reliable", dockerSeccompDocs},
+	"ustat":             {riskMedium, "obsolete syscall", dockerSeccompDocs},
+	"vm86":              {riskHigh, "in kernel x86 real mode virtual machine", dockerSeccompDocs},
+	"vm86old":           {riskHigh, "in kernel x86 real mode virtual machine", dockerSeccompDocs},
+	"chroot":            {riskMedium, "changes the root directory", "https://man7.org/linux/man-pages/man2/chroot.2.html"},
+	"clone":             {riskMedium, "can create namespaces depending on its flags", "https://man7.org/linux/man-pages/man2/clone.2.html"},
+	"execve":            {riskMedium, "runs other programs", "https://man7.org/linux/man-pages/man2/execve.2.html"},
+	"execveat":          {riskMedium, "runs other programs", "https://man7.org/linux/man-pages/man2/execveat.2.html"},
+	"memfd_create":      {riskMedium, "creates anonymous executable files, often used by fileless malware", "https://man7.org/linux/man-pages/man2/memfd_create.2.html"},
+	"mknod":             {riskMedium, "creates device files", "https://man7.org/linux/man-pages/man2/mknod.2.html"},
+	"mknodat":           {riskMedium, "creates device files", "https://man7.org/linux/man-pages/man2/mknod.2.html"},
+	"prctl":             {riskMedium, "changes process attributes, including seccomp and capabilities", "https://man7.org/linux/man-pages/man2/prctl.2.html"},
+	"seccomp":           {riskMedium, "installs seccomp filters", "https://man7.org/linux/man-pages/man2/seccomp.2.html"},
+	"setgid":            {riskMedium, "changes the process identity", "https://man7.org/linux/man-pages/man2/setgid.2.html"},
+	"setgroups":         {riskMedium, "changes the process identity", "https://man7.org/linux/man-pages/man2/setgroups.2.html"},
+	"setresgid":         {riskMedium, "changes the process identity", "https://man7.org/linux/man-pages/man2/setresuid.2.html"},
+	"setresuid":         {riskMedium, "changes the process identity", "https://man7.org/linux/man-pages/man2/setresuid.2.html"},
+	"setuid":            {riskMedium, "changes the process identity", "https://man7.org/linux/man-pages/man2/setuid.2.html"},
+	"socket":            {riskMedium, "network access, including raw sockets with CAP_NET_RAW", "https://man7.org/linux/man-pages/man2/socket.2.html"},
+}
+
+// riskOf returns the risk tier of the syscall, defaulting to low
+func riskOf(name string) syscallRisk {
+	if r, ok := syscallRisks[name]; ok {
+		return r
+	}
+	return syscallRisk{Tier: riskLow}
+}
+
+// rankByRisk sorts the syscall names from most to least risky, by name within each tier
+func rankByRisk(names []string) {
+	sort.Slice(names, func(i, j int) bool {
+		ri, rj := riskOrder[riskOf(names[i]).Tier], riskOrder[riskOf(names[j]).Tier]
+		if ri != rj {
+			return ri < rj
+		}
+		return names[i] < names[j]
+	})
+}
+
+// riskCommand prints the syscalls allowed by a profile, or detected in a binary, ranked by risk
+func riskCommand(args []string) {
+	if len(args) < 1 {
+		fmt.Println("Usage: go2seccomp risk /path/to/profile.json|/path/to/binary")
+		os.Exit(1)
+	}
+
+	var names []string
+	if data, err := ioutil.ReadFile(args[0]); err == nil && json.Valid(data) {
+		names = loadAllowedSyscalls(args[0])
+	} else {
+		graph, arch := analyzeBinary(args[0])
+		names = getSyscallList(graph.allSyscalls(), arch)
+	}
+
+	rankByRisk(names)
+	for _, name := range names {
+		r := riskOf(name)
+		if r.Tier == riskLow {
+			fmt.Printf("%-8v %v\n", r.Tier, name)
+			continue
+		}
+		fmt.Printf("%-8v %v: %v (%v)\n", r.Tier, name, r.Reason, r.Link)
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"gopkg.in/yaml.v2"
+)
+
+// matcherRule is a user supplied detection rule, applied in addition to the built-in matchers.
+// A rule triggers either on a call to a function matching Call or on an instruction matching
+// Instruction, optionally restricted to functions matching Function. Once triggered, the
+// previous instructions are searched for ID, whose first capture group is the syscall ID.
+type matcherRule struct {
+	Name        string `yaml:"name"`
+	Arch        string `yaml:"arch"`
+	Function    string `yaml:"function"`
+	Call        string `yaml:"call"`
+	Instruction string `yaml:"instruction"`
+	ID          string `yaml:"id"`
+
+	arch        specs.Arch
+	function    *regexp.Regexp
+	call        *regexp.Regexp
+	instruction *regexp.Regexp
+	id          *regexp.Regexp
+}
+
+type rulesFile struct {
+	Rules []matcherRule `yaml:"rules"`
+}
+
+// rules loaded with the -rules flag
+var customRules []matcherRule
+
+// loadRules reads a YAML rules file, validating and compiling each rule
+func loadRules(path string) []matcherRule {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		fatalf("Failed to read rules file: %v", err)
+	}
+
+	var f rulesFile
+	if err := yaml.UnmarshalStrict(data, &f); err != nil {
+		fatalf("Failed to parse rules file %v: %v", path, err)
+	}
+
+	for i := range f.Rules {
+		r := &f.Rules[i]
+		if r.Name == "" {
+			r.Name = fmt.Sprintf("rule #%v", i+1)
+		}
+		if (r.Call == "") == (r.Instruction == "") {
+			fatalf("Rule %v: exactly one of call or instruction must be set", r.Name)
+		}
+		if r.ID == "" {
+			fatalf("Rule %v: id pattern is required", r.Name)
+		}
+		if r.Arch != "" {
+			arch, ok := archByName(r.Arch)
+			if !ok {
+				fatalf("Rule %v: unknown arch %v", r.Name, r.Arch)
+			}
+			r.arch = arch
+		}
+		if r.Function != "" {
+			r.function = compileGlob(r.Function)
+		}
+		if r.Call != "" {
+			r.call = compileGlob(r.Call)
+		}
+		if r.Instruction != "" {
+			r.instruction = compileRulePattern(r.Name, r.Instruction)
+		}
+		r.id = compileRulePattern(r.Name, r.ID)
+		if r.id.NumSubexp() < 1 {
+			fatalf("Rule %v: id pattern must have a capture group for the syscall ID", r.Name)
+		}
+	}
+
+	fmt.Printf("Loaded %v custom rules from %v\n", len(f.Rules), path)
+	return f.Rules
+}
+
+func compileRulePattern(name, pattern string) *regexp.Regexp {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		fatalf("Rule %v: invalid pattern %q: %v", name, pattern, err)
+	}
+	return re
+}
+
+// matches tells if the rule triggers on the instruction
+func (r *matcherRule) matches(arch specs.Arch, instruction, currentFunction string) bool {
+	if r.arch != "" && r.arch != arch {
+		return false
+	}
+	if r.function != nil && !r.function.MatchString(currentFunction) {
+		return false
+	}
+	if r.call != nil {
+		target := callTarget(arch, instruction)
+		return target != "" && r.call.MatchString(target)
+	}
+	return r.instruction.MatchString(instruction)
+}
+
+// findID goes back from the current instruction until the rule's id pattern matches
+func (r *matcherRule) findID(previouInstructions []string, curPos int) (int64, string, error) {
+	i := 0
+
+	for i < previousInstructionsBufferSize {
+		instruction := previouInstructions[curPos%previousInstructionsBufferSize]
+
+		if m := r.id.FindStringSubmatch(instruction); m != nil {
+			id, err := strconv.ParseInt(m[1], 0, 64)
+			if err != nil {
+				return -1, "", fmt.Errorf("Error parsing id %q with rule %v: %v", m[1], r.Name, err)
+			}
+			return id, windowConfidence(i), nil
+		}
+		i++
+		curPos--
+	}
+	return -1, "", fmt.Errorf("Failed to find syscall ID with rule %v", r.Name)
+}
+
+// globMatch matches symbol names against a pattern where * matches any sequence of characters,
+// including the / and . separators that path.Match treats specially
+func globMatch(pattern, name string) bool {
+	return compileGlob(pattern).MatchString(name)
+}
+
+// compileGlob compiles a globMatch pattern, for the ones matched against every instruction
+func compileGlob(pattern string) *regexp.Regexp {
+	quoted := regexp.QuoteMeta(pattern)
+	quoted = strings.Replace(quoted, `\*`, ".*", -1)
+	quoted = strings.Replace(quoted, `\?`, ".", -1)
+	return regexp.MustCompile("^" + quoted + "$")
+}
+
+// callTarget extracts the called symbol from a call instruction, or "" if it's not a call
+func callTarget(arch specs.Arch, instruction string) string {
+	j := getCallOpByArch(arch)
+	start := strings.Index(instruction, j)
+	if start == -1 {
+		return ""
+	}
+	target := instruction[start+len(j):]
+	end := strings.Index(target, "(SB)")
+	if end == -1 {
+		return ""
+	}
+	return target[:end]
+}
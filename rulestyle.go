@@ -0,0 +1,115 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+// how the allowed syscalls are split into rules, selected with -rule-style
+const (
+	// a single rule with every syscall
+	ruleStyleSingle = "single"
+	// a rule per syscall
+	ruleStyleGrouped = "grouped"
+	// a rule per category, see syscallCategories
+	ruleStyleCategory = "category"
+)
+
+var ruleStyle = flag.String("rule-style", ruleStyleSingle, "how the allowed syscalls are split into rules: single (one rule), grouped (one per syscall) or category")
+
+// categories of the syscalls for -rule-style category, in the order the rules are written. Syscalls
+// not listed go in a last "other" rule.
+var syscallCategories = []struct {
+	name     string
+	syscalls []string
+}{
+	{"file", []string{
+		"open", "openat", "openat2", "creat", "close", "close_range", "read", "readv", "pread64", "preadv", "preadv2",
+		"write", "writev", "pwrite64", "pwritev", "pwritev2", "lseek", "_llseek", "stat", "fstat", "lstat", "newfstatat",
+		"statx", "stat64", "fstat64", "lstat64", "fstatat64", "statfs", "fstatfs", "statfs64", "fstatfs64", "access",
+		"faccessat", "faccessat2", "readlink", "readlinkat", "getdents", "getdents64", "mkdir", "mkdirat", "rmdir",
+		"unlink", "unlinkat", "rename", "renameat", "renameat2", "link", "linkat", "symlink", "symlinkat", "chmod",
+		"fchmod", "fchmodat", "fchmodat2", "chown", "fchown", "lchown", "fchownat", "chown32", "fchown32", "lchown32",
+		"truncate", "ftruncate", "truncate64", "ftruncate64", "fsync", "fdatasync", "sync", "syncfs", "sync_file_range",
+		"fallocate", "fadvise64", "fadvise64_64", "arm_fadvise64_64", "flock", "fcntl", "fcntl64", "ioctl", "dup",
+		"dup2", "dup3", "pipe", "pipe2", "splice", "tee", "vmsplice", "sendfile", "sendfile64", "copy_file_range",
+		"getcwd", "chdir", "fchdir", "chroot", "utime", "utimes", "utimensat", "futimesat", "getxattr", "lgetxattr",
+		"fgetxattr", "setxattr", "lsetxattr", "fsetxattr", "listxattr", "llistxattr", "flistxattr", "removexattr",
+		"lremovexattr", "fremovexattr", "umask", "mknod", "mknodat", "inotify_init", "inotify_init1",
+		"inotify_add_watch", "inotify_rm_watch", "fanotify_init", "fanotify_mark", "mount", "umount2",
+	}},
+	{"network", []string{
+		"socket", "socketpair", "bind", "listen", "accept", "accept4", "connect", "getsockname", "getpeername",
+		"getsockopt", "setsockopt", "sendto", "recvfrom", "sendmsg", "recvmsg", "sendmmsg", "recvmmsg", "shutdown",
+		"socketcall", "send", "recv",
+	}},
+	{"event", []string{
+		"epoll_create", "epoll_create1", "epoll_ctl", "epoll_wait", "epoll_pwait", "epoll_pwait2", "poll", "ppoll",
+		"select", "_newselect", "pselect6", "eventfd", "eventfd2", "signalfd", "signalfd4", "timerfd_create",
+		"timerfd_settime", "timerfd_gettime", "io_uring_setup", "io_uring_enter", "io_uring_register",
+	}},
+	{"memory", []string{
+		"mmap", "mmap2", "munmap", "mprotect", "mremap", "madvise", "mincore", "mlock", "mlock2", "munlock",
+		"mlockall", "munlockall", "msync", "brk", "membarrier", "memfd_create", "mbind", "set_mempolicy",
+		"get_mempolicy", "pkey_mprotect", "pkey_alloc", "pkey_free",
+	}},
+	{"process", []string{
+		"clone", "clone3", "fork", "vfork", "execve", "execveat", "exit", "exit_group", "wait4", "waitid", "kill",
+		"tkill", "tgkill", "getpid", "getppid", "gettid", "getuid", "geteuid", "getgid", "getegid", "getuid32",
+		"geteuid32", "getgid32", "getegid32", "getresuid", "getresgid", "getgroups", "setuid", "setgid", "setresuid",
+		"setresgid", "setgroups", "setsid", "getsid", "setpgid", "getpgid", "getpgrp", "prctl", "arch_prctl",
+		"set_tid_address", "set_robust_list", "get_robust_list", "futex", "futex_time64", "futex_waitv",
+		"sched_yield", "sched_getaffinity", "sched_setaffinity", "sched_getparam", "sched_setparam",
+		"sched_getscheduler", "sched_setscheduler", "getpriority", "setpriority", "getrlimit", "setrlimit",
+		"prlimit64", "ugetrlimit", "getrusage", "capget", "capset", "pidfd_open", "pidfd_send_signal",
+		"pidfd_getfd", "unshare", "setns", "rseq", "seccomp", "ptrace", "personality", "uname", "sysinfo",
+		"getrandom",
+	}},
+	{"signal", []string{
+		"rt_sigaction", "rt_sigprocmask", "rt_sigreturn", "rt_sigsuspend", "rt_sigpending", "rt_sigtimedwait",
+		"rt_sigqueueinfo", "rt_tgsigqueueinfo", "sigaltstack", "sigreturn", "sigaction", "sigprocmask", "pause",
+		"restart_syscall",
+	}},
+	{"time", []string{
+		"clock_gettime", "clock_getres", "clock_nanosleep", "clock_gettime64", "clock_nanosleep_time64",
+		"gettimeofday", "time", "nanosleep", "timer_create", "timer_settime", "timer_gettime", "timer_delete",
+		"timer_getoverrun", "alarm", "setitimer", "getitimer", "times",
+	}},
+}
+
+// styleRules splits the allowed syscalls into rules according to -rule-style
+func styleRules(syscallsList []string) []specs.LinuxSyscall {
+	switch *ruleStyle {
+	case ruleStyleGrouped:
+		rules := make([]specs.LinuxSyscall, 0, len(syscallsList))
+		for _, name := range syscallsList {
+			rules = append(rules, specs.LinuxSyscall{Names: []string{name}, Action: specs.ActAllow})
+		}
+		return rules
+	case ruleStyleCategory:
+		categoryOf := make(map[string]int)
+		for i, category := range syscallCategories {
+			for _, name := range category.syscalls {
+				categoryOf[name] = i
+			}
+		}
+		names := make([][]string, len(syscallCategories)+1)
+		for _, name := range syscallsList {
+			i, ok := categoryOf[name]
+			if !ok {
+				i = len(syscallCategories)
+			}
+			names[i] = append(names[i], name)
+		}
+		var rules []specs.LinuxSyscall
+		for _, n := range names {
+			if len(n) > 0 {
+				rules = append(rules, specs.LinuxSyscall{Names: n, Action: specs.ActAllow})
+			}
+		}
+		return rules
+	default:
+		return []specs.LinuxSyscall{{Names: syscallsList, Action: specs.ActAllow}}
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+// the prctl option installing a seccomp filter
+const prSetSeccomp = 22
+
+// packages installing seccomp filters for the programs using them
+var sandboxPackages = []string{
+	"github.com/seccomp/libseccomp-golang.",
+	"github.com/elastic/go-seccomp-bpf.",
+}
+
+// the syscalls a program needs to install its own seccomp filter
+var sandboxSyscalls = []string{"seccomp", "prctl"}
+
+// whether the analyzed binary installs its own seccomp filter
+var selfSandboxing bool
+
+// detectSelfSandbox looks in the given functions (all if nil) for the binary installing its own seccomp
+// filter, with the seccomp syscall, prctl(PR_SET_SECCOMP) or a seccomp package, and warns that the filters
+// stack. The syscalls it needs are then added to the profile.
+func detectSelfSandbox(graph *callGraph, arch specs.Arch, ids map[int64]bool, functions map[string]bool) {
+	var reasons []string
+	for id := range ids {
+		if syscallIDtoName[arch][id] == "seccomp" {
+			reasons = append(reasons, "it calls seccomp")
+		}
+	}
+	for _, site := range graph.argSitesOf("prctl", functions) {
+		if site.Known && site.Value == prSetSeccomp {
+			reasons = append(reasons, fmt.Sprintf("%v calls prctl(PR_SET_SECCOMP)", site.Function))
+			break
+		}
+	}
+	for _, pkg := range sandboxPackages {
+		for function := range graph.syscalls {
+			if strings.HasPrefix(function, pkg) && (functions == nil || functions[function]) {
+				reasons = append(reasons, "it uses "+strings.TrimSuffix(pkg, "."))
+				break
+			}
+		}
+	}
+
+	selfSandboxing = len(reasons) > 0
+	if !selfSandboxing {
+		return
+	}
+	warnf("the binary installs its own seccomp filter (%v), the profile allows %v for it", strings.Join(reasons, ", "), strings.Join(sandboxSyscalls, " and "))
+	warnf("the filters stack, a syscall only goes through if both allow it and the strictest action wins")
+}
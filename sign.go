@@ -0,0 +1,34 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+var sign = flag.Bool("sign", false, "sign each profile with cosign, writing the signature bundle next to it (keyless unless -sign-key is set)")
+var signKey = flag.String("sign-key", "", "cosign private key (or KMS URI) to sign the profiles with instead of keyless signing")
+
+// signatureBundlePath returns where the cosign bundle of a profile is written
+func signatureBundlePath(profilePath string) string {
+	return profilePath + ".sigstore.json"
+}
+
+// signProfile signs the profile with cosign sign-blob, writing the bundle with the signature and
+// certificate (or key reference) that cosign verify-blob checks the profile against
+func signProfile(profilePath string) {
+	args := []string{"sign-blob", "--yes", "--bundle", signatureBundlePath(profilePath)}
+	if *signKey != "" {
+		args = append(args, "--key", *signKey)
+	}
+	args = append(args, profilePath)
+
+	cmd := exec.Command("cosign", args...)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		fatalf("Failed to sign %v: %v", profilePath, err)
+	}
+	fmt.Printf("Saved signature bundle at %v\n", signatureBundlePath(profilePath))
+}
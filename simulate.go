@@ -0,0 +1,118 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+func init() {
+	commands["simulate"] = simulateCommand
+}
+
+// simulateCommand reports the action a profile takes for a syscall, with the given arguments, running the
+// profile compiled to a BPF filter
+func simulateCommand(args []string) {
+	fs := flag.NewFlagSet("simulate", flag.ExitOnError)
+	syscallName := fs.String("syscall", "", "syscall name or number to simulate")
+	archName := fs.String("arch", "amd64", "architecture the syscall is made on (amd64, 386, arm, arm64, ppc64le, mips, mipsle, mips64, mips64le)")
+	var argValues [6]*string
+	for i := range argValues {
+		argValues[i] = fs.String(fmt.Sprintf("arg%v", i), "", fmt.Sprintf("value of argument %v", i))
+	}
+	parseFlags(fs, args)
+
+	if fs.NArg() < 1 || *syscallName == "" {
+		fmt.Println("Usage: go2seccomp simulate -syscall name [-arch amd64] [-arg0 value ...] /path/to/profile.json")
+		os.Exit(1)
+	}
+
+	arch, ok := archByName(*archName)
+	if !ok {
+		fmt.Printf("Architecture %v is not supported\n", *archName)
+		os.Exit(1)
+	}
+
+	profile := loadProfile(fs.Arg(0))
+	if errs := validateProfile(profile); len(errs) > 0 {
+		for _, err := range errs {
+			fmt.Printf("%v: %v\n", fs.Arg(0), err)
+		}
+		os.Exit(1)
+	}
+
+	name := *syscallName
+	id, err := strconv.ParseInt(name, 0, 64)
+	if err == nil {
+		name, ok = syscallIDtoName[arch][id]
+	} else {
+		ok = false
+		for i, n := range syscallIDtoName[arch] {
+			if n == name {
+				id, ok = i, true
+			}
+		}
+	}
+	if !ok {
+		fmt.Printf("Syscall %v not found on %v\n", *syscallName, arch)
+		os.Exit(1)
+	}
+
+	var values [6]uint64
+	for i, v := range argValues {
+		if *v == "" {
+			continue
+		}
+		value, err := strconv.ParseUint(*v, 0, 64)
+		if err != nil {
+			fmt.Printf("Invalid value of argument %v: %v\n", i, *v)
+			os.Exit(1)
+		}
+		values[i] = value
+	}
+
+	action, errnoRet, steps, err := simulateSyscall(profile, arch, id, values)
+	if err != nil {
+		fmt.Printf("Failed to simulate %v: %v\n", name, err)
+		os.Exit(1)
+	}
+	if action == specs.ActErrno && errnoRet != nil {
+		fmt.Printf("%v: %v (errno %v), after %v BPF instructions\n", name, action, *errnoRet, steps)
+		return
+	}
+	fmt.Printf("%v: %v, after %v BPF instructions\n", name, action, steps)
+}
+
+// simulateSyscall compiles the profile to a BPF filter and runs it for the syscall ID on the arch with the
+// argument values, returning the action it takes, its errno if any, and the number of instructions it ran.
+// Profiles without architectures are compiled for the arch, like runtimes do for the native one.
+func simulateSyscall(profile specs.LinuxSeccomp, arch specs.Arch, id int64, values [6]uint64) (specs.LinuxSeccompAction, *uint, int, error) {
+	if len(profile.Architectures) == 0 {
+		profile.Architectures = []specs.Arch{arch}
+	}
+	prog, err := compileBPF(profile)
+	if err != nil {
+		return "", nil, 0, err
+	}
+
+	var data [16]uint32
+	data[seccompDataNr/4] = uint32(id)
+	data[seccompDataArch/4] = auditArches[arch].value
+	for i, v := range values {
+		hi, lo := seccompDataArgs/4+2*i+1, seccompDataArgs/4+2*i
+		if auditArches[arch].bigEndian {
+			hi, lo = lo, hi
+		}
+		data[hi], data[lo] = uint32(v>>32), uint32(v)
+	}
+
+	ret, steps, err := runBPF(prog, data)
+	if err != nil {
+		return "", nil, steps, err
+	}
+	action, errnoRet := returnAction(ret)
+	return action, errnoRet, steps, nil
+}
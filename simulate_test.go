@@ -0,0 +1,52 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+func TestSimulateSyscall(t *testing.T) {
+	enoent := uint(2)
+	profile := specs.LinuxSeccomp{
+		DefaultAction: specs.ActErrno,
+		Syscalls: []specs.LinuxSyscall{
+			{Names: []string{"read", "write"}, Action: specs.ActAllow},
+			{Names: []string{"openat"}, Action: specs.ActErrno, ErrnoRet: &enoent, Args: []specs.LinuxSeccompArg{
+				{Index: 2, Value: 0x40, ValueTwo: 0x40, Op: specs.OpMaskedEqual},
+			}},
+			{Names: []string{"openat"}, Action: specs.ActAllow},
+			{Names: []string{"ptrace"}, Action: specs.ActKillProcess},
+		},
+	}
+
+	tests := []struct {
+		arch   specs.Arch
+		name   string
+		values [6]uint64
+		action specs.LinuxSeccompAction
+		errno  uint
+	}{
+		{specs.ArchX86_64, "read", [6]uint64{}, specs.ActAllow, 0},
+		{specs.ArchX86_64, "openat", [6]uint64{0, 0, 0x2}, specs.ActAllow, 0},
+		{specs.ArchX86_64, "openat", [6]uint64{0, 0, 0x42}, specs.ActErrno, 2},
+		{specs.ArchX86_64, "ptrace", [6]uint64{}, specs.ActKillProcess, 0},
+		{specs.ArchX86_64, "connect", [6]uint64{}, specs.ActErrno, 1},
+		{specs.ArchMIPS, "openat", [6]uint64{0, 0, 0x42}, specs.ActErrno, 2},
+	}
+	for _, test := range tests {
+		var id int64 = -1
+		for i, name := range syscallIDtoName[test.arch] {
+			if name == test.name {
+				id = i
+			}
+		}
+		action, errnoRet, _, err := simulateSyscall(profile, test.arch, id, test.values)
+		if err != nil {
+			t.Fatalf("%v on %v: %v", test.name, test.arch, err)
+		}
+		if action != test.action || (errnoRet != nil) != (test.action == specs.ActErrno) || (errnoRet != nil && *errnoRet != test.errno) {
+			t.Errorf("%v%v on %v = %v %v, expected %v %v", test.name, test.values, test.arch, action, errnoRet, test.action, test.errno)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+var socketFamilies = flag.Bool("socket-families", false, "only allow socket for the address families the binary uses")
+
+// names of the common address families
+var addressFamilies = map[int64]string{
+	1:  "AF_UNIX",
+	2:  "AF_INET",
+	10: "AF_INET6",
+	16: "AF_NETLINK",
+	17: "AF_PACKET",
+	29: "AF_CAN",
+	31: "AF_BLUETOOTH",
+	38: "AF_ALG",
+	40: "AF_VSOCK",
+	44: "AF_XDP",
+}
+
+// the net package picks the family at run time and calls socket through a function value, so the families
+// of its dialers and listeners are assumed when it's used
+var netPackageFamilies = []int64{1, 2, 10}
+
+// the address families the binary was found to use, nil if they couldn't all be told
+var observedFamilies []int64
+
+func familyName(family int64) string {
+	if name, ok := addressFamilies[family]; ok {
+		return name
+	}
+	return fmt.Sprintf("family %v", family)
+}
+
+// reportSocketFamilies prints the address families passed to socket in the given functions (all if nil),
+// and records them for -socket-families
+func reportSocketFamilies(graph *callGraph, arch specs.Arch, functions map[string]bool) {
+	observedFamilies = nil
+	if arch != specs.ArchX86_64 {
+		if *socketFamilies {
+			warnf("socket families can only be told on amd64, socket isn't restricted")
+		}
+		return
+	}
+
+	families := make(map[int64]bool)
+	unknown := 0
+	for _, site := range graph.argSitesOf("socket", functions) {
+		if !site.Known {
+			unknown++
+			fmt.Printf("socket in %v at %v: address family isn't a constant\n", site.Function, site.Location)
+			continue
+		}
+		families[site.Value] = true
+	}
+	if _, ok := graph.syscalls["net.sysSocket"]; ok && (functions == nil || functions["net.sysSocket"]) {
+		for _, family := range netPackageFamilies {
+			families[family] = true
+		}
+	}
+	if len(families) == 0 && unknown == 0 {
+		return
+	}
+
+	sorted := make([]int64, 0, len(families))
+	for family := range families {
+		sorted = append(sorted, family)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	names := make([]string, len(sorted))
+	for i, family := range sorted {
+		names[i] = familyName(family)
+	}
+	fmt.Printf("Socket families: %v\n", names)
+
+	if unknown > 0 {
+		if *socketFamilies {
+			warnf("%v socket calls use families that can't be told, socket isn't restricted", unknown)
+		}
+		return
+	}
+	observedFamilies = sorted
+}
+
+// applySocketFamilies only allows socket for the observed address families, with -socket-families
+func applySocketFamilies(profile specs.LinuxSeccomp) specs.LinuxSeccomp {
+	if !*socketFamilies || len(observedFamilies) == 0 {
+		return profile
+	}
+
+	var rules []specs.LinuxSyscall
+	allowed := false
+	for _, rule := range profile.Syscalls {
+		if rule.Action == specs.ActAllow && len(rule.Args) == 0 {
+			var kept []string
+			for _, name := range rule.Names {
+				if name == "socket" {
+					allowed = true
+				} else {
+					kept = append(kept, name)
+				}
+			}
+			if len(kept) == 0 {
+				continue
+			}
+			rule.Names = kept
+		}
+		rules = append(rules, rule)
+	}
+	if !allowed {
+		return profile
+	}
+	// the conditions of a rule must all hold, so each family gets its own
+	for _, family := range observedFamilies {
+		rules = append(rules, specs.LinuxSyscall{
+			Names:  []string{"socket"},
+			Action: specs.ActAllow,
+			Args:   []specs.LinuxSeccompArg{{Index: 0, Value: uint64(family), Op: specs.OpEqualTo}},
+		})
+	}
+	profile.Syscalls = rules
+	return profile
+}
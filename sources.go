@@ -0,0 +1,165 @@
+package main
+
+import (
+	"sort"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+// where a syscall in the profile comes from
+const (
+	sourceDefault   = "default"
+	sourceDetected  = "detected"
+	sourceBase      = "base"
+	sourceAdded     = "added"
+	sourceFamily    = "family"
+	sourceSandbox   = "self-sandbox"
+	sourceRace      = "race"
+	sourceGodebug   = "godebug"
+	sourceTime64    = "time64"
+	sourceMinKernel = "min-kernel"
+)
+
+// syscalls allowed by the -base profile and given with -add
+var baseSyscalls []string
+var addedSyscalls stringList
+
+// loadAllowedSyscalls returns the names of the syscalls allowed by an existing profile
+func loadAllowedSyscalls(path string) []string {
+	profile := loadProfile(path)
+
+	var names []string
+	for _, rule := range profile.Syscalls {
+		if rule.Action == specs.ActAllow {
+			names = append(names, rule.Names...)
+		}
+	}
+	return names
+}
+
+// profileSources returns every syscall going in the profile, given the detected syscall IDs,
+// with where each one comes from
+func profileSources(ids map[int64]bool, arch specs.Arch) map[string][]string {
+	sources := make(map[string][]string)
+	add := func(name, source string) {
+		for _, s := range sources[name] {
+			if s == source {
+				return
+			}
+		}
+		sources[name] = append(sources[name], source)
+	}
+
+	addIDs := func(ids map[int64]bool, source string) {
+		for id := range ids {
+			if name, ok := syscallIDtoName[arch][id]; ok {
+				add(name, source)
+			}
+		}
+	}
+
+	if confident(confidenceDefault) {
+		addIDs(getDefaultSyscalls(arch), sourceDefault)
+	}
+	addIDs(ids, sourceDetected)
+	if *expandFamilies {
+		archNames := make(map[string]bool)
+		for _, name := range syscallIDtoName[arch] {
+			archNames[name] = true
+		}
+		for id := range ids {
+			for _, sibling := range familyOf(syscallIDtoName[arch][id]) {
+				if archNames[sibling] {
+					add(sibling, sourceFamily)
+				}
+			}
+		}
+	}
+	for _, name := range godebugNames(arch) {
+		add(name, sourceGodebug)
+	}
+	if raceBuild {
+		for _, name := range raceRuntimeSyscalls(arch) {
+			add(name, sourceRace)
+		}
+	}
+	if selfSandboxing {
+		for _, name := range sandboxSyscalls {
+			add(name, sourceSandbox)
+		}
+	}
+	for _, name := range baseSyscalls {
+		add(name, sourceBase)
+	}
+	for _, name := range addedSyscalls {
+		add(name, sourceAdded)
+	}
+	return sources
+}
+
+// syscallSources returns every syscall of the final profile, given the detected syscall IDs, with where each
+// one comes from: the syscalls the time64 and -min-kernel passes add are tagged with them, and the ones
+// -kconfig-drop and -prune-fallbacks leave out are dropped
+func syscallSources(ids map[int64]bool, arch specs.Arch) map[string][]string {
+	warnUnknownIDs(ids, arch)
+	fallbackOnly = nil
+	sources := profileSources(ids, arch)
+	list := sortedNames(sources)
+	list = applyPass(sources, list, applyTime64(list, arch), sourceTime64)
+	list = applyPass(sources, list, applyKernelConfig(list), "")
+	list = applyPass(sources, list, applyMinKernel(list, arch), sourceMinKernel)
+	applyPass(sources, list, applyPruneFallbacks(list), "")
+	return sources
+}
+
+// applyPass updates the sources with what a pass over the list changed, the added syscalls coming from source,
+// and returns the new list
+func applyPass(sources map[string][]string, before, after []string, source string) []string {
+	kept := make(map[string]bool)
+	for _, name := range after {
+		kept[name] = true
+		if _, ok := sources[name]; !ok {
+			sources[name] = []string{source}
+		}
+	}
+	for _, name := range before {
+		if !kept[name] {
+			delete(sources, name)
+		}
+	}
+	return after
+}
+
+// warnUnknownIDs warns about the detected syscall IDs missing from the ID->name map, left out of the profile
+func warnUnknownIDs(ids map[int64]bool, arch specs.Arch) {
+	var unknown []int64
+	for id := range ids {
+		if _, ok := syscallIDtoName[arch][id]; !ok {
+			unknown = append(unknown, id)
+		}
+	}
+	sort.Slice(unknown, func(i, j int) bool { return unknown[i] < unknown[j] })
+	for _, id := range unknown {
+		warnf("Syscall ID %v not available on the ID->name map, add it with -syscall-table", id)
+	}
+}
+
+// sourcesOf returns the sources of the syscalls in the list, which may have fewer syscalls than the sources
+// after the -interactive review
+func sourcesOf(sources map[string][]string, syscallsList []string) map[string][]string {
+	kept := make(map[string][]string, len(syscallsList))
+	for _, name := range syscallsList {
+		kept[name] = sources[name]
+	}
+	return kept
+}
+
+// sortedNames returns the syscall names in the sources map, sorted
+func sortedNames(sources map[string][]string) []string {
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
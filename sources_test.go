@@ -0,0 +1,26 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApplyPass(t *testing.T) {
+	sources := map[string][]string{
+		"clock_gettime": {sourceDetected},
+		"fstat":         {sourceDefault},
+		"statx":         {sourceDetected},
+	}
+	list := sortedNames(sources)
+	list = applyPass(sources, list, []string{"clock_gettime", "clock_gettime64", "fstat", "statx"}, sourceTime64)
+	applyPass(sources, list, []string{"clock_gettime", "clock_gettime64", "statx"}, "")
+
+	expected := map[string][]string{
+		"clock_gettime":   {sourceDetected},
+		"clock_gettime64": {sourceTime64},
+		"statx":           {sourceDetected},
+	}
+	if !reflect.DeepEqual(sources, expected) {
+		t.Errorf("sources = %v, expected %v", sources, expected)
+	}
+}
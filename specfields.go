@@ -0,0 +1,54 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+// whether the profile enforces itself or only logs, the permissions of its file are -file-mode
+var profileMode = flag.String("mode", modeEnforce, "enforce: fail the syscalls the profile doesn't allow, or audit: log them (SCMP_ACT_LOG) and let them run")
+
+// the -mode values
+const (
+	modeEnforce = "enforce"
+	modeAudit   = "audit"
+)
+
+var defaultErrnoRet = flag.Int("default-errno-ret", -1, "errno the syscalls the profile doesn't allow fail with, EPERM if not set")
+var listenerPath = flag.String("listener-path", "", "unix socket runtimes send the seccomp notification fd to, for SCMP_ACT_NOTIFY rules")
+var listenerMetadata = flag.String("listener-metadata", "", "opaque data runtimes pass to the -listener-path agent along with the fd")
+
+// flags to load the filter with, set with -seccomp-flag
+var seccompFlags stringList
+
+// seccomp(2) flags runtimes know
+var validFlags = map[specs.LinuxSeccompFlag]bool{
+	specs.LinuxSeccompFlagLog:              true,
+	specs.LinuxSeccompFlagSpecAllow:        true,
+	specs.LinuxSeccompFlagWaitKillableRecv: true,
+}
+
+// auditMode tells if the profile is an audit one, with -mode audit
+func auditMode() bool {
+	return *profileMode == modeAudit
+}
+
+// the largest errno, returned values above it aren't errors
+const maxErrno = 4095
+
+// applySpecFields sets the fields of the profile given with -default-action or -mode, -default-errno-ret,
+// -seccomp-flag, -listener-path and -listener-metadata
+func applySpecFields(profile specs.LinuxSeccomp) specs.LinuxSeccomp {
+	profile.DefaultAction = profileDefaultAction()
+	if *defaultErrnoRet != -1 {
+		errno := uint(*defaultErrnoRet)
+		profile.DefaultErrnoRet = &errno
+	}
+	for _, f := range seccompFlags {
+		profile.Flags = append(profile.Flags, specs.LinuxSeccompFlag(f))
+	}
+	profile.ListenerPath = *listenerPath
+	profile.ListenerMetadata = *listenerMetadata
+	return profile
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"gopkg.in/yaml.v2"
+)
+
+func init() {
+	registerFormatter(formatFunc{"k8s-spo", ".yaml", writeK8sSPO})
+}
+
+var spoNamespace = flag.String("spo-namespace", "", "namespace of the k8s-spo SeccompProfile, the one of the kubectl context if empty")
+
+// writeK8sSPO writes the profile as a SeccompProfile of the security-profiles-operator, which installs it on
+// the nodes, to be applied with kubectl apply -f profile.k8s-spo.yaml
+func writeK8sSPO(w io.Writer, profile specs.LinuxSeccomp, profilePath string) error {
+	// the spec has the fields of the OCI profile, without the go2seccomp metadata the CRD schema doesn't have
+	profileJSON, err := json.Marshal(profile)
+	if err != nil {
+		return err
+	}
+	var spec yaml.MapSlice
+	if err := yaml.Unmarshal(profileJSON, &spec); err != nil {
+		return err
+	}
+
+	base := filepath.Base(profilePath)
+	name := strings.TrimSuffix(base, filepath.Ext(base))
+	metadata := yaml.MapSlice{{Key: "name", Value: strings.ToLower(strings.Replace(name, "_", "-", -1))}}
+	if *spoNamespace != "" {
+		metadata = append(metadata, yaml.MapItem{Key: "namespace", Value: *spoNamespace})
+	}
+
+	seccompProfile := yaml.MapSlice{
+		{Key: "apiVersion", Value: "security-profiles-operator.x-k8s.io/v1beta1"},
+		{Key: "kind", Value: "SeccompProfile"},
+		{Key: "metadata", Value: metadata},
+		{Key: "spec", Value: spec},
+	}
+
+	enc := yaml.NewEncoder(w)
+	if err := enc.Encode(seccompProfile); err != nil {
+		return err
+	}
+	return enc.Close()
+}
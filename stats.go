@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+func init() {
+	commands["stats"] = statsCommand
+}
+
+// the kernel rejects filters longer than BPF_MAXINSNS instructions
+const bpfMaxInstructions = 4096
+
+// statsCommand prints a summary of a profile, or of the one generated for a binary
+func statsCommand(args []string) {
+	if len(args) < 1 {
+		fmt.Println("Usage: go2seccomp stats /path/to/profile.json|/path/to/binary")
+		os.Exit(1)
+	}
+
+	var profile specs.LinuxSeccomp
+	if data, err := ioutil.ReadFile(args[0]); err == nil && json.Valid(data) {
+		profile = loadProfile(args[0])
+	} else {
+		graph, arch := analyzeBinary(args[0])
+		profile = buildProfile(getSyscallList(graph.allSyscalls(), arch), arch)
+	}
+
+	names := make(map[string]bool)
+	perAction := make(map[specs.LinuxSeccompAction]int)
+	conditional := 0
+	for _, rule := range profile.Syscalls {
+		for _, name := range rule.Names {
+			names[name] = true
+		}
+		perAction[rule.Action] += len(rule.Names)
+		if len(rule.Args) > 0 {
+			conditional++
+		}
+	}
+
+	perTier := make(map[string]int)
+	for name := range names {
+		perTier[riskOf(name).Tier]++
+	}
+
+	fmt.Printf("Syscalls:        %v\n", len(names))
+	fmt.Printf("Rules:           %v (%v with argument conditions)\n", len(profile.Syscalls), conditional)
+	fmt.Printf("Default action:  %v\n", profile.DefaultAction)
+	fmt.Printf("Architectures:   %v %v\n", len(profile.Architectures), profile.Architectures)
+
+	fmt.Println("Per risk:")
+	for _, tier := range []string{riskHigh, riskMedium, riskLow} {
+		fmt.Printf("    %-30v %v\n", tier, perTier[tier])
+	}
+
+	fmt.Println("Per action:")
+	actions := make([]string, 0, len(perAction))
+	for action := range perAction {
+		actions = append(actions, string(action))
+	}
+	sort.Strings(actions)
+	for _, action := range actions {
+		fmt.Printf("    %-30v %v\n", action, perAction[specs.LinuxSeccompAction(action)])
+	}
+
+	reportBPF(profile)
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+var summaryFile = flag.String("summary-file", "", "path to write a JSON summary of the run to, with its exit status, counts and warnings, for CI")
+
+// the warnings printed during the run, for the summary
+var warnings []string
+
+// warnf prints a warning and records it for the summary
+func warnf(format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	fmt.Printf("Warning: %v\n", message)
+	warnings = append(warnings, message)
+}
+
+// runSummary tells how a run went, without the profile itself
+type runSummary struct {
+	ExitStatus int        `json:"exitStatus"`
+	Binary     string     `json:"binary"`
+	Arch       specs.Arch `json:"arch"`
+	Profile    string     `json:"profile"`
+	// syscalls in the profile, the ones found in the binary and the ones only there as default syscalls
+	Syscalls  int `json:"syscalls"`
+	Detected  int `json:"detected"`
+	Defaulted int `json:"defaulted"`
+	// syscalls whose ID couldn't be found
+	Unresolved int `json:"unresolved"`
+	// high risk syscalls in the profile
+	Dangerous         int      `json:"dangerous"`
+	DangerousSyscalls []string `json:"dangerousSyscalls,omitempty"`
+	Warnings          []string `json:"warnings"`
+}
+
+// the summary of the current run, written by exitWithSummary
+var summary *runSummary
+
+// newRunSummary counts the syscalls of the profile given with their sources by syscallSources, and the
+// unresolved ones in the given functions (all if nil)
+func newRunSummary(binaryPath, profilePath string, graph *callGraph, arch specs.Arch, sources map[string][]string, functions map[string]bool) *runSummary {
+	syscallsList := sortedNames(sources)
+	s := &runSummary{
+		Binary:     binaryPath,
+		Arch:       arch,
+		Profile:    profilePath,
+		Syscalls:   len(syscallsList),
+		Unresolved: len(graph.unresolvedIn(functions)),
+	}
+	for _, name := range syscallsList {
+		for _, source := range sources[name] {
+			if source == sourceDetected {
+				s.Detected++
+			}
+		}
+		if len(sources[name]) == 1 && sources[name][0] == sourceDefault {
+			s.Defaulted++
+		}
+		if riskOf(name).Tier == riskHigh {
+			s.DangerousSyscalls = append(s.DangerousSyscalls, name)
+		}
+	}
+	s.Dangerous = len(s.DangerousSyscalls)
+	return s
+}
+
+// fatalf logs the error and exits with status 1, writing the summary first like exitWithSummary, so CI gets one
+// for failed runs too
+func fatalf(format string, args ...interface{}) {
+	log.Printf(format, args...)
+	exitWithSummary(1)
+}
+
+// fatalln is fatalf with the arguments formatted like log.Println
+func fatalln(args ...interface{}) {
+	log.Println(args...)
+	exitWithSummary(1)
+}
+
+// exitWithSummary writes the summary with the exit status, with -summary-file, and exits if it's not 0
+func exitWithSummary(status int) {
+	if summary != nil && *summaryFile != "" {
+		summary.ExitStatus = status
+		summary.Warnings = warnings
+		if summary.Warnings == nil {
+			summary.Warnings = []string{}
+		}
+		data, err := json.MarshalIndent(summary, "", "    ")
+		if err != nil {
+			log.Fatalf("Failed to encode summary: %v", err)
+		}
+		if err := writeFileAtomic(*summaryFile, append(data, '\n'), 0644); err != nil {
+			log.Fatalf("Failed to write summary: %v", err)
+		}
+		fmt.Printf("Saved summary at %v\n", *summaryFile)
+	}
+	if status != 0 {
+		os.Exit(status)
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import "strings"
+
+// packages with the generic syscall functions taking the syscall ID as their first argument. Projects vendor,
+// fork or copy them under other paths, those paths are given with -syscall-package.
+var syscallPackages = []string{
+	"syscall",
+	"golang.org/x/sys/unix",
+	// the GitHub mirror of x/sys, imported by some older projects
+	"github.com/golang/sys/unix",
+	// gVisor makes raw syscalls without going through the runtime
+	"gvisor.dev/gvisor/pkg/hostsyscall",
+}
+
+// import paths of other syscall packages, given with -syscall-package
+var extraSyscallPackages stringList
+
+// the generic syscall functions of the syscall packages
+var syscallPackageFunctions = map[string]bool{
+	"Syscall":           true,
+	"Syscall6":          true,
+	"RawSyscall":        true,
+	"RawSyscall6":       true,
+	"rawVforkSyscall":   true,
+	"rawSyscallNoError": true,
+	"RawSyscallErrno":   true,
+	"RawSyscallErrno6":  true,
+}
+
+// isSyscallPackage tells if the import path is one of the syscall packages, or a vendored copy of one
+func isSyscallPackage(pkg string) bool {
+	for _, packages := range [][]string{syscallPackages, extraSyscallPackages} {
+		for _, p := range packages {
+			if pkg == p || pkg == "vendor/"+p || strings.HasSuffix(pkg, "/vendor/"+p) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// splitFunctionName splits a symbol name into its package import path and the function name
+func splitFunctionName(function string) (string, string) {
+	slash := strings.LastIndex(function, "/")
+	dot := strings.Index(function[slash+1:], ".")
+	if dot == -1 {
+		return "", function
+	}
+	return function[:slash+1+dot], function[slash+1+dot+1:]
+}
+
+// isGenericSyscallFunction tells if the function is a generic syscall function of a syscall package
+func isGenericSyscallFunction(function string) bool {
+	pkg, name := splitFunctionName(strings.TrimSuffix(function, ".abi0"))
+	return syscallPackageFunctions[name] && isSyscallPackage(pkg)
+}
+
+// canonicalSyscallFunction names the functions of the forks and copies of x/sys/unix after the x/sys/unix
+// ones, so they're recognized as them
+func canonicalSyscallFunction(function string) string {
+	pkg, name := splitFunctionName(function)
+	if pkg == "" || pkg == "syscall" || pkg == "golang.org/x/sys/unix" || !isSyscallPackage(pkg) {
+		return function
+	}
+	if pkg == "vendor/syscall" || strings.HasSuffix(pkg, "/vendor/syscall") {
+		return "syscall." + name
+	}
+	return "golang.org/x/sys/unix." + name
+}
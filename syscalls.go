@@ -5,9 +5,16 @@ import (
 )
 
 var syscallIDtoName = map[specs.Arch]map[int64]string{
-	specs.ArchX86_64: syscallIDtoNamex86_64,
-	specs.ArchX86:    syscallIDtoNamex86,
-	specs.ArchARM:    syscallIDtoNameARM,
+	specs.ArchX86_64:   syscallIDtoNamex86_64,
+	specs.ArchX86:      syscallIDtoNamex86,
+	specs.ArchX32:      syscallIDtoNameX32,
+	specs.ArchARM:      syscallIDtoNameARM,
+	specs.ArchAARCH64:  syscallIDtoNameAARCH64,
+	specs.ArchPPC64LE:  syscallIDtoNamePPC64LE,
+	specs.ArchMIPS:     syscallIDtoNameMIPS,
+	specs.ArchMIPSEL:   syscallIDtoNameMIPS,
+	specs.ArchMIPS64:   syscallIDtoNameMIPS64,
+	specs.ArchMIPSEL64: syscallIDtoNameMIPS64,
 }
 
 // reference: https://github.com/seccomp/libseccomp/blob/master/src/arch-x86-syscalls.c
@@ -358,6 +365,111 @@ var syscallIDtoNamex86 = map[int64]string{
 	347: "process_vm_readv",
 	348: "process_vm_writev",
 	349: "kcmp",
+	350: "finit_module",
+	351: "sched_setattr",
+	352: "sched_getattr",
+	353: "renameat2",
+	354: "seccomp",
+	355: "getrandom",
+	356: "memfd_create",
+	357: "bpf",
+	358: "execveat",
+	359: "socket",
+	360: "socketpair",
+	361: "bind",
+	362: "connect",
+	363: "listen",
+	364: "accept4",
+	365: "getsockopt",
+	366: "setsockopt",
+	367: "getsockname",
+	368: "getpeername",
+	369: "sendto",
+	370: "sendmsg",
+	371: "recvfrom",
+	372: "recvmsg",
+	373: "shutdown",
+	374: "userfaultfd",
+	375: "membarrier",
+	376: "mlock2",
+	377: "copy_file_range",
+	378: "preadv2",
+	379: "pwritev2",
+	380: "pkey_mprotect",
+	381: "pkey_alloc",
+	382: "pkey_free",
+	383: "statx",
+	384: "arch_prctl",
+	385: "io_pgetevents",
+	386: "rseq",
+	393: "semget",
+	394: "semctl",
+	395: "shmget",
+	396: "shmctl",
+	397: "shmat",
+	398: "shmdt",
+	399: "msgget",
+	400: "msgsnd",
+	401: "msgrcv",
+	402: "msgctl",
+	403: "clock_gettime64",
+	404: "clock_settime64",
+	405: "clock_adjtime64",
+	406: "clock_getres_time64",
+	407: "clock_nanosleep_time64",
+	408: "timer_gettime64",
+	409: "timer_settime64",
+	410: "timerfd_gettime64",
+	411: "timerfd_settime64",
+	412: "utimensat_time64",
+	413: "pselect6_time64",
+	414: "ppoll_time64",
+	416: "io_pgetevents_time64",
+	417: "recvmmsg_time64",
+	418: "mq_timedsend_time64",
+	419: "mq_timedreceive_time64",
+	420: "semtimedop_time64",
+	421: "rt_sigtimedwait_time64",
+	422: "futex_time64",
+	423: "sched_rr_get_interval_time64",
+	424: "pidfd_send_signal",
+	425: "io_uring_setup",
+	426: "io_uring_enter",
+	427: "io_uring_register",
+	428: "open_tree",
+	429: "move_mount",
+	430: "fsopen",
+	431: "fsconfig",
+	432: "fsmount",
+	433: "fspick",
+	434: "pidfd_open",
+	435: "clone3",
+	436: "close_range",
+	437: "openat2",
+	438: "pidfd_getfd",
+	439: "faccessat2",
+	440: "process_madvise",
+	441: "epoll_pwait2",
+	442: "mount_setattr",
+	443: "quotactl_fd",
+	444: "landlock_create_ruleset",
+	445: "landlock_add_rule",
+	446: "landlock_restrict_self",
+	447: "memfd_secret",
+	448: "process_mrelease",
+	449: "futex_waitv",
+	450: "set_mempolicy_home_node",
+	451: "cachestat",
+	452: "fchmodat2",
+	454: "futex_wake",
+	455: "futex_wait",
+	456: "futex_requeue",
+	457: "statmount",
+	458: "listmount",
+	459: "lsm_get_self_attr",
+	460: "lsm_set_self_attr",
+	461: "lsm_list_modules",
+	462: "mseal",
 }
 var syscallIDtoNameARM = map[int64]string{
 	0:   "restart_syscall",
@@ -705,6 +817,87 @@ var syscallIDtoNameARM = map[int64]string{
 	375: "setns",
 	376: "process_vm_readv",
 	377: "process_vm_writev",
+	378: "kcmp",
+	379: "finit_module",
+	380: "sched_setattr",
+	381: "sched_getattr",
+	382: "renameat2",
+	383: "seccomp",
+	384: "getrandom",
+	385: "memfd_create",
+	386: "bpf",
+	387: "execveat",
+	388: "userfaultfd",
+	389: "membarrier",
+	390: "mlock2",
+	391: "copy_file_range",
+	392: "preadv2",
+	393: "pwritev2",
+	394: "pkey_mprotect",
+	395: "pkey_alloc",
+	396: "pkey_free",
+	397: "statx",
+	398: "rseq",
+	399: "io_pgetevents",
+	400: "migrate_pages",
+	401: "kexec_file_load",
+	403: "clock_gettime64",
+	404: "clock_settime64",
+	405: "clock_adjtime64",
+	406: "clock_getres_time64",
+	407: "clock_nanosleep_time64",
+	408: "timer_gettime64",
+	409: "timer_settime64",
+	410: "timerfd_gettime64",
+	411: "timerfd_settime64",
+	412: "utimensat_time64",
+	413: "pselect6_time64",
+	414: "ppoll_time64",
+	416: "io_pgetevents_time64",
+	417: "recvmmsg_time64",
+	418: "mq_timedsend_time64",
+	419: "mq_timedreceive_time64",
+	420: "semtimedop_time64",
+	421: "rt_sigtimedwait_time64",
+	422: "futex_time64",
+	423: "sched_rr_get_interval_time64",
+	424: "pidfd_send_signal",
+	425: "io_uring_setup",
+	426: "io_uring_enter",
+	427: "io_uring_register",
+	428: "open_tree",
+	429: "move_mount",
+	430: "fsopen",
+	431: "fsconfig",
+	432: "fsmount",
+	433: "fspick",
+	434: "pidfd_open",
+	435: "clone3",
+	436: "close_range",
+	437: "openat2",
+	438: "pidfd_getfd",
+	439: "faccessat2",
+	440: "process_madvise",
+	441: "epoll_pwait2",
+	442: "mount_setattr",
+	443: "quotactl_fd",
+	444: "landlock_create_ruleset",
+	445: "landlock_add_rule",
+	446: "landlock_restrict_self",
+	448: "process_mrelease",
+	449: "futex_waitv",
+	450: "set_mempolicy_home_node",
+	451: "cachestat",
+	452: "fchmodat2",
+	454: "futex_wake",
+	455: "futex_wait",
+	456: "futex_requeue",
+	457: "statmount",
+	458: "listmount",
+	459: "lsm_get_self_attr",
+	460: "lsm_set_self_attr",
+	461: "lsm_list_modules",
+	462: "mseal",
 }
 
 // reference: https://github.com/seccomp/libseccomp/blob/master/src/arch-x86_64-syscalls.c
@@ -1043,3 +1236,1541 @@ var syscallIDtoNamex86_64 = map[int64]string{
 	331: "pkey_free",
 	332: "statx",
 }
+
+// reference: https://github.com/seccomp/libseccomp/blob/master/src/arch-aarch64-syscalls.c
+var syscallIDtoNameAARCH64 = map[int64]string{
+	0:   "io_setup",
+	1:   "io_destroy",
+	2:   "io_submit",
+	3:   "io_cancel",
+	4:   "io_getevents",
+	5:   "setxattr",
+	6:   "lsetxattr",
+	7:   "fsetxattr",
+	8:   "getxattr",
+	9:   "lgetxattr",
+	10:  "fgetxattr",
+	11:  "listxattr",
+	12:  "llistxattr",
+	13:  "flistxattr",
+	14:  "removexattr",
+	15:  "lremovexattr",
+	16:  "fremovexattr",
+	17:  "getcwd",
+	18:  "lookup_dcookie",
+	19:  "eventfd2",
+	20:  "epoll_create1",
+	21:  "epoll_ctl",
+	22:  "epoll_pwait",
+	23:  "dup",
+	24:  "dup3",
+	25:  "fcntl",
+	26:  "inotify_init1",
+	27:  "inotify_add_watch",
+	28:  "inotify_rm_watch",
+	29:  "ioctl",
+	30:  "ioprio_set",
+	31:  "ioprio_get",
+	32:  "flock",
+	33:  "mknodat",
+	34:  "mkdirat",
+	35:  "unlinkat",
+	36:  "symlinkat",
+	37:  "linkat",
+	38:  "renameat",
+	39:  "umount2",
+	40:  "mount",
+	41:  "pivot_root",
+	42:  "nfsservctl",
+	43:  "statfs",
+	44:  "fstatfs",
+	45:  "truncate",
+	46:  "ftruncate",
+	47:  "fallocate",
+	48:  "faccessat",
+	49:  "chdir",
+	50:  "fchdir",
+	51:  "chroot",
+	52:  "fchmod",
+	53:  "fchmodat",
+	54:  "fchownat",
+	55:  "fchown",
+	56:  "openat",
+	57:  "close",
+	58:  "vhangup",
+	59:  "pipe2",
+	60:  "quotactl",
+	61:  "getdents64",
+	62:  "lseek",
+	63:  "read",
+	64:  "write",
+	65:  "readv",
+	66:  "writev",
+	67:  "pread64",
+	68:  "pwrite64",
+	69:  "preadv",
+	70:  "pwritev",
+	71:  "sendfile",
+	72:  "pselect6",
+	73:  "ppoll",
+	74:  "signalfd4",
+	75:  "vmsplice",
+	76:  "splice",
+	77:  "tee",
+	78:  "readlinkat",
+	79:  "newfstatat",
+	80:  "fstat",
+	81:  "sync",
+	82:  "fsync",
+	83:  "fdatasync",
+	84:  "sync_file_range",
+	85:  "timerfd_create",
+	86:  "timerfd_settime",
+	87:  "timerfd_gettime",
+	88:  "utimensat",
+	89:  "acct",
+	90:  "capget",
+	91:  "capset",
+	92:  "personality",
+	93:  "exit",
+	94:  "exit_group",
+	95:  "waitid",
+	96:  "set_tid_address",
+	97:  "unshare",
+	98:  "futex",
+	99:  "set_robust_list",
+	100: "get_robust_list",
+	101: "nanosleep",
+	102: "getitimer",
+	103: "setitimer",
+	104: "kexec_load",
+	105: "init_module",
+	106: "delete_module",
+	107: "timer_create",
+	108: "timer_gettime",
+	109: "timer_getoverrun",
+	110: "timer_settime",
+	111: "timer_delete",
+	112: "clock_settime",
+	113: "clock_gettime",
+	114: "clock_getres",
+	115: "clock_nanosleep",
+	116: "syslog",
+	117: "ptrace",
+	118: "sched_setparam",
+	119: "sched_setscheduler",
+	120: "sched_getscheduler",
+	121: "sched_getparam",
+	122: "sched_setaffinity",
+	123: "sched_getaffinity",
+	124: "sched_yield",
+	125: "sched_get_priority_max",
+	126: "sched_get_priority_min",
+	127: "sched_rr_get_interval",
+	128: "restart_syscall",
+	129: "kill",
+	130: "tkill",
+	131: "tgkill",
+	132: "sigaltstack",
+	133: "rt_sigsuspend",
+	134: "rt_sigaction",
+	135: "rt_sigprocmask",
+	136: "rt_sigpending",
+	137: "rt_sigtimedwait",
+	138: "rt_sigqueueinfo",
+	139: "rt_sigreturn",
+	140: "setpriority",
+	141: "getpriority",
+	142: "reboot",
+	143: "setregid",
+	144: "setgid",
+	145: "setreuid",
+	146: "setuid",
+	147: "setresuid",
+	148: "getresuid",
+	149: "setresgid",
+	150: "getresgid",
+	151: "setfsuid",
+	152: "setfsgid",
+	153: "times",
+	154: "setpgid",
+	155: "getpgid",
+	156: "getsid",
+	157: "setsid",
+	158: "getgroups",
+	159: "setgroups",
+	160: "uname",
+	161: "sethostname",
+	162: "setdomainname",
+	163: "getrlimit",
+	164: "setrlimit",
+	165: "getrusage",
+	166: "umask",
+	167: "prctl",
+	168: "getcpu",
+	169: "gettimeofday",
+	170: "settimeofday",
+	171: "adjtimex",
+	172: "getpid",
+	173: "getppid",
+	174: "getuid",
+	175: "geteuid",
+	176: "getgid",
+	177: "getegid",
+	178: "gettid",
+	179: "sysinfo",
+	180: "mq_open",
+	181: "mq_unlink",
+	182: "mq_timedsend",
+	183: "mq_timedreceive",
+	184: "mq_notify",
+	185: "mq_getsetattr",
+	186: "msgget",
+	187: "msgctl",
+	188: "msgrcv",
+	189: "msgsnd",
+	190: "semget",
+	191: "semctl",
+	192: "semtimedop",
+	193: "semop",
+	194: "shmget",
+	195: "shmctl",
+	196: "shmat",
+	197: "shmdt",
+	198: "socket",
+	199: "socketpair",
+	200: "bind",
+	201: "listen",
+	202: "accept",
+	203: "connect",
+	204: "getsockname",
+	205: "getpeername",
+	206: "sendto",
+	207: "recvfrom",
+	208: "setsockopt",
+	209: "getsockopt",
+	210: "shutdown",
+	211: "sendmsg",
+	212: "recvmsg",
+	213: "readahead",
+	214: "brk",
+	215: "munmap",
+	216: "mremap",
+	217: "add_key",
+	218: "request_key",
+	219: "keyctl",
+	220: "clone",
+	221: "execve",
+	222: "mmap",
+	223: "fadvise64",
+	224: "swapon",
+	225: "swapoff",
+	226: "mprotect",
+	227: "msync",
+	228: "mlock",
+	229: "munlock",
+	230: "mlockall",
+	231: "munlockall",
+	232: "mincore",
+	233: "madvise",
+	234: "remap_file_pages",
+	235: "mbind",
+	236: "get_mempolicy",
+	237: "set_mempolicy",
+	238: "migrate_pages",
+	239: "move_pages",
+	240: "rt_tgsigqueueinfo",
+	241: "perf_event_open",
+	242: "accept4",
+	243: "recvmmsg",
+	260: "wait4",
+	261: "prlimit64",
+	262: "fanotify_init",
+	263: "fanotify_mark",
+	264: "name_to_handle_at",
+	265: "open_by_handle_at",
+	266: "clock_adjtime",
+	267: "syncfs",
+	268: "setns",
+	269: "sendmmsg",
+	270: "process_vm_readv",
+	271: "process_vm_writev",
+	272: "kcmp",
+	273: "finit_module",
+	274: "sched_setattr",
+	275: "sched_getattr",
+	276: "renameat2",
+	277: "seccomp",
+	278: "getrandom",
+	279: "memfd_create",
+	280: "bpf",
+	281: "execveat",
+	282: "userfaultfd",
+	283: "membarrier",
+	284: "mlock2",
+	285: "copy_file_range",
+	286: "preadv2",
+	287: "pwritev2",
+	288: "pkey_mprotect",
+	289: "pkey_alloc",
+	290: "pkey_free",
+	291: "statx",
+	292: "io_pgetevents",
+	293: "rseq",
+	294: "kexec_file_load",
+	424: "pidfd_send_signal",
+	425: "io_uring_setup",
+	426: "io_uring_enter",
+	427: "io_uring_register",
+	428: "open_tree",
+	429: "move_mount",
+	430: "fsopen",
+	431: "fsconfig",
+	432: "fsmount",
+	433: "fspick",
+	434: "pidfd_open",
+	435: "clone3",
+	436: "close_range",
+	437: "openat2",
+	438: "pidfd_getfd",
+	439: "faccessat2",
+	440: "process_madvise",
+	441: "epoll_pwait2",
+	442: "mount_setattr",
+	443: "quotactl_fd",
+	444: "landlock_create_ruleset",
+	445: "landlock_add_rule",
+	446: "landlock_restrict_self",
+	447: "memfd_secret",
+	448: "process_mrelease",
+	449: "futex_waitv",
+	450: "set_mempolicy_home_node",
+	451: "cachestat",
+	452: "fchmodat2",
+	453: "map_shadow_stack",
+	454: "futex_wake",
+	455: "futex_wait",
+	456: "futex_requeue",
+	457: "statmount",
+	458: "listmount",
+	459: "lsm_get_self_attr",
+	460: "lsm_set_self_attr",
+	461: "lsm_list_modules",
+	462: "mseal",
+}
+
+// reference: https://github.com/seccomp/libseccomp/blob/master/src/arch-ppc64-syscalls.c
+var syscallIDtoNamePPC64LE = map[int64]string{
+	0:   "restart_syscall",
+	1:   "exit",
+	2:   "fork",
+	3:   "read",
+	4:   "write",
+	5:   "open",
+	6:   "close",
+	7:   "waitpid",
+	8:   "creat",
+	9:   "link",
+	10:  "unlink",
+	11:  "execve",
+	12:  "chdir",
+	13:  "time",
+	14:  "mknod",
+	15:  "chmod",
+	16:  "lchown",
+	17:  "break",
+	18:  "oldstat",
+	19:  "lseek",
+	20:  "getpid",
+	21:  "mount",
+	22:  "umount",
+	23:  "setuid",
+	24:  "getuid",
+	25:  "stime",
+	26:  "ptrace",
+	27:  "alarm",
+	28:  "oldfstat",
+	29:  "pause",
+	30:  "utime",
+	31:  "stty",
+	32:  "gtty",
+	33:  "access",
+	34:  "nice",
+	35:  "ftime",
+	36:  "sync",
+	37:  "kill",
+	38:  "rename",
+	39:  "mkdir",
+	40:  "rmdir",
+	41:  "dup",
+	42:  "pipe",
+	43:  "times",
+	44:  "prof",
+	45:  "brk",
+	46:  "setgid",
+	47:  "getgid",
+	48:  "signal",
+	49:  "geteuid",
+	50:  "getegid",
+	51:  "acct",
+	52:  "umount2",
+	53:  "lock",
+	54:  "ioctl",
+	55:  "fcntl",
+	56:  "mpx",
+	57:  "setpgid",
+	58:  "ulimit",
+	59:  "oldolduname",
+	60:  "umask",
+	61:  "chroot",
+	62:  "ustat",
+	63:  "dup2",
+	64:  "getppid",
+	65:  "getpgrp",
+	66:  "setsid",
+	67:  "sigaction",
+	68:  "sgetmask",
+	69:  "ssetmask",
+	70:  "setreuid",
+	71:  "setregid",
+	72:  "sigsuspend",
+	73:  "sigpending",
+	74:  "sethostname",
+	75:  "setrlimit",
+	76:  "getrlimit",
+	77:  "getrusage",
+	78:  "gettimeofday",
+	79:  "settimeofday",
+	80:  "getgroups",
+	81:  "setgroups",
+	82:  "select",
+	83:  "symlink",
+	84:  "oldlstat",
+	85:  "readlink",
+	86:  "uselib",
+	87:  "swapon",
+	88:  "reboot",
+	89:  "readdir",
+	90:  "mmap",
+	91:  "munmap",
+	92:  "truncate",
+	93:  "ftruncate",
+	94:  "fchmod",
+	95:  "fchown",
+	96:  "getpriority",
+	97:  "setpriority",
+	98:  "profil",
+	99:  "statfs",
+	100: "fstatfs",
+	101: "ioperm",
+	102: "socketcall",
+	103: "syslog",
+	104: "setitimer",
+	105: "getitimer",
+	106: "stat",
+	107: "lstat",
+	108: "fstat",
+	109: "olduname",
+	110: "iopl",
+	111: "vhangup",
+	112: "idle",
+	113: "vm86",
+	114: "wait4",
+	115: "swapoff",
+	116: "sysinfo",
+	117: "ipc",
+	118: "fsync",
+	119: "sigreturn",
+	120: "clone",
+	121: "setdomainname",
+	122: "uname",
+	123: "modify_ldt",
+	124: "adjtimex",
+	125: "mprotect",
+	126: "sigprocmask",
+	127: "create_module",
+	128: "init_module",
+	129: "delete_module",
+	130: "get_kernel_syms",
+	131: "quotactl",
+	132: "getpgid",
+	133: "fchdir",
+	134: "bdflush",
+	135: "sysfs",
+	136: "personality",
+	137: "afs_syscall",
+	138: "setfsuid",
+	139: "setfsgid",
+	140: "_llseek",
+	141: "getdents",
+	142: "_newselect",
+	143: "flock",
+	144: "msync",
+	145: "readv",
+	146: "writev",
+	147: "getsid",
+	148: "fdatasync",
+	149: "_sysctl",
+	150: "mlock",
+	151: "munlock",
+	152: "mlockall",
+	153: "munlockall",
+	154: "sched_setparam",
+	155: "sched_getparam",
+	156: "sched_setscheduler",
+	157: "sched_getscheduler",
+	158: "sched_yield",
+	159: "sched_get_priority_max",
+	160: "sched_get_priority_min",
+	161: "sched_rr_get_interval",
+	162: "nanosleep",
+	163: "mremap",
+	164: "setresuid",
+	165: "getresuid",
+	166: "query_module",
+	167: "poll",
+	168: "nfsservctl",
+	169: "setresgid",
+	170: "getresgid",
+	171: "prctl",
+	172: "rt_sigreturn",
+	173: "rt_sigaction",
+	174: "rt_sigprocmask",
+	175: "rt_sigpending",
+	176: "rt_sigtimedwait",
+	177: "rt_sigqueueinfo",
+	178: "rt_sigsuspend",
+	179: "pread64",
+	180: "pwrite64",
+	181: "chown",
+	182: "getcwd",
+	183: "capget",
+	184: "capset",
+	185: "sigaltstack",
+	186: "sendfile",
+	187: "getpmsg",
+	188: "putpmsg",
+	189: "vfork",
+	190: "ugetrlimit",
+	191: "readahead",
+	198: "pciconfig_read",
+	199: "pciconfig_write",
+	200: "pciconfig_iobase",
+	201: "multiplexer",
+	202: "getdents64",
+	203: "pivot_root",
+	205: "madvise",
+	206: "mincore",
+	207: "gettid",
+	208: "tkill",
+	209: "setxattr",
+	210: "lsetxattr",
+	211: "fsetxattr",
+	212: "getxattr",
+	213: "lgetxattr",
+	214: "fgetxattr",
+	215: "listxattr",
+	216: "llistxattr",
+	217: "flistxattr",
+	218: "removexattr",
+	219: "lremovexattr",
+	220: "fremovexattr",
+	221: "futex",
+	222: "sched_setaffinity",
+	223: "sched_getaffinity",
+	225: "tuxcall",
+	227: "io_setup",
+	228: "io_destroy",
+	229: "io_getevents",
+	230: "io_submit",
+	231: "io_cancel",
+	232: "set_tid_address",
+	233: "fadvise64",
+	234: "exit_group",
+	235: "lookup_dcookie",
+	236: "epoll_create",
+	237: "epoll_ctl",
+	238: "epoll_wait",
+	239: "remap_file_pages",
+	240: "timer_create",
+	241: "timer_settime",
+	242: "timer_gettime",
+	243: "timer_getoverrun",
+	244: "timer_delete",
+	245: "clock_settime",
+	246: "clock_gettime",
+	247: "clock_getres",
+	248: "clock_nanosleep",
+	249: "swapcontext",
+	250: "tgkill",
+	251: "utimes",
+	252: "statfs64",
+	253: "fstatfs64",
+	255: "rtas",
+	256: "sys_debug_setcontext",
+	258: "migrate_pages",
+	259: "mbind",
+	260: "get_mempolicy",
+	261: "set_mempolicy",
+	262: "mq_open",
+	263: "mq_unlink",
+	264: "mq_timedsend",
+	265: "mq_timedreceive",
+	266: "mq_notify",
+	267: "mq_getsetattr",
+	268: "kexec_load",
+	269: "add_key",
+	270: "request_key",
+	271: "keyctl",
+	272: "waitid",
+	273: "ioprio_set",
+	274: "ioprio_get",
+	275: "inotify_init",
+	276: "inotify_add_watch",
+	277: "inotify_rm_watch",
+	278: "spu_run",
+	279: "spu_create",
+	280: "pselect6",
+	281: "ppoll",
+	282: "unshare",
+	283: "splice",
+	284: "tee",
+	285: "vmsplice",
+	286: "openat",
+	287: "mkdirat",
+	288: "mknodat",
+	289: "fchownat",
+	290: "futimesat",
+	291: "newfstatat",
+	292: "unlinkat",
+	293: "renameat",
+	294: "linkat",
+	295: "symlinkat",
+	296: "readlinkat",
+	297: "fchmodat",
+	298: "faccessat",
+	299: "get_robust_list",
+	300: "set_robust_list",
+	301: "move_pages",
+	302: "getcpu",
+	303: "epoll_pwait",
+	304: "utimensat",
+	305: "signalfd",
+	306: "timerfd_create",
+	307: "eventfd",
+	308: "sync_file_range2",
+	309: "fallocate",
+	310: "subpage_prot",
+	311: "timerfd_settime",
+	312: "timerfd_gettime",
+	313: "signalfd4",
+	314: "eventfd2",
+	315: "epoll_create1",
+	316: "dup3",
+	317: "pipe2",
+	318: "inotify_init1",
+	319: "perf_event_open",
+	320: "preadv",
+	321: "pwritev",
+	322: "rt_tgsigqueueinfo",
+	323: "fanotify_init",
+	324: "fanotify_mark",
+	325: "prlimit64",
+	326: "socket",
+	327: "bind",
+	328: "connect",
+	329: "listen",
+	330: "accept",
+	331: "getsockname",
+	332: "getpeername",
+	333: "socketpair",
+	334: "send",
+	335: "sendto",
+	336: "recv",
+	337: "recvfrom",
+	338: "shutdown",
+	339: "setsockopt",
+	340: "getsockopt",
+	341: "sendmsg",
+	342: "recvmsg",
+	343: "recvmmsg",
+	344: "accept4",
+	345: "name_to_handle_at",
+	346: "open_by_handle_at",
+	347: "clock_adjtime",
+	348: "syncfs",
+	349: "sendmmsg",
+	350: "setns",
+	351: "process_vm_readv",
+	352: "process_vm_writev",
+	353: "finit_module",
+	354: "kcmp",
+	355: "sched_setattr",
+	356: "sched_getattr",
+	357: "renameat2",
+	358: "seccomp",
+	359: "getrandom",
+	360: "memfd_create",
+	361: "bpf",
+	362: "execveat",
+	363: "switch_endian",
+	364: "userfaultfd",
+	365: "membarrier",
+	378: "mlock2",
+	379: "copy_file_range",
+	380: "preadv2",
+	381: "pwritev2",
+	382: "kexec_file_load",
+	383: "statx",
+	384: "pkey_alloc",
+	385: "pkey_free",
+	386: "pkey_mprotect",
+	387: "rseq",
+	388: "io_pgetevents",
+	392: "semtimedop",
+	393: "semget",
+	394: "semctl",
+	395: "shmget",
+	396: "shmctl",
+	397: "shmat",
+	398: "shmdt",
+	399: "msgget",
+	400: "msgsnd",
+	401: "msgrcv",
+	402: "msgctl",
+	424: "pidfd_send_signal",
+	425: "io_uring_setup",
+	426: "io_uring_enter",
+	427: "io_uring_register",
+	428: "open_tree",
+	429: "move_mount",
+	430: "fsopen",
+	431: "fsconfig",
+	432: "fsmount",
+	433: "fspick",
+	434: "pidfd_open",
+	435: "clone3",
+	436: "close_range",
+	437: "openat2",
+	438: "pidfd_getfd",
+	439: "faccessat2",
+	440: "process_madvise",
+	441: "epoll_pwait2",
+	442: "mount_setattr",
+	443: "quotactl_fd",
+	444: "landlock_create_ruleset",
+	445: "landlock_add_rule",
+	446: "landlock_restrict_self",
+	448: "process_mrelease",
+	449: "futex_waitv",
+	450: "set_mempolicy_home_node",
+	451: "cachestat",
+	452: "fchmodat2",
+	454: "futex_wake",
+	455: "futex_wait",
+	456: "futex_requeue",
+	457: "statmount",
+	458: "listmount",
+	459: "lsm_get_self_attr",
+	460: "lsm_set_self_attr",
+	461: "lsm_list_modules",
+	462: "mseal",
+}
+
+// reference: https://github.com/seccomp/libseccomp/blob/master/src/arch-mips-syscalls.c, numbered from 4000 on O32
+var syscallIDtoNameMIPS = map[int64]string{
+	4000: "syscall",
+	4001: "exit",
+	4002: "fork",
+	4003: "read",
+	4004: "write",
+	4005: "open",
+	4006: "close",
+	4007: "waitpid",
+	4008: "creat",
+	4009: "link",
+	4010: "unlink",
+	4011: "execve",
+	4012: "chdir",
+	4013: "time",
+	4014: "mknod",
+	4015: "chmod",
+	4016: "lchown",
+	4017: "break",
+	4019: "lseek",
+	4020: "getpid",
+	4021: "mount",
+	4022: "umount",
+	4023: "setuid",
+	4024: "getuid",
+	4025: "stime",
+	4026: "ptrace",
+	4027: "alarm",
+	4029: "pause",
+	4030: "utime",
+	4031: "stty",
+	4032: "gtty",
+	4033: "access",
+	4034: "nice",
+	4035: "ftime",
+	4036: "sync",
+	4037: "kill",
+	4038: "rename",
+	4039: "mkdir",
+	4040: "rmdir",
+	4041: "dup",
+	4042: "pipe",
+	4043: "times",
+	4044: "prof",
+	4045: "brk",
+	4046: "setgid",
+	4047: "getgid",
+	4048: "signal",
+	4049: "geteuid",
+	4050: "getegid",
+	4051: "acct",
+	4052: "umount2",
+	4053: "lock",
+	4054: "ioctl",
+	4055: "fcntl",
+	4056: "mpx",
+	4057: "setpgid",
+	4058: "ulimit",
+	4060: "umask",
+	4061: "chroot",
+	4062: "ustat",
+	4063: "dup2",
+	4064: "getppid",
+	4065: "getpgrp",
+	4066: "setsid",
+	4067: "sigaction",
+	4068: "sgetmask",
+	4069: "ssetmask",
+	4070: "setreuid",
+	4071: "setregid",
+	4072: "sigsuspend",
+	4073: "sigpending",
+	4074: "sethostname",
+	4075: "setrlimit",
+	4076: "getrlimit",
+	4077: "getrusage",
+	4078: "gettimeofday",
+	4079: "settimeofday",
+	4080: "getgroups",
+	4081: "setgroups",
+	4083: "symlink",
+	4085: "readlink",
+	4086: "uselib",
+	4087: "swapon",
+	4088: "reboot",
+	4089: "readdir",
+	4090: "mmap",
+	4091: "munmap",
+	4092: "truncate",
+	4093: "ftruncate",
+	4094: "fchmod",
+	4095: "fchown",
+	4096: "getpriority",
+	4097: "setpriority",
+	4098: "profil",
+	4099: "statfs",
+	4100: "fstatfs",
+	4101: "ioperm",
+	4102: "socketcall",
+	4103: "syslog",
+	4104: "setitimer",
+	4105: "getitimer",
+	4106: "stat",
+	4107: "lstat",
+	4108: "fstat",
+	4110: "iopl",
+	4111: "vhangup",
+	4112: "idle",
+	4113: "vm86",
+	4114: "wait4",
+	4115: "swapoff",
+	4116: "sysinfo",
+	4117: "ipc",
+	4118: "fsync",
+	4119: "sigreturn",
+	4120: "clone",
+	4121: "setdomainname",
+	4122: "uname",
+	4123: "modify_ldt",
+	4124: "adjtimex",
+	4125: "mprotect",
+	4126: "sigprocmask",
+	4127: "create_module",
+	4128: "init_module",
+	4129: "delete_module",
+	4130: "get_kernel_syms",
+	4131: "quotactl",
+	4132: "getpgid",
+	4133: "fchdir",
+	4134: "bdflush",
+	4135: "sysfs",
+	4136: "personality",
+	4137: "afs_syscall",
+	4138: "setfsuid",
+	4139: "setfsgid",
+	4140: "_llseek",
+	4141: "getdents",
+	4142: "_newselect",
+	4143: "flock",
+	4144: "msync",
+	4145: "readv",
+	4146: "writev",
+	4147: "cacheflush",
+	4148: "cachectl",
+	4149: "sysmips",
+	4151: "getsid",
+	4152: "fdatasync",
+	4153: "_sysctl",
+	4154: "mlock",
+	4155: "munlock",
+	4156: "mlockall",
+	4157: "munlockall",
+	4158: "sched_setparam",
+	4159: "sched_getparam",
+	4160: "sched_setscheduler",
+	4161: "sched_getscheduler",
+	4162: "sched_yield",
+	4163: "sched_get_priority_max",
+	4164: "sched_get_priority_min",
+	4165: "sched_rr_get_interval",
+	4166: "nanosleep",
+	4167: "mremap",
+	4168: "accept",
+	4169: "bind",
+	4170: "connect",
+	4171: "getpeername",
+	4172: "getsockname",
+	4173: "getsockopt",
+	4174: "listen",
+	4175: "recv",
+	4176: "recvfrom",
+	4177: "recvmsg",
+	4178: "send",
+	4179: "sendmsg",
+	4180: "sendto",
+	4181: "setsockopt",
+	4182: "shutdown",
+	4183: "socket",
+	4184: "socketpair",
+	4185: "setresuid",
+	4186: "getresuid",
+	4187: "query_module",
+	4188: "poll",
+	4189: "nfsservctl",
+	4190: "setresgid",
+	4191: "getresgid",
+	4192: "prctl",
+	4193: "rt_sigreturn",
+	4194: "rt_sigaction",
+	4195: "rt_sigprocmask",
+	4196: "rt_sigpending",
+	4197: "rt_sigtimedwait",
+	4198: "rt_sigqueueinfo",
+	4199: "rt_sigsuspend",
+	4200: "pread64",
+	4201: "pwrite64",
+	4202: "chown",
+	4203: "getcwd",
+	4204: "capget",
+	4205: "capset",
+	4206: "sigaltstack",
+	4207: "sendfile",
+	4208: "getpmsg",
+	4209: "putpmsg",
+	4210: "mmap2",
+	4211: "truncate64",
+	4212: "ftruncate64",
+	4213: "stat64",
+	4214: "lstat64",
+	4215: "fstat64",
+	4216: "pivot_root",
+	4217: "mincore",
+	4218: "madvise",
+	4219: "getdents64",
+	4220: "fcntl64",
+	4222: "gettid",
+	4223: "readahead",
+	4224: "setxattr",
+	4225: "lsetxattr",
+	4226: "fsetxattr",
+	4227: "getxattr",
+	4228: "lgetxattr",
+	4229: "fgetxattr",
+	4230: "listxattr",
+	4231: "llistxattr",
+	4232: "flistxattr",
+	4233: "removexattr",
+	4234: "lremovexattr",
+	4235: "fremovexattr",
+	4236: "tkill",
+	4237: "sendfile64",
+	4238: "futex",
+	4239: "sched_setaffinity",
+	4240: "sched_getaffinity",
+	4241: "io_setup",
+	4242: "io_destroy",
+	4243: "io_getevents",
+	4244: "io_submit",
+	4245: "io_cancel",
+	4246: "exit_group",
+	4247: "lookup_dcookie",
+	4248: "epoll_create",
+	4249: "epoll_ctl",
+	4250: "epoll_wait",
+	4251: "remap_file_pages",
+	4252: "set_tid_address",
+	4253: "restart_syscall",
+	4254: "fadvise64",
+	4255: "statfs64",
+	4256: "fstatfs64",
+	4257: "timer_create",
+	4258: "timer_settime",
+	4259: "timer_gettime",
+	4260: "timer_getoverrun",
+	4261: "timer_delete",
+	4262: "clock_settime",
+	4263: "clock_gettime",
+	4264: "clock_getres",
+	4265: "clock_nanosleep",
+	4266: "tgkill",
+	4267: "utimes",
+	4268: "mbind",
+	4269: "get_mempolicy",
+	4270: "set_mempolicy",
+	4271: "mq_open",
+	4272: "mq_unlink",
+	4273: "mq_timedsend",
+	4274: "mq_timedreceive",
+	4275: "mq_notify",
+	4276: "mq_getsetattr",
+	4277: "vserver",
+	4278: "waitid",
+	4280: "add_key",
+	4281: "request_key",
+	4282: "keyctl",
+	4283: "set_thread_area",
+	4284: "inotify_init",
+	4285: "inotify_add_watch",
+	4286: "inotify_rm_watch",
+	4287: "migrate_pages",
+	4288: "openat",
+	4289: "mkdirat",
+	4290: "mknodat",
+	4291: "fchownat",
+	4292: "futimesat",
+	4293: "fstatat64",
+	4294: "unlinkat",
+	4295: "renameat",
+	4296: "linkat",
+	4297: "symlinkat",
+	4298: "readlinkat",
+	4299: "fchmodat",
+	4300: "faccessat",
+	4301: "pselect6",
+	4302: "ppoll",
+	4303: "unshare",
+	4304: "splice",
+	4305: "sync_file_range",
+	4306: "tee",
+	4307: "vmsplice",
+	4308: "move_pages",
+	4309: "set_robust_list",
+	4310: "get_robust_list",
+	4311: "kexec_load",
+	4312: "getcpu",
+	4313: "epoll_pwait",
+	4314: "ioprio_set",
+	4315: "ioprio_get",
+	4316: "utimensat",
+	4317: "signalfd",
+	4318: "timerfd",
+	4319: "eventfd",
+	4320: "fallocate",
+	4321: "timerfd_create",
+	4322: "timerfd_gettime",
+	4323: "timerfd_settime",
+	4324: "signalfd4",
+	4325: "eventfd2",
+	4326: "epoll_create1",
+	4327: "dup3",
+	4328: "pipe2",
+	4329: "inotify_init1",
+	4330: "preadv",
+	4331: "pwritev",
+	4332: "rt_tgsigqueueinfo",
+	4333: "perf_event_open",
+	4334: "accept4",
+	4335: "recvmmsg",
+	4336: "fanotify_init",
+	4337: "fanotify_mark",
+	4338: "prlimit64",
+	4339: "name_to_handle_at",
+	4340: "open_by_handle_at",
+	4341: "clock_adjtime",
+	4342: "syncfs",
+	4343: "sendmmsg",
+	4344: "setns",
+	4345: "process_vm_readv",
+	4346: "process_vm_writev",
+	4347: "kcmp",
+	4348: "finit_module",
+	4349: "sched_setattr",
+	4350: "sched_getattr",
+	4351: "renameat2",
+	4352: "seccomp",
+	4353: "getrandom",
+	4354: "memfd_create",
+	4355: "bpf",
+	4356: "execveat",
+	4357: "userfaultfd",
+	4358: "membarrier",
+	4359: "mlock2",
+	4360: "copy_file_range",
+	4361: "preadv2",
+	4362: "pwritev2",
+	4363: "pkey_mprotect",
+	4364: "pkey_alloc",
+	4365: "pkey_free",
+	4366: "statx",
+	4367: "rseq",
+	4368: "io_pgetevents",
+	4393: "semget",
+	4394: "semctl",
+	4395: "shmget",
+	4396: "shmctl",
+	4397: "shmat",
+	4398: "shmdt",
+	4399: "msgget",
+	4400: "msgsnd",
+	4401: "msgrcv",
+	4402: "msgctl",
+	4403: "clock_gettime64",
+	4404: "clock_settime64",
+	4405: "clock_adjtime64",
+	4406: "clock_getres_time64",
+	4407: "clock_nanosleep_time64",
+	4408: "timer_gettime64",
+	4409: "timer_settime64",
+	4410: "timerfd_gettime64",
+	4411: "timerfd_settime64",
+	4412: "utimensat_time64",
+	4413: "pselect6_time64",
+	4414: "ppoll_time64",
+	4416: "io_pgetevents_time64",
+	4417: "recvmmsg_time64",
+	4418: "mq_timedsend_time64",
+	4419: "mq_timedreceive_time64",
+	4420: "semtimedop_time64",
+	4421: "rt_sigtimedwait_time64",
+	4422: "futex_time64",
+	4423: "sched_rr_get_interval_time64",
+	4424: "pidfd_send_signal",
+	4425: "io_uring_setup",
+	4426: "io_uring_enter",
+	4427: "io_uring_register",
+	4428: "open_tree",
+	4429: "move_mount",
+	4430: "fsopen",
+	4431: "fsconfig",
+	4432: "fsmount",
+	4433: "fspick",
+	4434: "pidfd_open",
+	4435: "clone3",
+	4436: "close_range",
+	4437: "openat2",
+	4438: "pidfd_getfd",
+	4439: "faccessat2",
+	4440: "process_madvise",
+	4441: "epoll_pwait2",
+	4442: "mount_setattr",
+	4443: "quotactl_fd",
+	4444: "landlock_create_ruleset",
+	4445: "landlock_add_rule",
+	4446: "landlock_restrict_self",
+	4448: "process_mrelease",
+	4449: "futex_waitv",
+	4450: "set_mempolicy_home_node",
+	4451: "cachestat",
+	4452: "fchmodat2",
+	4454: "futex_wake",
+	4455: "futex_wait",
+	4456: "futex_requeue",
+	4457: "statmount",
+	4458: "listmount",
+	4459: "lsm_get_self_attr",
+	4460: "lsm_set_self_attr",
+	4461: "lsm_list_modules",
+	4462: "mseal",
+}
+
+// reference: https://github.com/seccomp/libseccomp/blob/master/src/arch-mips64-syscalls.c, numbered from 5000 on N64
+var syscallIDtoNameMIPS64 = map[int64]string{
+	5000: "read",
+	5001: "write",
+	5002: "open",
+	5003: "close",
+	5004: "stat",
+	5005: "fstat",
+	5006: "lstat",
+	5007: "poll",
+	5008: "lseek",
+	5009: "mmap",
+	5010: "mprotect",
+	5011: "munmap",
+	5012: "brk",
+	5013: "rt_sigaction",
+	5014: "rt_sigprocmask",
+	5015: "ioctl",
+	5016: "pread64",
+	5017: "pwrite64",
+	5018: "readv",
+	5019: "writev",
+	5020: "access",
+	5021: "pipe",
+	5022: "_newselect",
+	5023: "sched_yield",
+	5024: "mremap",
+	5025: "msync",
+	5026: "mincore",
+	5027: "madvise",
+	5028: "shmget",
+	5029: "shmat",
+	5030: "shmctl",
+	5031: "dup",
+	5032: "dup2",
+	5033: "pause",
+	5034: "nanosleep",
+	5035: "getitimer",
+	5036: "setitimer",
+	5037: "alarm",
+	5038: "getpid",
+	5039: "sendfile",
+	5040: "socket",
+	5041: "connect",
+	5042: "accept",
+	5043: "sendto",
+	5044: "recvfrom",
+	5045: "sendmsg",
+	5046: "recvmsg",
+	5047: "shutdown",
+	5048: "bind",
+	5049: "listen",
+	5050: "getsockname",
+	5051: "getpeername",
+	5052: "socketpair",
+	5053: "setsockopt",
+	5054: "getsockopt",
+	5055: "clone",
+	5056: "fork",
+	5057: "execve",
+	5058: "exit",
+	5059: "wait4",
+	5060: "kill",
+	5061: "uname",
+	5062: "semget",
+	5063: "semop",
+	5064: "semctl",
+	5065: "shmdt",
+	5066: "msgget",
+	5067: "msgsnd",
+	5068: "msgrcv",
+	5069: "msgctl",
+	5070: "fcntl",
+	5071: "flock",
+	5072: "fsync",
+	5073: "fdatasync",
+	5074: "truncate",
+	5075: "ftruncate",
+	5076: "getdents",
+	5077: "getcwd",
+	5078: "chdir",
+	5079: "fchdir",
+	5080: "rename",
+	5081: "mkdir",
+	5082: "rmdir",
+	5083: "creat",
+	5084: "link",
+	5085: "unlink",
+	5086: "symlink",
+	5087: "readlink",
+	5088: "chmod",
+	5089: "fchmod",
+	5090: "chown",
+	5091: "fchown",
+	5092: "lchown",
+	5093: "umask",
+	5094: "gettimeofday",
+	5095: "getrlimit",
+	5096: "getrusage",
+	5097: "sysinfo",
+	5098: "times",
+	5099: "ptrace",
+	5100: "getuid",
+	5101: "syslog",
+	5102: "getgid",
+	5103: "setuid",
+	5104: "setgid",
+	5105: "geteuid",
+	5106: "getegid",
+	5107: "setpgid",
+	5108: "getppid",
+	5109: "getpgrp",
+	5110: "setsid",
+	5111: "setreuid",
+	5112: "setregid",
+	5113: "getgroups",
+	5114: "setgroups",
+	5115: "setresuid",
+	5116: "getresuid",
+	5117: "setresgid",
+	5118: "getresgid",
+	5119: "getpgid",
+	5120: "setfsuid",
+	5121: "setfsgid",
+	5122: "getsid",
+	5123: "capget",
+	5124: "capset",
+	5125: "rt_sigpending",
+	5126: "rt_sigtimedwait",
+	5127: "rt_sigqueueinfo",
+	5128: "rt_sigsuspend",
+	5129: "sigaltstack",
+	5130: "utime",
+	5131: "mknod",
+	5132: "personality",
+	5133: "ustat",
+	5134: "statfs",
+	5135: "fstatfs",
+	5136: "sysfs",
+	5137: "getpriority",
+	5138: "setpriority",
+	5139: "sched_setparam",
+	5140: "sched_getparam",
+	5141: "sched_setscheduler",
+	5142: "sched_getscheduler",
+	5143: "sched_get_priority_max",
+	5144: "sched_get_priority_min",
+	5145: "sched_rr_get_interval",
+	5146: "mlock",
+	5147: "munlock",
+	5148: "mlockall",
+	5149: "munlockall",
+	5150: "vhangup",
+	5151: "pivot_root",
+	5152: "_sysctl",
+	5153: "prctl",
+	5154: "adjtimex",
+	5155: "setrlimit",
+	5156: "chroot",
+	5157: "sync",
+	5158: "acct",
+	5159: "settimeofday",
+	5160: "mount",
+	5161: "umount2",
+	5162: "swapon",
+	5163: "swapoff",
+	5164: "reboot",
+	5165: "sethostname",
+	5166: "setdomainname",
+	5167: "create_module",
+	5168: "init_module",
+	5169: "delete_module",
+	5170: "get_kernel_syms",
+	5171: "query_module",
+	5172: "quotactl",
+	5173: "nfsservctl",
+	5174: "getpmsg",
+	5175: "putpmsg",
+	5176: "afs_syscall",
+	5178: "gettid",
+	5179: "readahead",
+	5180: "setxattr",
+	5181: "lsetxattr",
+	5182: "fsetxattr",
+	5183: "getxattr",
+	5184: "lgetxattr",
+	5185: "fgetxattr",
+	5186: "listxattr",
+	5187: "llistxattr",
+	5188: "flistxattr",
+	5189: "removexattr",
+	5190: "lremovexattr",
+	5191: "fremovexattr",
+	5192: "tkill",
+	5194: "futex",
+	5195: "sched_setaffinity",
+	5196: "sched_getaffinity",
+	5197: "cacheflush",
+	5198: "cachectl",
+	5199: "sysmips",
+	5200: "io_setup",
+	5201: "io_destroy",
+	5202: "io_getevents",
+	5203: "io_submit",
+	5204: "io_cancel",
+	5205: "exit_group",
+	5206: "lookup_dcookie",
+	5207: "epoll_create",
+	5208: "epoll_ctl",
+	5209: "epoll_wait",
+	5210: "remap_file_pages",
+	5211: "rt_sigreturn",
+	5212: "set_tid_address",
+	5213: "restart_syscall",
+	5214: "semtimedop",
+	5215: "fadvise64",
+	5216: "timer_create",
+	5217: "timer_settime",
+	5218: "timer_gettime",
+	5219: "timer_getoverrun",
+	5220: "timer_delete",
+	5221: "clock_settime",
+	5222: "clock_gettime",
+	5223: "clock_getres",
+	5224: "clock_nanosleep",
+	5225: "tgkill",
+	5226: "utimes",
+	5227: "mbind",
+	5228: "get_mempolicy",
+	5229: "set_mempolicy",
+	5230: "mq_open",
+	5231: "mq_unlink",
+	5232: "mq_timedsend",
+	5233: "mq_timedreceive",
+	5234: "mq_notify",
+	5235: "mq_getsetattr",
+	5236: "vserver",
+	5237: "waitid",
+	5239: "add_key",
+	5240: "request_key",
+	5241: "keyctl",
+	5242: "set_thread_area",
+	5243: "inotify_init",
+	5244: "inotify_add_watch",
+	5245: "inotify_rm_watch",
+	5246: "migrate_pages",
+	5247: "openat",
+	5248: "mkdirat",
+	5249: "mknodat",
+	5250: "fchownat",
+	5251: "futimesat",
+	5252: "newfstatat",
+	5253: "unlinkat",
+	5254: "renameat",
+	5255: "linkat",
+	5256: "symlinkat",
+	5257: "readlinkat",
+	5258: "fchmodat",
+	5259: "faccessat",
+	5260: "pselect6",
+	5261: "ppoll",
+	5262: "unshare",
+	5263: "splice",
+	5264: "sync_file_range",
+	5265: "tee",
+	5266: "vmsplice",
+	5267: "move_pages",
+	5268: "set_robust_list",
+	5269: "get_robust_list",
+	5270: "kexec_load",
+	5271: "getcpu",
+	5272: "epoll_pwait",
+	5273: "ioprio_set",
+	5274: "ioprio_get",
+	5275: "utimensat",
+	5276: "signalfd",
+	5277: "timerfd",
+	5278: "eventfd",
+	5279: "fallocate",
+	5280: "timerfd_create",
+	5281: "timerfd_gettime",
+	5282: "timerfd_settime",
+	5283: "signalfd4",
+	5284: "eventfd2",
+	5285: "epoll_create1",
+	5286: "dup3",
+	5287: "pipe2",
+	5288: "inotify_init1",
+	5289: "preadv",
+	5290: "pwritev",
+	5291: "rt_tgsigqueueinfo",
+	5292: "perf_event_open",
+	5293: "accept4",
+	5294: "recvmmsg",
+	5295: "fanotify_init",
+	5296: "fanotify_mark",
+	5297: "prlimit64",
+	5298: "name_to_handle_at",
+	5299: "open_by_handle_at",
+	5300: "clock_adjtime",
+	5301: "syncfs",
+	5302: "sendmmsg",
+	5303: "setns",
+	5304: "process_vm_readv",
+	5305: "process_vm_writev",
+	5306: "kcmp",
+	5307: "finit_module",
+	5308: "getdents64",
+	5309: "sched_setattr",
+	5310: "sched_getattr",
+	5311: "renameat2",
+	5312: "seccomp",
+	5313: "getrandom",
+	5314: "memfd_create",
+	5315: "bpf",
+	5316: "execveat",
+	5317: "userfaultfd",
+	5318: "membarrier",
+	5319: "mlock2",
+	5320: "copy_file_range",
+	5321: "preadv2",
+	5322: "pwritev2",
+	5323: "pkey_mprotect",
+	5324: "pkey_alloc",
+	5325: "pkey_free",
+	5326: "statx",
+	5327: "rseq",
+	5328: "io_pgetevents",
+	5424: "pidfd_send_signal",
+	5425: "io_uring_setup",
+	5426: "io_uring_enter",
+	5427: "io_uring_register",
+	5428: "open_tree",
+	5429: "move_mount",
+	5430: "fsopen",
+	5431: "fsconfig",
+	5432: "fsmount",
+	5433: "fspick",
+	5434: "pidfd_open",
+	5435: "clone3",
+	5436: "close_range",
+	5437: "openat2",
+	5438: "pidfd_getfd",
+	5439: "faccessat2",
+	5440: "process_madvise",
+	5441: "epoll_pwait2",
+	5442: "mount_setattr",
+	5443: "quotactl_fd",
+	5444: "landlock_create_ruleset",
+	5445: "landlock_add_rule",
+	5446: "landlock_restrict_self",
+	5448: "process_mrelease",
+	5449: "futex_waitv",
+	5450: "set_mempolicy_home_node",
+	5451: "cachestat",
+	5452: "fchmodat2",
+	5454: "futex_wake",
+	5455: "futex_wait",
+	5456: "futex_requeue",
+	5457: "statmount",
+	5458: "listmount",
+	5459: "lsm_get_self_attr",
+	5460: "lsm_set_self_attr",
+	5461: "lsm_list_modules",
+	5462: "mseal",
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+func init() {
+	registerFormatter(formatFunc{"systemd-unit", ".conf", writeSystemdUnit})
+}
+
+// systemd names of the seccomp arches, for SystemCallArchitectures
+var systemdArchs = map[specs.Arch]string{
+	specs.ArchX86_64:   "x86-64",
+	specs.ArchX86:      "x86",
+	specs.ArchX32:      "x32",
+	specs.ArchARM:      "arm",
+	specs.ArchAARCH64:  "arm64",
+	specs.ArchPPC64LE:  "ppc64-le",
+	specs.ArchMIPS:     "mips",
+	specs.ArchMIPSEL:   "mips-le",
+	specs.ArchMIPS64:   "mips64",
+	specs.ArchMIPSEL64: "mips64-le",
+}
+
+// writeSystemdUnit writes a systemd drop-in confining the service to the allowed syscalls, with the hardening
+// options the analysis shows the binary doesn't need to do without
+func writeSystemdUnit(w io.Writer, profile specs.LinuxSeccomp, profilePath string) error {
+	allowed := make(map[string]bool)
+	var names, conditional []string
+	for _, rule := range profile.Syscalls {
+		if !runningActions[rule.Action] {
+			continue
+		}
+		for _, name := range rule.Names {
+			if len(rule.Args) > 0 {
+				conditional = append(conditional, name)
+			}
+			if !allowed[name] {
+				allowed[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+
+	sort.Strings(names)
+	sort.Strings(conditional)
+
+	var archs []string
+	for _, arch := range profile.Architectures {
+		name, ok := systemdArchs[arch]
+		if !ok {
+			return fmt.Errorf("systemd doesn't know arch %v", arch)
+		}
+		archs = append(archs, name)
+	}
+
+	var buf bytes.Buffer
+	name := strings.TrimSuffix(filepath.Base(profilePath), filepath.Ext(profilePath))
+	if profilePath == stdoutPath {
+		name = "seccomp"
+	}
+	fmt.Fprintf(&buf, "# Generated by go2seccomp for %v.\n", analyzedBinary)
+	fmt.Fprintf(&buf, "# Install as /etc/systemd/system/<unit>.service.d/%v.conf and run systemctl daemon-reload.\n", name)
+	if len(conditional) > 0 {
+		fmt.Fprintf(&buf, "# systemd filters by name only, these are allowed whatever their arguments: %v\n", strings.Join(uniqueStrings(conditional), " "))
+	}
+	fmt.Fprintln(&buf, "[Service]")
+	// nothing is enforced for audit profiles, they log the syscalls they don't allow
+	audit := profile.DefaultAction == specs.ActLog
+	if !audit {
+		fmt.Fprintln(&buf, "NoNewPrivileges=yes")
+	}
+	if len(archs) > 0 && !audit {
+		fmt.Fprintf(&buf, "SystemCallArchitectures=%v\n", strings.Join(archs, " "))
+	}
+	// several allow lists add up, one line per few syscalls keeps the diffs of the drop-in readable. Audit
+	// profiles log the other syscalls instead, with a deny list of the allowed ones.
+	for i, line := range systemdFilterLines(names) {
+		switch {
+		case !audit:
+			fmt.Fprintf(&buf, "SystemCallFilter=%v\n", line)
+		case i == 0:
+			fmt.Fprintf(&buf, "SystemCallLog=~%v\n", line)
+		default:
+			fmt.Fprintf(&buf, "SystemCallLog=%v\n", line)
+		}
+	}
+	switch profile.DefaultAction {
+	case specs.ActErrno:
+		errno := "EPERM"
+		if profile.DefaultErrnoRet != nil {
+			errno = fmt.Sprint(*profile.DefaultErrnoRet)
+		}
+		fmt.Fprintf(&buf, "SystemCallErrorNumber=%v\n", errno)
+	case specs.ActKill, specs.ActKillThread, specs.ActKillProcess:
+		// systemd kills the process by default
+	case specs.ActLog:
+		// SystemCallLog needs systemd 247
+	default:
+		return fmt.Errorf("systemd can't take default action %v", profile.DefaultAction)
+	}
+	if noExecMappings && !audit {
+		fmt.Fprintln(&buf, "MemoryDenyWriteExecute=yes")
+	}
+	if !allowed["personality"] && !audit {
+		fmt.Fprintln(&buf, "LockPersonality=yes")
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// the longest SystemCallFilter line written, without the key
+const systemdFilterLineLength = 100
+
+// systemdFilterLines splits the syscall names into lines of at most systemdFilterLineLength characters
+func systemdFilterLines(names []string) []string {
+	var lines []string
+	var line string
+	for _, name := range names {
+		if line != "" && len(line)+1+len(name) > systemdFilterLineLength {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += name
+	}
+	if line != "" || len(lines) == 0 {
+		lines = append(lines, line)
+	}
+	return lines
+}
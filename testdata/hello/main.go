@@ -0,0 +1,9 @@
+//go:debug panicnil=1
+package main
+
+import "os"
+
+// fixture the tests build for each architecture, with a DefaultGODEBUG setting in its build info
+func main() {
+	os.Stdout.WriteString("hello\n")
+}
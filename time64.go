@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+// the 64 bit time variants 32 bit arches got in 5.1, by the syscall they replace. Go, libc and the kernel
+// headers move between them, so profiles for these arches need both.
+var time64Variants = map[string]string{
+	"clock_gettime":         "clock_gettime64",
+	"clock_settime":         "clock_settime64",
+	"clock_adjtime":         "clock_adjtime64",
+	"clock_getres":          "clock_getres_time64",
+	"clock_nanosleep":       "clock_nanosleep_time64",
+	"timer_gettime":         "timer_gettime64",
+	"timer_settime":         "timer_settime64",
+	"timerfd_gettime":       "timerfd_gettime64",
+	"timerfd_settime":       "timerfd_settime64",
+	"utimensat":             "utimensat_time64",
+	"pselect6":              "pselect6_time64",
+	"ppoll":                 "ppoll_time64",
+	"io_pgetevents":         "io_pgetevents_time64",
+	"recvmmsg":              "recvmmsg_time64",
+	"mq_timedsend":          "mq_timedsend_time64",
+	"mq_timedreceive":       "mq_timedreceive_time64",
+	"semtimedop":            "semtimedop_time64",
+	"rt_sigtimedwait":       "rt_sigtimedwait_time64",
+	"futex":                 "futex_time64",
+	"sched_rr_get_interval": "sched_rr_get_interval_time64",
+}
+
+func init() {
+	// programs fall back to the 32 bit time syscalls on kernels without the 64 bit ones
+	for old, variant := range time64Variants {
+		syscallFallbacks[variant] = append(syscallFallbacks[variant], old)
+	}
+}
+
+// time64Version is the kernel the 64 bit time syscalls were added in
+var time64Version = kernelVersion{5, 1}
+
+// applyTime64 adds the 64 bit time variant of the detected 32 bit time syscalls, and the other way around,
+// on the arches having both. The 32 bit ones aren't added when -min-kernel has the 64 bit ones.
+func applyTime64(syscallsList []string, arch specs.Arch) []string {
+	inList := make(map[string]bool)
+	for _, name := range syscallsList {
+		inList[name] = true
+	}
+	archNames := make(map[string]bool)
+	for _, name := range syscallIDtoName[arch] {
+		archNames[name] = true
+	}
+	if !archNames["clock_gettime64"] {
+		return syscallsList
+	}
+	needOld := true
+	if *minKernel != "" {
+		if version, err := parseKernelVersion(*minKernel); err == nil && !version.less(time64Version) {
+			needOld = false
+		}
+	}
+
+	var added []string
+	for old, variant := range time64Variants {
+		if !archNames[old] || !archNames[variant] {
+			continue
+		}
+		if inList[old] && !inList[variant] {
+			added = append(added, variant)
+		}
+		if inList[variant] && !inList[old] && needOld {
+			added = append(added, old)
+			addFallbackOnly(old, variant)
+		}
+	}
+	if len(added) == 0 {
+		return syscallsList
+	}
+	sort.Strings(added)
+	fmt.Printf("Adding the other variant of the detected time syscalls: %v\n", added)
+	list := append(append([]string(nil), syscallsList...), added...)
+	sort.Strings(list)
+	return list
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"text/template"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+func init() {
+	commands["trace-stub"] = traceStubCommand
+}
+
+// syscalls given with -trace, marked with SCMP_ACT_TRACE instead of allowed
+var tracedSyscalls stringList
+
+// applyTrace moves the traced syscalls out of the allowed ones into a SCMP_ACT_TRACE rule. They're
+// included even if not detected, the supervisor decides what happens to them.
+func applyTrace(profile specs.LinuxSeccomp) specs.LinuxSeccomp {
+	if len(tracedSyscalls) == 0 {
+		return profile
+	}
+	traced := make(map[string]bool)
+	for _, name := range tracedSyscalls {
+		traced[name] = true
+	}
+
+	var rules []specs.LinuxSyscall
+	for _, rule := range profile.Syscalls {
+		if rule.Action == specs.ActAllow {
+			var names []string
+			for _, name := range rule.Names {
+				if !traced[name] {
+					names = append(names, name)
+				}
+			}
+			// drop the rules left without syscalls, e.g. with -rule-style grouped
+			if len(names) == 0 {
+				continue
+			}
+			rule.Names = names
+		}
+		rules = append(rules, rule)
+	}
+	profile.Syscalls = rules
+
+	names := make([]string, 0, len(traced))
+	for name := range traced {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	profile.Syscalls = append(profile.Syscalls, specs.LinuxSyscall{Names: names, Action: specs.ActTrace})
+	return profile
+}
+
+var traceStubTemplate = template.Must(template.New("trace-stub").Parse(`// Code generated by go2seccomp trace-stub. DO NOT EDIT.
+
+//go:build linux && amd64
+
+// Command supervisor runs a program under ptrace, logging the syscalls its seccomp profile marks with
+// SCMP_ACT_TRACE and letting them through. Without a tracer the kernel fails them with ENOSYS.
+//
+//	supervisor /path/to/program [args...]
+//
+// In a container, the supervisor has to be the entrypoint, running the program as its child.
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"syscall"
+)
+
+const (
+	ptraceEventSeccomp  = 7
+	ptraceOTraceSeccomp = 0x80
+	ptraceOExitKill     = 0x100000
+)
+
+// syscalls the profile traces
+var traced = map[uint64]string{
+{{- range .Syscalls}}
+	{{.ID}}: {{printf "%q" .Name}},
+{{- end}}
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, "Usage: supervisor /path/to/program [args...]")
+		os.Exit(2)
+	}
+
+	// ptrace requests have to come from the thread that attached
+	runtime.LockOSThread()
+
+	cmd := exec.Command(os.Args[1], os.Args[2:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.SysProcAttr = &syscall.SysProcAttr{Ptrace: true}
+	if err := cmd.Start(); err != nil {
+		fmt.Fprintf(os.Stderr, "supervisor: %v\n", err)
+		os.Exit(1)
+	}
+	pid := cmd.Process.Pid
+
+	// the program stops at its exec
+	var status syscall.WaitStatus
+	if _, err := syscall.Wait4(pid, &status, 0, nil); err != nil {
+		fmt.Fprintf(os.Stderr, "supervisor: %v\n", err)
+		os.Exit(1)
+	}
+	options := ptraceOTraceSeccomp | ptraceOExitKill | syscall.PTRACE_O_TRACECLONE | syscall.PTRACE_O_TRACEFORK | syscall.PTRACE_O_TRACEVFORK
+	if err := syscall.PtraceSetOptions(pid, options); err != nil {
+		fmt.Fprintf(os.Stderr, "supervisor: %v\n", err)
+		os.Exit(1)
+	}
+	syscall.PtraceCont(pid, 0)
+
+	for {
+		wpid, err := syscall.Wait4(-1, &status, syscall.WALL, nil)
+		if err != nil {
+			os.Exit(1)
+		}
+		if status.Exited() || status.Signaled() {
+			if wpid == pid {
+				if status.Signaled() {
+					os.Exit(128 + int(status.Signal()))
+				}
+				os.Exit(status.ExitStatus())
+			}
+			continue
+		}
+
+		signal := 0
+		switch {
+		case status.StopSignal() == syscall.SIGTRAP && status.TrapCause() == ptraceEventSeccomp:
+			var regs syscall.PtraceRegs
+			if err := syscall.PtraceGetRegs(wpid, &regs); err == nil {
+				name, ok := traced[regs.Orig_rax]
+				if !ok {
+					name = fmt.Sprint(regs.Orig_rax)
+				}
+				fmt.Fprintf(os.Stderr, "supervisor: pid %v called %v(%#x, %#x, %#x)\n", wpid, name, regs.Rdi, regs.Rsi, regs.Rdx)
+			}
+		case status.StopSignal() == syscall.SIGTRAP || status.StopSignal() == syscall.SIGSTOP:
+			// ptrace events and the stop of new threads
+		default:
+			signal = int(status.StopSignal())
+		}
+		syscall.PtraceCont(wpid, signal)
+	}
+}
+`))
+
+// traceStubCommand prints the source of a ptrace supervisor for the syscalls a profile traces
+func traceStubCommand(args []string) {
+	if len(args) < 1 {
+		fmt.Println("Usage: go2seccomp trace-stub /path/to/profile.json > supervisor.go")
+		os.Exit(1)
+	}
+	profile := loadProfile(args[0])
+
+	ids := make(map[string]int64)
+	for id, name := range syscallIDtoName[specs.ArchX86_64] {
+		ids[name] = id
+	}
+
+	type tracedSyscall struct {
+		ID   int64
+		Name string
+	}
+	var syscalls []tracedSyscall
+	for _, rule := range profile.Syscalls {
+		if rule.Action != specs.ActTrace {
+			continue
+		}
+		for _, name := range rule.Names {
+			if id, ok := ids[name]; ok {
+				syscalls = append(syscalls, tracedSyscall{id, name})
+			}
+		}
+	}
+	if len(syscalls) == 0 {
+		fmt.Fprintf(os.Stderr, "%v has no SCMP_ACT_TRACE syscalls known on x86_64\n", args[0])
+		os.Exit(1)
+	}
+	sort.Slice(syscalls, func(i, j int) bool { return syscalls[i].ID < syscalls[j].ID })
+
+	if err := traceStubTemplate.Execute(os.Stdout, map[string]interface{}{"Syscalls": syscalls}); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write the supervisor: %v\n", err)
+		os.Exit(1)
+	}
+}
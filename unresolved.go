@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+var verboseFlag = flag.Bool("v", false, "verbose output")
+var veryVerbose = flag.Bool("vv", false, "more verbose output, with the instructions around the syscalls whose ID can't be found")
+
+// unresolvedSite is a syscall whose ID couldn't be found, with the instructions before it to help
+// improve the detection
+type unresolvedSite struct {
+	Function    string `json:"function"`
+	Address     string `json:"address"`
+	Location    string `json:"location"`
+	Instruction string `json:"instruction"`
+	Reason      string `json:"reason"`
+	// the instructions before the syscall, the oldest first
+	Context []string `json:"context"`
+}
+
+// addUnresolved records the syscall instruction whose ID couldn't be found, with the buffered
+// instructions before it
+func (g *callGraph) addUnresolved(function, instruction string, previousInstructions []string, curPos int, err error) {
+	site := unresolvedSite{Function: function, Instruction: strings.TrimSpace(instruction), Reason: err.Error()}
+	fields := strings.Fields(instruction)
+	if len(fields) > 1 {
+		site.Location = fields[0]
+		site.Address = fields[1]
+	}
+	for i := previousInstructionsBufferSize - 1; i > 0; i-- {
+		previous := previousInstructions[(curPos-i+previousInstructionsBufferSize)%previousInstructionsBufferSize]
+		if curPos-i >= 0 && previous != "" {
+			site.Context = append(site.Context, strings.TrimSpace(previous))
+		}
+	}
+	g.unresolved = append(g.unresolved, site)
+
+	if *veryVerbose {
+		fmt.Printf("Unresolved syscall in %v at %v (%v), after:\n", site.Function, site.Location, site.Address)
+		for _, previous := range site.Context {
+			fmt.Printf("    %v\n", previous)
+		}
+	}
+}
+
+// unresolvedIn returns the unresolved sites in the given functions, or in all of them if nil
+func (g *callGraph) unresolvedIn(functions map[string]bool) []unresolvedSite {
+	var sites []unresolvedSite
+	for _, site := range g.unresolved {
+		if functions == nil || functions[site.Function] {
+			sites = append(sites, site)
+		}
+	}
+	return sites
+}
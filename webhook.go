@@ -0,0 +1,155 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+func init() {
+	commands["webhook"] = webhookCommand
+}
+
+// minimal subset of the Kubernetes admission.k8s.io/v1 and core/v1 types the webhook needs
+
+type admissionReview struct {
+	APIVersion string             `json:"apiVersion"`
+	Kind       string             `json:"kind"`
+	Request    *admissionRequest  `json:"request,omitempty"`
+	Response   *admissionResponse `json:"response,omitempty"`
+}
+
+type admissionRequest struct {
+	UID    string          `json:"uid"`
+	Object json.RawMessage `json:"object"`
+}
+
+type admissionResponse struct {
+	UID      string           `json:"uid"`
+	Allowed  bool             `json:"allowed"`
+	Status   *admissionStatus `json:"status,omitempty"`
+	Warnings []string         `json:"warnings,omitempty"`
+}
+
+type admissionStatus struct {
+	Message string `json:"message"`
+}
+
+type k8sSeccompProfile struct {
+	Type             string `json:"type"`
+	LocalhostProfile string `json:"localhostProfile"`
+}
+
+type k8sSecurityContext struct {
+	SeccompProfile *k8sSeccompProfile `json:"seccompProfile"`
+}
+
+type k8sContainer struct {
+	Name            string              `json:"name"`
+	Image           string              `json:"image"`
+	SecurityContext *k8sSecurityContext `json:"securityContext"`
+}
+
+type k8sPod struct {
+	Metadata struct {
+		Name         string            `json:"name"`
+		GenerateName string            `json:"generateName"`
+		Annotations  map[string]string `json:"annotations"`
+	} `json:"metadata"`
+	Spec struct {
+		SecurityContext *k8sSecurityContext `json:"securityContext"`
+		InitContainers  []k8sContainer      `json:"initContainers"`
+		Containers      []k8sContainer      `json:"containers"`
+	} `json:"spec"`
+}
+
+// webhookCommand runs a Kubernetes validating admission webhook that checks pods use the profiles
+// generated for them by go2seccomp pod, and that the profiles weren't changed since
+func webhookCommand(args []string) {
+	fs := flag.NewFlagSet("webhook", flag.ExitOnError)
+	addr := fs.String("addr", ":8443", "address to listen on")
+	certFile := fs.String("tls-cert", "", "TLS certificate file")
+	keyFile := fs.String("tls-key", "", "TLS key file")
+	profileRoot := fs.String("profile-root", "/var/lib/kubelet/seccomp", "directory with the profiles, laid out like the kubelet seccomp root")
+	warnOnly := fs.Bool("warn", false, "admit pods that fail the checks, returning warnings instead of denying them")
+	parseFlags(fs, args)
+
+	if *certFile == "" || *keyFile == "" {
+		fmt.Println("Usage: go2seccomp webhook -tls-cert cert.pem -tls-key key.pem [-addr :8443] [-profile-root dir] [-warn]")
+		os.Exit(1)
+	}
+
+	http.HandleFunc("/validate", func(w http.ResponseWriter, r *http.Request) {
+		var review admissionReview
+		if err := json.NewDecoder(r.Body).Decode(&review); err != nil || review.Request == nil {
+			http.Error(w, "invalid AdmissionReview", http.StatusBadRequest)
+			return
+		}
+
+		response := &admissionResponse{UID: review.Request.UID, Allowed: true}
+		var pod k8sPod
+		if err := json.Unmarshal(review.Request.Object, &pod); err != nil {
+			response.Allowed = false
+			response.Status = &admissionStatus{Message: fmt.Sprintf("go2seccomp: can't parse pod: %v", err)}
+		} else if problems := checkPodProfiles(&pod, *profileRoot); len(problems) > 0 {
+			if *warnOnly {
+				response.Warnings = problems
+			} else {
+				response.Allowed = false
+				response.Status = &admissionStatus{Message: fmt.Sprintf("go2seccomp: %v", problems)}
+			}
+			log.Printf("Pod %v%v: %v\n", pod.Metadata.Name, pod.Metadata.GenerateName, problems)
+		}
+
+		review.Request = nil
+		review.Response = response
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(review)
+	})
+
+	fmt.Printf("Listening on %v\n", *addr)
+	log.Fatal(http.ListenAndServeTLS(*addr, *certFile, *keyFile, nil))
+}
+
+// checkPodProfiles returns the problems found with the profiles of each container of the pod
+func checkPodProfiles(pod *k8sPod, profileRoot string) []string {
+	var problems []string
+
+	containers := append(append([]k8sContainer{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+	for _, c := range containers {
+		// the container profile takes precedence over the pod one
+		var profile *k8sSeccompProfile
+		if pod.Spec.SecurityContext != nil {
+			profile = pod.Spec.SecurityContext.SeccompProfile
+		}
+		if c.SecurityContext != nil && c.SecurityContext.SeccompProfile != nil {
+			profile = c.SecurityContext.SeccompProfile
+		}
+
+		hash, ok := pod.Metadata.Annotations[profileHashAnnotation+c.Name]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("container %v (%v) has no generated profile", c.Name, c.Image))
+			continue
+		}
+		if profile == nil || profile.Type != "Localhost" {
+			problems = append(problems, fmt.Sprintf("container %v doesn't use a Localhost seccomp profile", c.Name))
+			continue
+		}
+
+		data, err := ioutil.ReadFile(filepath.Join(profileRoot, filepath.Clean("/"+profile.LocalhostProfile)))
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("container %v references profile %v, which isn't installed", c.Name, profile.LocalhostProfile))
+			continue
+		}
+		if actual := sha256Hex(data); actual != hash {
+			problems = append(problems, fmt.Sprintf("container %v references profile %v, which doesn't match the generated one (sha256 %v, expected %v)",
+				c.Name, profile.LocalhostProfile, actual, hash))
+		}
+	}
+	return problems
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"debug/elf"
+	"flag"
+	"fmt"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+var forbidExec = flag.Bool("forbid-exec", false, "only allow mmap and mprotect without PROT_EXEC, for binaries that never map executable memory")
+
+const (
+	protWrite = 0x2
+	protExec  = 0x4
+)
+
+// whether the analyzed binary is dynamically linked, its loader maps the libraries executable
+var dynamicBinary bool
+
+// whether the analysis showed the binary never maps executable memory, set by reportExecMappings
+var noExecMappings bool
+
+func isDynamic(f *elf.File) bool {
+	for _, p := range f.Progs {
+		if p.Type == elf.PT_INTERP {
+			return true
+		}
+	}
+	return false
+}
+
+// reportExecMappings prints the W^X posture of the binary: whether its mmap and mprotect calls ask for
+// executable memory, and writable and executable at once, in the given functions (all if nil)
+func reportExecMappings(graph *callGraph, arch specs.Arch, functions map[string]bool) {
+	noExecMappings = false
+	if *forbidExec && dynamicBinary {
+		warnf("-forbid-exec on a dynamically linked binary, its loader needs PROT_EXEC")
+	}
+	if arch != specs.ArchX86_64 {
+		return
+	}
+	sites := append(graph.argSitesOf("mmap", functions), graph.argSitesOf("mprotect", functions)...)
+	sites = append(sites, graph.argSitesOf("pkey_mprotect", functions)...)
+	if len(sites) == 0 {
+		// the runtime never maps executable memory
+		noExecMappings = !dynamicBinary
+		return
+	}
+
+	executable, unknown := 0, 0
+	for _, site := range sites {
+		switch {
+		case !site.Known:
+			unknown++
+			fmt.Printf("%v in %v at %v: protection isn't a constant\n", site.Syscall, site.Function, site.Location)
+		case site.Value&protExec != 0 && site.Value&protWrite != 0:
+			executable++
+			warnf("%v in %v at %v maps memory writable and executable (%#x)", site.Syscall, site.Function, site.Location, site.Value)
+		case site.Value&protExec != 0:
+			executable++
+			fmt.Printf("%v in %v at %v maps memory executable (%#x)\n", site.Syscall, site.Function, site.Location, site.Value)
+		}
+	}
+
+	switch {
+	case executable > 0:
+		fmt.Printf("W^X: %v of %v mmap/mprotect calls map executable memory, e.g. for a JIT\n", executable, len(sites))
+		if *forbidExec {
+			warnf("-forbid-exec will make these calls fail")
+		}
+	case unknown > 0:
+		fmt.Printf("W^X: no mmap/mprotect call maps executable memory, %v of %v can't be told\n", unknown, len(sites))
+	case dynamicBinary:
+		fmt.Printf("W^X: no mmap/mprotect call maps executable memory, but the binary is dynamically linked and its loader does\n")
+	default:
+		noExecMappings = true
+		fmt.Printf("W^X: no mmap/mprotect call maps executable memory, -forbid-exec can enforce it in the profile\n")
+	}
+}
+
+// applyForbidExec makes mmap and mprotect allowed only without PROT_EXEC, with -forbid-exec
+func applyForbidExec(profile specs.LinuxSeccomp) specs.LinuxSeccomp {
+	if !*forbidExec {
+		return profile
+	}
+	restricted := map[string]bool{"mmap": true, "mmap2": true, "mprotect": true, "pkey_mprotect": true}
+
+	var rules []specs.LinuxSyscall
+	var names []string
+	for _, rule := range profile.Syscalls {
+		if rule.Action == specs.ActAllow && len(rule.Args) == 0 {
+			var kept []string
+			for _, name := range rule.Names {
+				if restricted[name] {
+					names = append(names, name)
+				} else {
+					kept = append(kept, name)
+				}
+			}
+			if len(kept) == 0 {
+				continue
+			}
+			rule.Names = kept
+		}
+		rules = append(rules, rule)
+	}
+	if len(names) > 0 {
+		// prot is the third argument of all of them
+		rules = append(rules, specs.LinuxSyscall{
+			Names:  names,
+			Action: specs.ActAllow,
+			Args:   []specs.LinuxSeccompArg{{Index: 2, Value: protExec, ValueTwo: 0, Op: specs.OpMaskedEqual}},
+		})
+	}
+	profile.Syscalls = rules
+	return profile
+}
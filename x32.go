@@ -0,0 +1,88 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+var x32 = flag.Bool("x32", false, "for amd64 binaries, also allow the profile syscalls to x32 processes, adding SCMP_ARCH_X32 to the architectures")
+
+// the syscalls x32 has its own entry points for, since they pass structures whose layout differs from the
+// x86_64 one. Their x86_64 numbers are 64 bit only.
+// reference: https://github.com/torvalds/linux/blob/master/arch/x86/entry/syscalls/syscall_64.tbl
+var x32CompatSyscalls = map[string]int64{
+	"rt_sigaction":      512,
+	"rt_sigreturn":      513,
+	"ioctl":             514,
+	"readv":             515,
+	"writev":            516,
+	"recvfrom":          517,
+	"sendmsg":           518,
+	"recvmsg":           519,
+	"execve":            520,
+	"ptrace":            521,
+	"rt_sigpending":     522,
+	"rt_sigtimedwait":   523,
+	"rt_sigqueueinfo":   524,
+	"sigaltstack":       525,
+	"timer_create":      526,
+	"mq_notify":         527,
+	"kexec_load":        528,
+	"waitid":            529,
+	"set_robust_list":   530,
+	"get_robust_list":   531,
+	"vmsplice":          532,
+	"move_pages":        533,
+	"preadv":            534,
+	"pwritev":           535,
+	"rt_tgsigqueueinfo": 536,
+	"recvmmsg":          537,
+	"sendmmsg":          538,
+	"process_vm_readv":  539,
+	"process_vm_writev": 540,
+	"setsockopt":        541,
+	"getsockopt":        542,
+	"io_setup":          543,
+	"io_submit":         544,
+	"execveat":          545,
+	"preadv2":           546,
+	"pwritev2":          547,
+}
+
+// x86_64 syscalls x32 doesn't have at all
+var x32MissingSyscalls = map[string]bool{
+	"uselib": true, "_sysctl": true, "create_module": true, "get_kernel_syms": true, "query_module": true,
+	"nfsservctl": true, "getpmsg": true, "putpmsg": true, "afs_syscall": true, "tuxcall": true, "security": true,
+	"set_thread_area": true, "get_thread_area": true, "epoll_ctl_old": true, "epoll_wait_old": true, "vserver": true,
+}
+
+// x32 syscalls are numbered like the x86_64 ones, with x32SyscallBit set
+var syscallIDtoNameX32 = x32SyscallTable(syscallIDtoNamex86_64)
+
+func x32SyscallTable(x86_64 map[int64]string) map[int64]string {
+	table := make(map[int64]string)
+	for id, name := range x86_64 {
+		if _, compat := x32CompatSyscalls[name]; compat || x32MissingSyscalls[name] {
+			continue
+		}
+		table[id|x32SyscallBit] = name
+	}
+	for name, id := range x32CompatSyscalls {
+		table[id|x32SyscallBit] = name
+	}
+	return table
+}
+
+// profileArchitectures returns the architectures of the profile for a binary of the arch, x86_64 ones get
+// x32 as well with -x32. The syscall names are the same, the runtimes resolve them to the x32 numbers.
+// Profiles merged by multiarch have the architectures of all the binaries.
+func profileArchitectures(arch specs.Arch) []specs.Arch {
+	if mergedArchitectures != nil {
+		return mergedArchitectures
+	}
+	if *x32 && arch == specs.ArchX86_64 {
+		return []specs.Arch{arch, specs.ArchX32}
+	}
+	return []specs.Arch{arch}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"gopkg.in/yaml.v2"
+)
+
+func init() {
+	registerFormatter(formatFunc{"oci-yaml", ".yaml", writeOCIYAMLProfile})
+	registerFormatter(formatFunc{"yaml", ".yaml", writeOCIYAMLProfile})
+	commands["convert"] = convertCommand
+}
+
+// writeOCIYAMLProfile writes the OCI profile as YAML, with the same fields in the same order as the JSON
+func writeOCIYAMLProfile(w io.Writer, profile specs.LinuxSeccomp, profilePath string) error {
+	var profileJSON bytes.Buffer
+	if err := writeOCIProfile(&profileJSON, profile, profilePath); err != nil {
+		return err
+	}
+	// JSON is YAML, decoding it into a MapSlice keeps the order of the fields
+	var doc yaml.MapSlice
+	if err := yaml.Unmarshal(profileJSON.Bytes(), &doc); err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// isYAMLPath tells if the file is YAML, going by its extension
+func isYAMLPath(path string) bool {
+	ext := filepath.Ext(path)
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// yamlToJSON converts a YAML document to JSON
+func yamlToJSON(data []byte) ([]byte, error) {
+	var doc interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	doc, err := jsonValue(doc)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(doc)
+}
+
+// jsonValue turns the maps yaml decodes, keyed by interface{}, into maps JSON can encode
+func jsonValue(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			k, ok := key.(string)
+			if !ok {
+				return nil, fmt.Errorf("non string key %v", key)
+			}
+			converted, err := jsonValue(value)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = converted
+		}
+		return m, nil
+	case []interface{}:
+		for i := range v {
+			converted, err := jsonValue(v[i])
+			if err != nil {
+				return nil, err
+			}
+			v[i] = converted
+		}
+	}
+	return v, nil
+}
+
+// convertCommand converts a profile between JSON and YAML, going by the extensions of the files, and
+// resolves the fragments it includes
+func convertCommand(args []string) {
+	if len(args) < 2 {
+		fmt.Println("Usage: go2seccomp convert profile.yaml profile.json")
+		os.Exit(1)
+	}
+
+	data, err := ioutil.ReadFile(args[0])
+	if err != nil {
+		fatalf("Failed to read profile: %v", err)
+	}
+	if isYAMLPath(args[0]) {
+		if data, err = yamlToJSON(data); err != nil {
+			fatalf("Failed to parse profile %v: %v", args[0], err)
+		}
+	}
+
+	// profiles including fragments are converted with them merged in
+	var includes profileIncludes
+	if json.Unmarshal(data, &includes) == nil && len(includes.Include) > 0 {
+		if data, err = json.Marshal(loadProfile(args[0])); err != nil {
+			fatalf("Failed to convert %v: %v", args[0], err)
+		}
+	}
+
+	// otherwise converted as documents rather than profiles, so no field is lost
+	var out []byte
+	if isYAMLPath(args[1]) {
+		var doc yaml.MapSlice
+		if err = yaml.Unmarshal(data, &doc); err == nil {
+			out, err = yaml.Marshal(doc)
+		}
+	} else {
+		var buf bytes.Buffer
+		if err = json.Indent(&buf, data, "", "    "); err == nil {
+			buf.WriteString("\n")
+			out = buf.Bytes()
+		}
+	}
+	if err != nil {
+		fatalf("Failed to convert %v: %v", args[0], err)
+	}
+	writeProfileFile(args[1], out)
+	fmt.Printf("Saved seccomp profile at %v\n", args[1])
+}